@@ -16,10 +16,14 @@ package cmd
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	ep "github.com/jlewi/cloud-endpoints-controller/pkg"
 	kftypes "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
 	"github.com/kubeflow/kfctl/v3/pkg/kfapp/coordinator"
+	kfloaders "github.com/kubeflow/kfctl/v3/pkg/kfconfig/loaders"
 	"github.com/kubeflow/kfctl/v3/pkg/kfupgrade"
 	"github.com/kubeflow/kfctl/v3/pkg/utils"
 	log "github.com/sirupsen/logrus"
@@ -61,33 +65,52 @@ var applyCmd = &cobra.Command{
 
 		kind, err := utils.GetObjectKindFromUri(configFilePath)
 		if err != nil {
-			return fmt.Errorf("Cannot determine the object kind: %v", err)
+			return fmt.Errorf("Cannot determine the object kind: %w", err)
 		}
 		switch kind {
 		case string(kftypes.KFDEF):
-			kfApp, err = coordinator.NewLoadKfAppFromURI(configFilePath)
+			// Writes annotations to pass information to kfapps.
+			annotations := map[string]string{
+				strings.Join([]string{utils.KfDefAnnotation, utils.ForceConflicts}, "/"): strconv.FormatBool(
+					applyCfg.GetBool(string(kftypes.FORCE_CONFLICTS))),
+				strings.Join([]string{utils.KfDefAnnotation, utils.Prune}, "/"): strconv.FormatBool(
+					applyCfg.GetBool(string(kftypes.PRUNE))),
+				strings.Join([]string{utils.KfDefAnnotation, utils.PruneDryRun}, "/"): strconv.FormatBool(
+					applyCfg.GetBool(string(kftypes.PRUNE_DRY_RUN))),
+				strings.Join([]string{utils.KfDefAnnotation, utils.Wait}, "/"): strconv.FormatBool(
+					applyCfg.GetBool(string(kftypes.WAIT))),
+				strings.Join([]string{utils.KfDefAnnotation, utils.WaitTimeout}, "/"): applyCfg.GetDuration(
+					string(kftypes.WAIT_TIMEOUT)).String(),
+				strings.Join([]string{utils.KfDefAnnotation, utils.Parallelism}, "/"): strconv.Itoa(
+					applyCfg.GetInt(string(kftypes.PARALLELISM))),
+			}
+			for k, v := range appSelectionAnnotations(applyCfg) {
+				annotations[k] = v
+			}
+			kfApp, err = coordinator.NewLoadKfAppFromURI(configFilePath, kfloaders.WithAnnotations(annotations),
+				configLoadOption())
 			if err != nil {
-				return fmt.Errorf("failed to build kfApp from URI %s: %v", configFilePath, err)
+				return emitResult(cmd, nil, err, fmt.Errorf("failed to build kfApp from URI %s: %w", configFilePath, err))
 			}
 			if err := kfApp.Apply(kftypes.ALL); err != nil {
-				return fmt.Errorf("failed to apply: %s", err)
+				return emitResult(cmd, kfApp, err, fmt.Errorf("failed to apply: %w", err))
 			}
 			log.Info("Applied the configuration Successfully!")
-			return nil
+			return emitResult(cmd, kfApp, nil, nil)
 		case string(kftypes.KFUPGRADE):
 			log.Warnf("Support for kind %s is deprecated and will be removed in subsequent versions", kftypes.KFUPGRADE)
 			kfUpgrade, err := kfupgrade.NewKfUpgrade(configFilePath)
 			if err != nil {
-				return fmt.Errorf("couldn't load KfUpgrade: %v", err)
+				return emitResult(cmd, nil, err, fmt.Errorf("couldn't load KfUpgrade: %w", err))
 			}
 
 			err = kfUpgrade.Apply()
 			if err != nil {
-				return fmt.Errorf("couldn't apply KfUpgrade: %v", err)
+				return emitResult(cmd, nil, err, fmt.Errorf("couldn't apply KfUpgrade: %w", err))
 			}
-			return nil
+			return emitResult(cmd, nil, nil, nil)
 		case ep.Kind:
-			return ep.Process(configFilePath, kubeContext)
+			return emitResult(cmd, nil, nil, ep.Process(configFilePath, kubeContext))
 		default:
 			return fmt.Errorf("Unsupported object kind: %v", kind)
 		}
@@ -121,4 +144,60 @@ func init() {
 		log.Errorf("Couldn't set flag --%v: %v", string(kftypes.VERBOSE), bindErr)
 		return
 	}
+
+	// take ownership of fields managed by other field managers during server-side apply.
+	applyCmd.Flags().Bool(string(kftypes.FORCE_CONFLICTS), false,
+		"Take ownership of fields managed by other tools instead of failing on server-side apply conflicts.")
+	bindErr = applyCfg.BindPFlag(string(kftypes.FORCE_CONFLICTS), applyCmd.Flags().Lookup(string(kftypes.FORCE_CONFLICTS)))
+	if bindErr != nil {
+		log.Errorf("Couldn't set flag --%v: %v", string(kftypes.FORCE_CONFLICTS), bindErr)
+		return
+	}
+
+	// delete objects a previous apply created that are no longer part of the rendered applications.
+	applyCmd.Flags().Bool(string(kftypes.PRUNE), false,
+		"Delete objects recorded by a previous apply that the applications no longer contain.")
+	bindErr = applyCfg.BindPFlag(string(kftypes.PRUNE), applyCmd.Flags().Lookup(string(kftypes.PRUNE)))
+	if bindErr != nil {
+		log.Errorf("Couldn't set flag --%v: %v", string(kftypes.PRUNE), bindErr)
+		return
+	}
+
+	// only print the objects --prune would delete.
+	applyCmd.Flags().Bool(string(kftypes.PRUNE_DRY_RUN), false,
+		"Print the objects --prune would delete without deleting them.")
+	bindErr = applyCfg.BindPFlag(string(kftypes.PRUNE_DRY_RUN), applyCmd.Flags().Lookup(string(kftypes.PRUNE_DRY_RUN)))
+	if bindErr != nil {
+		log.Errorf("Couldn't set flag --%v: %v", string(kftypes.PRUNE_DRY_RUN), bindErr)
+		return
+	}
+
+	// wait for every application to become healthy before applying the next one.
+	applyCmd.Flags().Bool(string(kftypes.WAIT), false,
+		"Wait until the Deployments, StatefulSets, DaemonSets, Jobs and CRDs of each application are ready.")
+	bindErr = applyCfg.BindPFlag(string(kftypes.WAIT), applyCmd.Flags().Lookup(string(kftypes.WAIT)))
+	if bindErr != nil {
+		log.Errorf("Couldn't set flag --%v: %v", string(kftypes.WAIT), bindErr)
+		return
+	}
+
+	applyCmd.Flags().Duration(string(kftypes.WAIT_TIMEOUT), 5*time.Minute,
+		"How long --wait waits for each application to become ready.")
+	bindErr = applyCfg.BindPFlag(string(kftypes.WAIT_TIMEOUT), applyCmd.Flags().Lookup(string(kftypes.WAIT_TIMEOUT)))
+	if bindErr != nil {
+		log.Errorf("Couldn't set flag --%v: %v", string(kftypes.WAIT_TIMEOUT), bindErr)
+		return
+	}
+
+	// only apply some of the applications.
+	addAppSelectionFlags(applyCmd, applyCfg)
+
+	// apply independent applications concurrently.
+	applyCmd.Flags().Int(string(kftypes.PARALLELISM), 1,
+		"Number of applications to render and apply at the same time. Applications still wait for their dependsOn.")
+	bindErr = applyCfg.BindPFlag(string(kftypes.PARALLELISM), applyCmd.Flags().Lookup(string(kftypes.PARALLELISM)))
+	if bindErr != nil {
+		log.Errorf("Couldn't set flag --%v: %v", string(kftypes.PARALLELISM), bindErr)
+		return
+	}
 }
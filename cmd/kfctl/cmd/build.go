@@ -16,9 +16,11 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	kftypes "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
 	"github.com/kubeflow/kfctl/v3/pkg/kfapp/coordinator"
+	kfloaders "github.com/kubeflow/kfctl/v3/pkg/kfconfig/loaders"
 	"github.com/kubeflow/kfctl/v3/pkg/kfupgrade"
 	"github.com/kubeflow/kfctl/v3/pkg/utils"
 	log "github.com/sirupsen/logrus"
@@ -40,27 +42,35 @@ var buildCmd = &cobra.Command{
 			log.SetLevel(log.WarnLevel)
 		}
 
+		if structuredOutput() && buildCfg.GetBool(string(kftypes.DUMP)) {
+			return fmt.Errorf("--%v can't be combined with --%v %v", kftypes.DUMP, kftypes.OUTPUT, outputFormat)
+		}
+
 		kind, err := utils.GetObjectKindFromUri(configFilePath)
 		if err != nil {
-			return fmt.Errorf("Cannot determine the object kind: %v", err)
+			return fmt.Errorf("Cannot determine the object kind: %w", err)
 		}
 
 		var kfApp kftypes.KfApp
 		switch kind {
 		case string(kftypes.KFDEF):
-			kfApp, err = coordinator.NewLoadKfAppFromURI(configFilePath)
+			if buildCfg.GetBool(string(kftypes.DUMP_CONFIG)) {
+				return dumpConfig(cmd)
+			}
+			kfApp, err = coordinator.NewLoadKfAppFromURI(configFilePath,
+				kfloaders.WithAnnotations(appSelectionAnnotations(buildCfg)), configLoadOption())
 			if err != nil {
-				return fmt.Errorf("failed to build kfApp from URI %s: %v", configFilePath, err)
+				return emitResult(cmd, nil, err, fmt.Errorf("failed to build kfApp from URI %s: %w", configFilePath, err))
 			}
 		case string(kftypes.KFUPGRADE):
 			log.Warnf("Support for kind %s is deprecated and will be removed in subsequent versions", kftypes.KFUPGRADE)
 			kfApp, err := kfupgrade.NewKfUpgrade(configFilePath)
 			if err != nil {
-				return fmt.Errorf("couldn't load KfUpgrade: %v", err)
+				return emitResult(cmd, nil, err, fmt.Errorf("couldn't load KfUpgrade: %w", err))
 			}
 
 			if err := kfApp.Generate(); err != nil {
-				return fmt.Errorf("couldn't generate KfApp: %v", err)
+				return emitResult(cmd, nil, err, fmt.Errorf("couldn't generate KfApp: %w", err))
 			}
 		default:
 			return fmt.Errorf("Unsupported object kind: %v", kind)
@@ -69,10 +79,30 @@ var buildCmd = &cobra.Command{
 		if buildCfg.GetBool(string(kftypes.DUMP)) == true {
 			kfApp.Dump(kftypes.ALL)
 		}
-		return nil
+		return emitResult(cmd, kfApp, nil, nil)
 	},
 }
 
+// dumpConfig prints the KfDef as kfctl sees it, merged into its bases and with its
+// variables resolved, in the version of the config file. Literal secrets aren't printed and
+// other secrets are redacted unless --show-secrets is given.
+func dumpConfig(cmd *cobra.Command) error {
+	config, err := kfloaders.LoadConfigFromURI(configFilePath, configLoadOption())
+	if err != nil {
+		return fmt.Errorf("couldn't load KfDef %v: %w", configFilePath, err)
+	}
+	kfdef, err := kfloaders.ConvertKfDef(*config, strings.TrimPrefix(config.APIVersion, kfloaders.Api+"/"))
+	if err != nil {
+		return fmt.Errorf("couldn't convert KfDef %v: %w", configFilePath, err)
+	}
+	kfloaders.RedactKfDef(kfdef, strings.TrimPrefix(config.APIVersion, kfloaders.Api+"/"))
+	format := outputYAML
+	if outputFormat == outputJSON {
+		format = outputJSON
+	}
+	return printDocument(cmd.OutOrStdout(), format, kfdef)
+}
+
 func init() {
 	rootCmd.AddCommand(buildCmd)
 
@@ -107,4 +137,16 @@ func init() {
 		log.Errorf("Couldn't set flag --%v: %v", string(kftypes.VERBOSE), bindErr)
 		return
 	}
+
+	// only build some of the applications.
+	addAppSelectionFlags(buildCmd, buildCfg)
+
+	// dump-config flag
+	buildCmd.Flags().Bool(string(kftypes.DUMP_CONFIG), false,
+		"print the KfDef merged into its bases, with its variables resolved, instead of building")
+	bindErr = buildCfg.BindPFlag(string(kftypes.DUMP_CONFIG), buildCmd.Flags().Lookup(string(kftypes.DUMP_CONFIG)))
+	if bindErr != nil {
+		log.Errorf("Couldn't set flag --%v: %v", string(kftypes.DUMP_CONFIG), bindErr)
+		return
+	}
 }
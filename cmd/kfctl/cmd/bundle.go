@@ -0,0 +1,172 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	kftypes "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
+	"github.com/kubeflow/kfctl/v3/pkg/kfapp/kustomize"
+	"github.com/kubeflow/kfctl/v3/pkg/kfconfig"
+	kfloaders "github.com/kubeflow/kfctl/v3/pkg/kfconfig/loaders"
+	"github.com/kubeflow/kfctl/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// bundleFile is the file the bundle is written to.
+var bundleFile string
+
+// bundleCmd represents the commands managing bundles for air-gapped installs.
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Manage bundles for installs without network access.",
+	Long: `A bundle holds everything kfctl needs to build a KfDef without network access: the KfDef,` + "\n" +
+		`the archives of its repos and the list of container images to mirror into the local registry.` + "\n" +
+		`Extract it on the air-gapped machine and pass the KfDef of the bundle, or --bundle, to` + "\n" +
+		`kfctl with --offline, e.g. -> ` + ColorPrint("kfctl apply -f bundle/kfdef.yaml --offline"),
+}
+
+var bundleCreateCmd = &cobra.Command{
+	Args:  cobra.NoArgs,
+	Use:   "create -f ${CONFIG} --out-file bundle.tgz",
+	Short: "Package a KfDef, its repos and its image list for transfer.",
+	Long: `Download the repos of a KfDef and write a gzipped tarball with` + "\n" +
+		`  ` + kfconfig.BundleKfDefFile + `   the KfDef with its bases merged, variables resolved and archives pinned` + "\n" +
+		`  ` + kfconfig.BundleReposDir + `/        the repo archives, named by their sha256; git and local dir repos are packed` + "\n" +
+		`  ` + kfconfig.BundleImagesFile + `   the container images of the enabled applications, one per line` + "\n" +
+		`  ` + kfconfig.BundleIndexFile + `  the index of the bundle` + "\n" +
+		`To create a bundle run -> ` + ColorPrint("kfctl alpha bundle create -f ${CONFIG} --out-file bundle.tgz"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if configFilePath == "" {
+			return fmt.Errorf("Must pass in -f configFile")
+		}
+		if bundleFile == "" {
+			return fmt.Errorf("Must pass in --out-file bundleFile")
+		}
+		cmd.SilenceUsage = true
+		return emitResult(cmd, nil, nil, createBundle())
+	},
+}
+
+// createBundle writes the bundle of the KfDef at configFilePath to bundleFile.
+func createBundle() error {
+
+	config, err := kfloaders.LoadConfigFromURI(configFilePath, configLoadOption())
+	if err != nil {
+		return fmt.Errorf("couldn't load KfDef %v: %w", configFilePath, err)
+	}
+	// --strict only applies to loading the KfDef.
+	delete(config.Annotations, strings.Join([]string{utils.KfDefAnnotation, utils.Strict}, "/"))
+
+	stageDir, err := ioutil.TempDir("", "kfctl-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stageDir)
+	index, err := config.StageBundle(stageDir)
+	if err != nil {
+		return fmt.Errorf("couldn't bundle KfDef %v: %w", configFilePath, err)
+	}
+	if err := writeBundleKfDef(config, filepath.Join(stageDir, index.KfDef)); err != nil {
+		return err
+	}
+	if err := index.Write(); err != nil {
+		return fmt.Errorf("couldn't write the bundle index: %v", err)
+	}
+	images, err := bundleImages(config, stageDir)
+	if err != nil {
+		return fmt.Errorf("couldn't list the images of KfDef %v: %w", configFilePath, err)
+	}
+	data := strings.Join(images, "\n")
+	if len(images) > 0 {
+		data += "\n"
+	}
+	if err := ioutil.WriteFile(filepath.Join(stageDir, index.Images), []byte(data), 0644); err != nil {
+		return fmt.Errorf("couldn't write the image list: %v", err)
+	}
+
+	f, err := os.Create(bundleFile)
+	if err != nil {
+		return fmt.Errorf("couldn't create %v: %v", bundleFile, err)
+	}
+	if err := kfconfig.ArchiveDir(f, stageDir); err != nil {
+		f.Close()
+		return fmt.Errorf("couldn't write %v: %w", bundleFile, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("couldn't write %v: %v", bundleFile, err)
+	}
+	log.Infof("Wrote bundle %v with %v repos and %v images", bundleFile, len(index.Repos), len(images))
+	return nil
+}
+
+// writeBundleKfDef writes config as a self contained KfDef of its version to file.
+func writeBundleKfDef(config *kfconfig.KfConfig, file string) error {
+	version := strings.TrimPrefix(config.APIVersion, kfloaders.Api+"/")
+	kfdef, err := kfloaders.ConvertKfDef(*config, version)
+	if err != nil {
+		return fmt.Errorf("couldn't convert KfDef %v: %w", configFilePath, err)
+	}
+	for _, f := range kfloaders.RedactKfDef(kfdef, version) {
+		log.Warnf("%v: %v is a secret and is redacted; use --%v to keep it", configFilePath, f, kftypes.SHOW_SECRETS)
+	}
+	data, err := yaml.Marshal(kfdef)
+	if err != nil {
+		return fmt.Errorf("couldn't encode KfDef: %v", err)
+	}
+	return ioutil.WriteFile(file, keepComments(configFilePath, data), 0644)
+}
+
+// bundleImages generates the applications of config in a temporary app dir, taking the repos
+// from the staged bundle in stageDir, and returns their images. This checks that the bundle
+// works offline too.
+func bundleImages(config *kfconfig.KfConfig, stageDir string) ([]string, error) {
+	appDir, err := ioutil.TempDir("", "kfctl-bundle-app-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(appDir)
+	offline, dir := kfconfig.Offline(), kfconfig.OfflineBundle()
+	kfconfig.SetOffline(true, stageDir)
+	defer kfconfig.SetOffline(offline, dir)
+
+	config.Spec.AppDir = appDir
+	config.Status.Caches = nil
+	kfApp := kustomize.GetKfApp(config)
+	if err := kfApp.Generate(kftypes.K8S); err != nil {
+		return nil, err
+	}
+	images, ok := kfApp.(kftypes.KfImages)
+	if !ok || images == nil {
+		return nil, fmt.Errorf("kfApp doesn't support listing images")
+	}
+	return images.Images()
+}
+
+func init() {
+	alphaCmd.AddCommand(bundleCmd)
+	bundleCmd.AddCommand(bundleCreateCmd)
+
+	bundleCreateCmd.Flags().StringVarP(&configFilePath, string(kftypes.FILE), "f", "",
+		`Static config file to use. Can be either a local path or a URL.`)
+	bundleCreateCmd.Flags().StringVar(&bundleFile, string(kftypes.OUT_FILE), "",
+		`File to write the bundle to, e.g. bundle.tgz.`)
+}
@@ -0,0 +1,177 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	kftypes "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
+	"github.com/kubeflow/kfctl/v3/pkg/kfconfig"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var cachePruneCfg = viper.New()
+var cacheVerifyCfg = viper.New()
+
+// cacheCmd represents the commands managing the shared cache of repos.
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the repo cache shared by all app dirs.",
+	Long: `Manage the repo cache shared by all app dirs.` + "\n" +
+		`Repo archives are downloaded into $` + kfconfig.SharedCacheEnv + ` or else kfctl under the user` + "\n" +
+		`cache dir, e.g. $XDG_CACHE_HOME/kfctl, and the .cache dir of every app dir links to them.` + "\n" +
+		`Archives pinned with sha256 are downloaded once; other URIs are downloaded on every sync and` + "\n" +
+		`stored once per digest.` + "\n" +
+		`Set $` + kfconfig.SharedCacheEnv + ` to off to download repos into every app dir instead.`,
+}
+
+var cacheListCmd = &cobra.Command{
+	Args:  cobra.NoArgs,
+	Use:   "list",
+	Short: "List the entries of the shared cache.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		cache, err := sharedCache()
+		if err != nil {
+			return err
+		}
+		entries, err := cache.List()
+		if err != nil {
+			return err
+		}
+		return printCacheEntries(cmd.OutOrStdout(), outputFormat, entries)
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Args:  cobra.NoArgs,
+	Use:   "prune",
+	Short: "Remove the entries of the shared cache no app dir uses.",
+	Long: `Remove the entries of the shared cache no app dir links to anymore.` + "\n" +
+		`With --older-than entries that weren't used for that long are removed too and with --all` + "\n" +
+		`every entry is removed. App dirs download removed repos again on their next build or apply.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		cache, err := sharedCache()
+		if err != nil {
+			return err
+		}
+		removed, err := cache.Prune(cachePruneCfg.GetDuration(string(kftypes.OLDER_THAN)),
+			cachePruneCfg.GetBool(string(kftypes.PRUNE_ALL)))
+		if err != nil {
+			return err
+		}
+		for _, e := range removed {
+			log.Infof("Removed %v (%v)", e.URI, e.Digest)
+		}
+		return printCacheEntries(cmd.OutOrStdout(), outputFormat, removed)
+	},
+}
+
+var cacheVerifyCmd = &cobra.Command{
+	Args:  cobra.NoArgs,
+	Use:   "verify",
+	Short: "Check that the files of the shared cache weren't modified.",
+	Long: `Hash the files of every entry of the shared cache and report the entries that are missing` + "\n" +
+		`or were modified. With --remove they are removed so that they are downloaded again.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		cache, err := sharedCache()
+		if err != nil {
+			return err
+		}
+		failed, err := cache.Verify(cacheVerifyCfg.GetBool(string(kftypes.REMOVE)))
+		if err != nil {
+			return err
+		}
+		if err := printCacheEntries(cmd.OutOrStdout(), outputFormat, failed); err != nil {
+			return err
+		}
+		if len(failed) > 0 && !cacheVerifyCfg.GetBool(string(kftypes.REMOVE)) {
+			return &kfapis.KfError{
+				Code: int(kfapis.INTERNAL_ERROR),
+				Message: fmt.Sprintf("%v entries of the shared cache failed verification; "+
+					"run kfctl alpha cache verify --remove", len(failed)),
+			}
+		}
+		return nil
+	},
+}
+
+// sharedCache returns the shared cache or an error if it's turned off.
+func sharedCache() (*kfconfig.SharedCache, error) {
+	cache, err := kfconfig.DefaultSharedCache()
+	if err != nil {
+		return nil, err
+	}
+	if cache == nil {
+		return nil, kfapis.NewKfError(kfapis.ErrConfigInvalid, "the shared cache is turned off by $%v",
+			kfconfig.SharedCacheEnv)
+	}
+	return cache, nil
+}
+
+// printCacheEntries writes entries to w as a table, JSON or YAML.
+func printCacheEntries(w io.Writer, output string, entries []kfconfig.CacheEntry) error {
+	if output != outputText {
+		return printDocument(w, output, entries)
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "DIGEST\tSIZE\tLAST USED\tLINKS\tURI")
+	for _, e := range entries {
+		digest := strings.TrimPrefix(e.Digest, "sha256:")
+		if len(digest) > 12 {
+			digest = digest[:12]
+		}
+		fmt.Fprintf(tw, "%v\t%vK\t%v\t%v\t%v\n", digest, e.Size/1024, e.LastUsed.Format(time.RFC3339),
+			len(e.Links), e.URI)
+	}
+	return tw.Flush()
+}
+
+func init() {
+	alphaCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cacheCmd.AddCommand(cacheVerifyCmd)
+
+	cachePruneCmd.Flags().Duration(string(kftypes.OLDER_THAN), 0,
+		"Also remove entries that weren't used for this long, e.g. 720h")
+	bindErr := cachePruneCfg.BindPFlag(string(kftypes.OLDER_THAN), cachePruneCmd.Flags().Lookup(string(kftypes.OLDER_THAN)))
+	if bindErr != nil {
+		log.Errorf("Couldn't set flag --%v: %v", string(kftypes.OLDER_THAN), bindErr)
+		return
+	}
+	cachePruneCmd.Flags().Bool(string(kftypes.PRUNE_ALL), false, "Remove every entry")
+	bindErr = cachePruneCfg.BindPFlag(string(kftypes.PRUNE_ALL), cachePruneCmd.Flags().Lookup(string(kftypes.PRUNE_ALL)))
+	if bindErr != nil {
+		log.Errorf("Couldn't set flag --%v: %v", string(kftypes.PRUNE_ALL), bindErr)
+		return
+	}
+
+	cacheVerifyCmd.Flags().Bool(string(kftypes.REMOVE), false, "Remove the entries that fail verification")
+	bindErr = cacheVerifyCfg.BindPFlag(string(kftypes.REMOVE), cacheVerifyCmd.Flags().Lookup(string(kftypes.REMOVE)))
+	if bindErr != nil {
+		log.Errorf("Couldn't set flag --%v: %v", string(kftypes.REMOVE), bindErr)
+		return
+	}
+}
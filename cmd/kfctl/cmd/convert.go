@@ -0,0 +1,130 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	kftypes "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
+	kfloaders "github.com/kubeflow/kfctl/v3/pkg/kfconfig/loaders"
+	"github.com/kubeflow/kfctl/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// convertVersion is the KfDef version to convert to.
+	convertVersion string
+	// convertFile is the file the converted KfDef is written to.
+	convertFile string
+)
+
+// convertCmd represents the convert command
+var convertCmd = &cobra.Command{
+	Args:  cobra.NoArgs,
+	Use:   "convert -f ${CONFIG} --to <version> [--out-file ${NEW_CONFIG}]",
+	Short: "Convert a KfDef to another version.",
+	Long: `Convert a KfDef to another version, e.g. to migrate an old config; the default version is v2.` + "\n" +
+		`Supported versions: ` + strings.Join(kfloaders.Versions(), ", ") + "\n" +
+		`The KfDef is written to the file given with --out-file as YAML unless the file ends in .json,` + "\n" +
+		`or printed in the format given with -o/--output, YAML by default.` + "\n" +
+		`Comments of the original file are kept on the fields that are still there.` + "\n" +
+		`Converting to a version and back doesn't lose anything; settings a version has no fields for` + "\n" +
+		`are kept in the ` + utils.KfDefAnnotation + "/" + utils.ApplicationSettings + ` annotation.` + "\n" +
+		`Fields that can't be represented in the new version, e.g. literal secrets, are reported.` + "\n" +
+		`Variable references such as ${HOSTNAME} are kept.` + "\n" +
+		`To migrate a config run -> ` + ColorPrint("kfctl alpha convert -f ${CONFIG} --to v2 --out-file ${NEW_CONFIG}"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if configFilePath == "" {
+			return fmt.Errorf("Must pass in -f configFile")
+		}
+		cmd.SilenceUsage = true
+
+		config, err := kfloaders.LoadConfigFromURI(configFilePath, configLoadOption(), kfloaders.WithV1alpha1(),
+			kfloaders.WithUnresolvedVariables())
+		if err != nil {
+			return fmt.Errorf("couldn't load KfDef %v: %w", configFilePath, err)
+		}
+		// --strict only applies to loading the KfDef.
+		delete(config.Annotations, strings.Join([]string{utils.KfDefAnnotation, utils.Strict}, "/"))
+		kfdef, err := kfloaders.ConvertKfDef(*config, convertVersion)
+		if err != nil {
+			return fmt.Errorf("couldn't convert KfDef %v: %w", configFilePath, err)
+		}
+		lost, err := kfloaders.UnrepresentableFields(*config, convertVersion)
+		if err != nil {
+			return fmt.Errorf("couldn't convert KfDef %v: %w", configFilePath, err)
+		}
+		for _, f := range lost {
+			log.Warnf("%v: %v can't be represented in KfDef %v and is dropped", configFilePath, f, convertVersion)
+		}
+		for _, f := range kfloaders.RedactKfDef(kfdef, convertVersion) {
+			log.Warnf("%v: %v is a secret and is redacted; use --%v to keep it", configFilePath, f, kftypes.SHOW_SECRETS)
+		}
+
+		var data []byte
+		if strings.HasSuffix(convertFile, ".json") || convertFile == "" && outputFormat == outputJSON {
+			data, err = json.MarshalIndent(kfdef, "", "  ")
+			data = append(data, '\n')
+		} else {
+			data, err = yaml.Marshal(kfdef)
+			if err == nil {
+				data = keepComments(configFilePath, data)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("couldn't encode KfDef: %v", err)
+		}
+		if convertFile == "" {
+			_, err = cmd.OutOrStdout().Write(data)
+			return err
+		}
+		if err := ioutil.WriteFile(convertFile, data, 0644); err != nil {
+			return emitResult(cmd, nil, nil, fmt.Errorf("couldn't write %v: %v", convertFile, err))
+		}
+		log.Infof("Wrote KfDef %v to %v", convertVersion, convertFile)
+		return emitResult(cmd, nil, nil, nil)
+	},
+}
+
+// keepComments moves the comments of configFile to the converted KfDef if configFile is a
+// local YAML file. Otherwise data is returned as is.
+func keepComments(configFile string, data []byte) []byte {
+	original, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return data
+	}
+	commented, err := utils.KeepComments(original, data)
+	if err != nil {
+		log.Warnf("Couldn't keep the comments of %v: %v", configFile, err)
+		return data
+	}
+	return commented
+}
+
+func init() {
+	alphaCmd.AddCommand(convertCmd)
+
+	convertCmd.Flags().StringVarP(&configFilePath, string(kftypes.FILE), "f", "",
+		`Static config file to use. Can be either a local path or a URL.`)
+	convertCmd.Flags().StringVar(&convertVersion, string(kftypes.TO), "v2",
+		`KfDef version to convert to.`)
+	convertCmd.Flags().StringVar(&convertFile, string(kftypes.OUT_FILE), "",
+		`File to write the converted KfDef to. The default is to print it.`)
+}
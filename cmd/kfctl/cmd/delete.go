@@ -55,16 +55,22 @@ var deleteCmd = &cobra.Command{
 			forceDeleteAnn: annValue,
 		})
 
-		kfApp, err = coordinator.NewLoadKfAppFromURI(configFilePath)
+		kfApp, err = coordinator.NewLoadKfAppFromURI(configFilePath,
+			kfloaders.WithAnnotations(appSelectionAnnotations(deleteCfg)), configLoadOption())
 		if err != nil || kfApp == nil {
-			return fmt.Errorf("error loading kfapp: %v", err)
+			return emitResult(cmd, nil, err, fmt.Errorf("error loading kfapp: %w", err))
 		}
 
-		deleteErr := kfApp.Delete(kftypes.ALL)
+		// The platform, e.g. the cluster, is only deleted with all applications.
+		resources := kftypes.ALL
+		if appSubset(deleteCfg) {
+			resources = kftypes.K8S
+		}
+		deleteErr := kfApp.Delete(resources)
 		if deleteErr != nil {
-			return fmt.Errorf("couldn't delete KfApp: %v", deleteErr)
+			return emitResult(cmd, kfApp, deleteErr, fmt.Errorf("couldn't delete KfApp: %w", deleteErr))
 		}
-		return nil
+		return emitResult(cmd, kfApp, nil, nil)
 	},
 }
 
@@ -95,6 +101,9 @@ func init() {
 		return
 	}
 
+	// only delete some of the applications.
+	addAppSelectionFlags(deleteCmd, deleteCfg)
+
 	deleteCmd.Flags().Bool(string(kftypes.DELETE_STORAGE), false,
 		"Set if you want to delete app's storage cluster used for mlpipeline.")
 	bindErr = deleteCfg.BindPFlag(string(kftypes.DELETE_STORAGE), deleteCmd.Flags().Lookup(string(kftypes.DELETE_STORAGE)))
@@ -105,7 +114,7 @@ func init() {
 }
 
 func setAnnotations(configPath string, annotations map[string]string) error {
-	config, err := kfloaders.LoadConfigFromURI(configPath)
+	config, err := kfloaders.LoadConfigFromURI(configPath, configLoadOption())
 	if err != nil {
 		return err
 	}
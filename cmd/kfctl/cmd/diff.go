@@ -0,0 +1,84 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	kftypes "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
+	"github.com/kubeflow/kfctl/v3/pkg/kfapp/coordinator"
+	kfloaders "github.com/kubeflow/kfctl/v3/pkg/kfconfig/loaders"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var diffCfg = viper.New()
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Args:  cobra.NoArgs,
+	Use:   "diff -f ${CONFIG}",
+	Short: "Show the changes apply would make to the cluster.",
+	Long: `'kfctl diff' renders every application of a KFDef config and prints a unified diff` + "\n" +
+		`against the objects currently in the cluster. Status and server managed fields are ignored.` + "\n" +
+		`To preview an install run -> ` + ColorPrint("kfctl diff -f ${CONFIG}"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.SetLevel(log.InfoLevel)
+		if diffCfg.GetBool(string(kftypes.VERBOSE)) != true {
+			log.SetLevel(log.WarnLevel)
+		}
+
+		if configFilePath == "" {
+			return fmt.Errorf("Must pass in -f configFile")
+		}
+
+		kfApp, err := coordinator.NewLoadKfAppFromURI(configFilePath,
+			kfloaders.WithAnnotations(appSelectionAnnotations(diffCfg)), configLoadOption())
+		if err != nil {
+			return fmt.Errorf("failed to build kfApp from URI %s: %w", configFilePath, err)
+		}
+		diff, ok := kfApp.(kftypes.KfDiff)
+		if !ok || diff == nil {
+			return fmt.Errorf("kfApp doesn't support diff")
+		}
+		if err := diff.Diff(kftypes.K8S); err != nil {
+			return fmt.Errorf("couldn't diff KfApp: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCfg.SetConfigName("app")
+	diffCfg.SetConfigType("yaml")
+
+	diffCmd.PersistentFlags().StringVarP(&configFilePath, string(kftypes.FILE), "f", "",
+		`Static config file to use. Can be either a local path or a URL.`)
+
+	// verbose output
+	diffCmd.Flags().BoolP(string(kftypes.VERBOSE), "V", false,
+		string(kftypes.VERBOSE)+" output default is false")
+	bindErr := diffCfg.BindPFlag(string(kftypes.VERBOSE), diffCmd.Flags().Lookup(string(kftypes.VERBOSE)))
+	if bindErr != nil {
+		log.Errorf("Couldn't set flag --%v: %v", string(kftypes.VERBOSE), bindErr)
+		return
+	}
+
+	// only diff some of the applications.
+	addAppSelectionFlags(diffCmd, diffCfg)
+}
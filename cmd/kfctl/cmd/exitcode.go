@@ -0,0 +1,64 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+)
+
+// Exit statuses of kfctl. Errors carrying a KfError exit with the status of its code so
+// that scripts can tell failures apart; any other error exits with exitError.
+const (
+	exitOK             = 0
+	exitError          = 1
+	exitInvalidConfig  = 2
+	exitNotFound       = 3
+	exitUnavailable    = 4
+	exitFailed         = 5
+	exitPartialFailure = 6
+	exitNotReady       = 7
+)
+
+// exitCodes maps KfError codes to exit statuses.
+var exitCodes = map[kfapis.StatusCode]int{
+	kfapis.INVALID_ARGUMENT:  exitInvalidConfig,
+	kfapis.NOT_FOUND:         exitNotFound,
+	kfapis.UNAVAILABLE:       exitUnavailable,
+	kfapis.INTERNAL_ERROR:    exitFailed,
+	kfapis.PARTIAL_FAILURE:   exitPartialFailure,
+	kfapis.DEADLINE_EXCEEDED: exitNotReady,
+}
+
+// exitCodesHelp documents exitCodes in the help of kfctl.
+const exitCodesHelp = `Exit status:
+  0  success
+  1  unclassified error, e.g. invalid command line usage
+  2  invalid configuration or arguments
+  3  plugin, file or resource not found
+  4  cluster unreachable
+  5  apply or another operation failed
+  6  partial failure, e.g. some objects couldn't be deleted
+  7  objects didn't become ready before --wait-timeout`
+
+// exitCode returns the exit status for err.
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	if code, ok := exitCodes[kfapis.Code(err)]; ok {
+		return code
+	}
+	return exitError
+}
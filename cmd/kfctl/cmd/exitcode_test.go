@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+)
+
+func TestExitCode(t *testing.T) {
+	type testCase struct {
+		err      error
+		expected int
+	}
+	tests := []testCase{
+		{
+			err:      nil,
+			expected: exitOK,
+		},
+		{
+			err:      fmt.Errorf("unknown flag: --foo"),
+			expected: exitError,
+		},
+		{
+			err:      &kfapis.KfError{Code: int(kfapis.INVALID_ARGUMENT), Message: "invalid config"},
+			expected: exitInvalidConfig,
+		},
+		{
+			err:      &kfapis.KfError{Code: int(kfapis.NOT_FOUND), Message: "Unrecognized platform foo"},
+			expected: exitNotFound,
+		},
+		{
+			err: fmt.Errorf("failed to apply: %w",
+				&kfapis.KfError{Code: int(kfapis.UNAVAILABLE), Message: "connection refused"}),
+			expected: exitUnavailable,
+		},
+		{
+			err:      fmt.Errorf("failed to apply: %w", &kfapis.KfError{Code: int(kfapis.INTERNAL_ERROR)}),
+			expected: exitFailed,
+		},
+		{
+			err:      &kfapis.KfError{Code: int(kfapis.PARTIAL_FAILURE), Message: "error deleting kustomize manifests"},
+			expected: exitPartialFailure,
+		},
+		{
+			err:      &kfapis.KfError{Code: int(kfapis.DEADLINE_EXCEEDED), Message: "objects not ready"},
+			expected: exitNotReady,
+		},
+		{
+			err:      &kfapis.KfError{Code: int(kfapis.UNKNOWN)},
+			expected: exitError,
+		},
+	}
+	for _, c := range tests {
+		if actual := exitCode(c.err); actual != c.expected {
+			t.Errorf("exitCode(%v) = %v; want %v", c.err, actual, c.expected)
+		}
+	}
+}
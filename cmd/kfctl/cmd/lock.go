@@ -0,0 +1,94 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	kftypes "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
+	kfloaders "github.com/kubeflow/kfctl/v3/pkg/kfconfig/loaders"
+	"github.com/kubeflow/kfctl/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// lockCmd represents the lock command
+var lockCmd = &cobra.Command{
+	Args:  cobra.NoArgs,
+	Use:   "lock -f ${CONFIG}",
+	Short: "Pin the repos of a KfDef to the sha256 of their archives.",
+	Long: `Download the archive of every repo of a KfDef, unless it's in the shared cache, and write` + "\n" +
+		`its sha256 into the repo. kfctl then refuses to use an archive with another digest, which` + "\n" +
+		`makes builds reproducible and tamper-evident. Repos that are pinned already are verified.` + "\n" +
+		`Only archives can be pinned; git and local directory repos are skipped.` + "\n" +
+		`To pin the repos of a config run -> ` + ColorPrint("kfctl alpha lock -f ${CONFIG}"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if configFilePath == "" {
+			return fmt.Errorf("Must pass in -f configFile")
+		}
+		isRemoteFile, err := utils.IsRemoteFile(configFilePath)
+		if err != nil {
+			return err
+		}
+		if isRemoteFile {
+			return fmt.Errorf("can only lock local files; download %v first", configFilePath)
+		}
+		cmd.SilenceUsage = true
+
+		config, err := kfloaders.LoadConfigFromURI(configFilePath, configLoadOption())
+		if err != nil {
+			return fmt.Errorf("couldn't load KfDef %v: %w", configFilePath, err)
+		}
+		digests := map[string]string{}
+		for _, repo := range config.Spec.Repos {
+			if !repo.IsArchive() {
+				log.Infof("Not locking repo %v; %v isn't an archive", repo.Name, repo.URI)
+				continue
+			}
+			digest, err := repo.ResolveDigest()
+			if err != nil {
+				return fmt.Errorf("couldn't lock repo %v: %w", repo.Name, err)
+			}
+			log.Infof("Locked repo %v to %v", repo.Name, digest)
+			digests[repo.Name] = strings.TrimPrefix(digest, "sha256:")
+		}
+
+		data, err := ioutil.ReadFile(configFilePath)
+		if err != nil {
+			return fmt.Errorf("couldn't read %v: %v", configFilePath, err)
+		}
+		data, missing, err := kfloaders.SetRepoDigests(data, digests)
+		if err != nil {
+			return fmt.Errorf("couldn't lock %v: %w", configFilePath, err)
+		}
+		for _, name := range missing {
+			log.Warnf("Repo %v isn't listed in %v, e.g. because it comes from a base; lock the base to pin it",
+				name, configFilePath)
+		}
+		if err := ioutil.WriteFile(configFilePath, data, 0644); err != nil {
+			return fmt.Errorf("couldn't write %v: %v", configFilePath, err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	alphaCmd.AddCommand(lockCmd)
+
+	lockCmd.Flags().StringVarP(&configFilePath, string(kftypes.FILE), "f", "",
+		`Static config file to use. Must be a local path.`)
+}
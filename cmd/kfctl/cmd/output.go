@@ -0,0 +1,140 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ghodss/yaml"
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	kftypes "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
+	kfdefsv1beta1 "github.com/kubeflow/kfctl/v3/pkg/apis/apps/kfdef/v1beta1"
+	"github.com/kubeflow/kfctl/v3/pkg/kfapp/coordinator"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Values of the global --output flag.
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+// outputFormat is set by the global --output flag.
+var outputFormat = outputText
+
+// commandResult is the document commands print with --output json|yaml.
+type commandResult struct {
+	Command      string                         `json:"command"`
+	Succeeded    bool                           `json:"succeeded"`
+	Version      string                         `json:"version,omitempty"`
+	Applications []kftypes.ApplicationResult    `json:"applications,omitempty"`
+	Conditions   []kfdefsv1beta1.KfDefCondition `json:"conditions,omitempty"`
+	Problems     []problem                      `json:"problems,omitempty"`
+	Error        *kfapis.KfError                `json:"error,omitempty"`
+}
+
+// problem is a validation problem of the field at Field.
+type problem struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// newProblems converts the errors returned by KfConfig.Validate.
+func newProblems(errs field.ErrorList) []problem {
+	problems := []problem{}
+	for _, err := range errs {
+		problems = append(problems, problem{Field: err.Field, Message: err.ErrorBody()})
+	}
+	return problems
+}
+
+// structuredOutput returns true if commands should print a machine-readable document.
+func structuredOutput() bool {
+	return outputFormat == outputJSON || outputFormat == outputYAML
+}
+
+// validateOutputFormat checks the value of the global --output flag.
+func validateOutputFormat(cmd *cobra.Command, args []string) error {
+	switch outputFormat {
+	case outputText, outputJSON, outputYAML:
+		return nil
+	}
+	return fmt.Errorf("unknown output format %v; must be one of %v, %v or %v", outputFormat,
+		outputText, outputJSON, outputYAML)
+}
+
+// newCommandResult builds the result document of cmd from kfApp, which may be nil if it
+// couldn't be loaded, and the error the command failed with.
+func newCommandResult(cmd *cobra.Command, kfApp kftypes.KfApp, err error) *commandResult {
+	result := &commandResult{
+		Command:   cmd.CommandPath(),
+		Succeeded: err == nil,
+	}
+	if r, ok := kfApp.(kftypes.KfResults); ok && r != nil {
+		result.Applications = r.Results()
+	}
+	if getter, ok := kfApp.(coordinator.KfDefGetterV1beta1); ok && getter != nil {
+		result.Conditions = getter.GetKfDefV1Beta1().Status.Conditions
+	}
+	if err != nil {
+		var kfErr *kfapis.KfError
+		if !errors.As(err, &kfErr) {
+			kfErr = &kfapis.KfError{
+				Code:    int(kfapis.UNKNOWN),
+				Message: err.Error(),
+			}
+		}
+		result.Error = kfErr
+	}
+	return result
+}
+
+// printDocument writes v to w in the requested structured format.
+func printDocument(w io.Writer, format string, v interface{}) error {
+	var data []byte
+	var err error
+	if format == outputJSON {
+		data, err = json.MarshalIndent(v, "", "  ")
+		data = append(data, '\n')
+	} else {
+		data, err = yaml.Marshal(v)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// emitResult prints the result document of cmd if structured output was requested and returns err.
+// kfErr is the error as returned by kfApp and provides the code in the document; err is the
+// error the command fails with. If kfErr is nil, err is used for both.
+func emitResult(cmd *cobra.Command, kfApp kftypes.KfApp, kfErr error, err error) error {
+	if !structuredOutput() {
+		return err
+	}
+	if kfErr == nil {
+		kfErr = err
+	}
+	if printErr := printDocument(cmd.OutOrStdout(), outputFormat, newCommandResult(cmd, kfApp, kfErr)); printErr != nil {
+		return fmt.Errorf("couldn't print result: %v", printErr)
+	}
+	return err
+}
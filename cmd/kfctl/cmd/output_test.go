@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	"github.com/spf13/cobra"
+)
+
+func TestEmitResult(t *testing.T) {
+	defer func(format string) { outputFormat = format }(outputFormat)
+
+	type testCase struct {
+		format   string
+		kfErr    error
+		err      error
+		expected string
+	}
+	tests := []testCase{
+		{
+			format:   outputText,
+			err:      fmt.Errorf("failed to apply"),
+			expected: "",
+		},
+		{
+			format:   outputJSON,
+			expected: "{\n  \"command\": \"kfctl apply\",\n  \"succeeded\": true\n}\n",
+		},
+		{
+			format: outputYAML,
+			kfErr: &kfapis.KfError{
+				Code:    int(kfapis.DEADLINE_EXCEEDED),
+				Message: "objects not ready",
+			},
+			err: fmt.Errorf("failed to apply: objects not ready"),
+			expected: "command: kfctl apply\n" +
+				"error:\n" +
+				"  code: 504\n" +
+				"  message: objects not ready\n" +
+				"succeeded: false\n",
+		},
+		{
+			format: outputYAML,
+			err:    fmt.Errorf("couldn't load KfUpgrade"),
+			expected: "command: kfctl apply\n" +
+				"error:\n" +
+				"  code: 520\n" +
+				"  message: couldn't load KfUpgrade\n" +
+				"succeeded: false\n",
+		},
+	}
+
+	root := &cobra.Command{Use: "kfctl"}
+	apply := &cobra.Command{Use: "apply"}
+	root.AddCommand(apply)
+	for _, c := range tests {
+		outputFormat = c.format
+		var buf bytes.Buffer
+		apply.SetOut(&buf)
+		if err := emitResult(apply, nil, c.kfErr, c.err); err != c.err {
+			t.Errorf("emitResult(%v) returned %v; want %v", c.format, err, c.err)
+		}
+		if buf.String() != c.expected {
+			t.Errorf("emitResult(%v) printed\n%v\nwant\n%v", c.format, buf.String(), c.expected)
+		}
+	}
+}
@@ -17,8 +17,16 @@ package cmd
 import (
 	"fmt"
 	kftypes "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
+	"github.com/kubeflow/kfctl/v3/pkg/kfconfig"
+	kfloaders "github.com/kubeflow/kfctl/v3/pkg/kfconfig/loaders"
+	"github.com/kubeflow/kfctl/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 )
 
 func processResourceArg(args []string) (kftypes.ResourceEnum, error) {
@@ -45,27 +53,132 @@ var rootCmd = &cobra.Command{
 	Use:   "kfctl",
 	Short: "A client CLI to create kubeflow applications",
 	Long: `A client CLI to create kubeflow applications for specific platforms or 'on-prem' 
-to an existing k8s cluster.`,
+to an existing k8s cluster.
+
+` + exitCodesHelp,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		utils.SetShowSecrets(showSecrets)
+		setOffline()
+		return validateOutputFormat(cmd, args)
+	},
 }
 
 var (
 	// VERSION is set during build
 	VERSION string
+
+	// strictDecoding is set by the global --strict flag.
+	strictDecoding = true
+
+	// configVars and configVarFiles are set by the global --var and --var-file flags.
+	configVars     map[string]string
+	configVarFiles []string
+
+	// showSecrets is set by the global --show-secrets flag.
+	showSecrets bool
+
+	// offlineMode and bundleDir are set by the global --offline and --bundle flags.
+	offlineMode bool
+	bundleDir   string
 )
 
+// setOffline passes the --offline and --bundle flags to kfconfig. --bundle implies --offline
+// and without it a bundle the config is part of, i.e. with a bundle.json next to it, is used.
+func setOffline() {
+	offline := offlineMode || bundleDir != ""
+	dir := bundleDir
+	if offline && dir == "" && configFilePath != "" {
+		if isRemote, err := utils.IsRemoteFile(configFilePath); err == nil && !isRemote {
+			candidate := filepath.Dir(configFilePath)
+			if _, err := os.Stat(filepath.Join(candidate, kfconfig.BundleIndexFile)); err == nil {
+				log.Infof("Using the bundle %v of %v", candidate, configFilePath)
+				dir = candidate
+			}
+		}
+	}
+	kfconfig.SetOffline(offline, dir)
+}
+
+// configLoadOption passes the global config flags to the loaders: the variables set with
+// --var and --var-file and, as an annotation that also reaches the KfApp, --strict. Unless
+// --strict is given, the strict annotation of the config itself applies.
+func configLoadOption() kfloaders.LoadOption {
+	opts := []kfloaders.LoadOption{
+		kfloaders.WithVarFiles(configVarFiles...),
+		kfloaders.WithVars(configVars),
+	}
+	if rootCmd.PersistentFlags().Changed(string(kftypes.STRICT)) {
+		opts = append(opts, kfloaders.WithAnnotations(map[string]string{
+			strings.Join([]string{utils.KfDefAnnotation, utils.Strict}, "/"): strconv.FormatBool(strictDecoding),
+		}))
+	}
+	return kfloaders.WithOptions(opts...)
+}
+
+// addAppSelectionFlags adds the --app and --skip-app flags to cmd and binds them to cfg.
+func addAppSelectionFlags(cmd *cobra.Command, cfg *viper.Viper) {
+	cmd.Flags().StringSlice(string(kftypes.APP), nil,
+		"Only work on this application of the KfDef. Can be repeated.")
+	cmd.Flags().StringSlice(string(kftypes.SKIP_APP), nil,
+		"Leave out this application of the KfDef. Can be repeated.")
+	for _, flag := range []kftypes.CliOption{kftypes.APP, kftypes.SKIP_APP} {
+		if bindErr := cfg.BindPFlag(string(flag), cmd.Flags().Lookup(string(flag))); bindErr != nil {
+			log.Errorf("Couldn't set flag --%v: %v", string(flag), bindErr)
+		}
+	}
+}
+
+// appSelectionAnnotations passes the --app and --skip-app flags bound to cfg to the KfApp.
+// They are always set so that a selection saved with the app config doesn't outlive the command.
+func appSelectionAnnotations(cfg *viper.Viper) map[string]string {
+	return map[string]string{
+		strings.Join([]string{utils.KfDefAnnotation, utils.Apps}, "/"): strings.Join(
+			cfg.GetStringSlice(string(kftypes.APP)), ","),
+		strings.Join([]string{utils.KfDefAnnotation, utils.SkipApps}, "/"): strings.Join(
+			cfg.GetStringSlice(string(kftypes.SKIP_APP)), ","),
+	}
+}
+
+// appSubset returns true if the --app or --skip-app flags bound to cfg are set.
+func appSubset(cfg *viper.Viper) bool {
+	return len(cfg.GetStringSlice(string(kftypes.APP))) > 0 || len(cfg.GetStringSlice(string(kftypes.SKIP_APP))) > 0
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute(version string) {
 	VERSION = version
 
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Printf("kfctl exited with error: %+v", err)
-		os.Exit(1)
+		if structuredOutput() {
+			// Keep stdout parseable; the error is part of the result document.
+			fmt.Fprintf(os.Stderr, "kfctl exited with error: %+v\n", err)
+		} else {
+			fmt.Printf("kfctl exited with error: %+v", err)
+		}
+		os.Exit(exitCode(err))
 	}
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
+	log.AddHook(utils.RedactHook())
+
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, string(kftypes.OUTPUT), "o", outputText,
+		"Output format. One of text, json or yaml. json and yaml print a result document to stdout.")
+	rootCmd.PersistentFlags().BoolVar(&strictDecoding, string(kftypes.STRICT), true,
+		"Fail on config fields kfctl doesn't know, e.g. misspelled ones. With --strict=false they are only warnings.")
+	rootCmd.PersistentFlags().StringToStringVar(&configVars, string(kftypes.VAR), nil,
+		"Set a variable referenced as ${NAME} in the config, e.g. --var HOSTNAME=kf.example.com. Can be repeated.")
+	rootCmd.PersistentFlags().StringArrayVar(&configVarFiles, string(kftypes.VAR_FILE), nil,
+		"YAML file mapping the names of variables referenced in the config to their values. Can be repeated.\n"+
+			"--var takes precedence over variable files, which take precedence over the environment.")
+	rootCmd.PersistentFlags().BoolVar(&showSecrets, string(kftypes.SHOW_SECRETS), false,
+		"Don't redact secrets in logs, dumps and the KfDefs kfctl writes.")
+	rootCmd.PersistentFlags().BoolVar(&offlineMode, string(kftypes.OFFLINE), false,
+		"Never use the network. Repos come from the bundle or the shared cache and remote configs are refused.")
+	rootCmd.PersistentFlags().StringVar(&bundleDir, string(kftypes.BUNDLE), "",
+		"Directory of an extracted bundle made by 'kfctl alpha bundle create' to take repos from. Implies --offline.")
 }
 
 // initConfig creates a Viper config file and set's it's name and type
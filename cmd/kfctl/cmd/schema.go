@@ -0,0 +1,53 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubeflow/kfctl/v3/pkg/apis/apps/schema"
+	"github.com/spf13/cobra"
+)
+
+// schemaCmd represents the schema command
+var schemaCmd = &cobra.Command{
+	Args:  cobra.MaximumNArgs(1),
+	Use:   "schema [" + strings.Join(schema.Kinds(), "|") + "]",
+	Short: "Print the JSON schema of a kfctl config kind.",
+	Long: `Print the JSON schema of a kfctl config kind; the default is KfDef.` + "\n" +
+		`Point an editor at the schema to get completion and checks while writing a KfDef.` + "\n" +
+		`The KfDef schema checks the spec of every plugin against the schema of its kind.` + "\n" +
+		`The schema is printed as JSON unless --output yaml is given.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		kind := schema.KfDefKind
+		if len(args) > 0 {
+			kind = args[0]
+		}
+		s, err := schema.Document(kind)
+		if err != nil {
+			return fmt.Errorf("couldn't generate schema: %w", err)
+		}
+		format := outputJSON
+		if outputFormat == outputYAML {
+			format = outputYAML
+		}
+		return printDocument(cmd.OutOrStdout(), format, s)
+	},
+}
+
+func init() {
+	alphaCmd.AddCommand(schemaCmd)
+}
@@ -40,18 +40,18 @@ var showCmd = &cobra.Command{
 		if resourceErr != nil {
 			return fmt.Errorf("invalid resource: %v", resourceErr)
 		}
-		kfApp, kfAppErr := coordinator.NewLoadKfAppFromURI(configFilePath)
+		kfApp, kfAppErr := coordinator.NewLoadKfAppFromURI(configFilePath, configLoadOption())
 		if kfAppErr != nil {
-			return fmt.Errorf("couldn't load KfApp: %v", kfAppErr)
+			return emitResult(cmd, nil, kfAppErr, fmt.Errorf("couldn't load KfApp: %w", kfAppErr))
 		}
 		show, ok := kfApp.(kftypes.KfShow)
 		if ok && show != nil {
 			showErr := show.Show(resource)
 			if showErr != nil {
-				return fmt.Errorf("couldn't show KfApp: %v", showErr)
+				return emitResult(cmd, kfApp, showErr, fmt.Errorf("couldn't show KfApp: %w", showErr))
 			}
 		}
-		return nil
+		return emitResult(cmd, kfApp, nil, nil)
 	},
 }
 
@@ -0,0 +1,130 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	kftypes "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
+	"github.com/kubeflow/kfctl/v3/pkg/kfapp/coordinator"
+	kfloaders "github.com/kubeflow/kfctl/v3/pkg/kfconfig/loaders"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var statusCfg = viper.New()
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Args:  cobra.NoArgs,
+	Use:   "status -f ${CONFIG}",
+	Short: "Report the health of the applications in the cluster.",
+	Long: `'kfctl status' renders every application of a KFDef config and checks its objects in the cluster.` + "\n" +
+		`Objects are reported as Ready, Drifted, NotReady or Missing. The command fails if any object` + "\n" +
+		`is NotReady or Missing so it can be used as a post-deploy check.` + "\n" +
+		`To check an install run -> ` + ColorPrint("kfctl status -f ${CONFIG}"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.SetLevel(log.InfoLevel)
+		if statusCfg.GetBool(string(kftypes.VERBOSE)) != true {
+			log.SetLevel(log.WarnLevel)
+		}
+
+		if configFilePath == "" {
+			return fmt.Errorf("Must pass in -f configFile")
+		}
+		cmd.SilenceUsage = true
+
+		kfApp, err := coordinator.NewLoadKfAppFromURI(configFilePath,
+			kfloaders.WithAnnotations(appSelectionAnnotations(statusCfg)), configLoadOption())
+		if err != nil {
+			return fmt.Errorf("failed to build kfApp from URI %s: %w", configFilePath, err)
+		}
+		status, ok := kfApp.(kftypes.KfStatus)
+		if !ok || status == nil {
+			return fmt.Errorf("kfApp doesn't support status")
+		}
+		statuses, err := status.Status(kftypes.K8S)
+		if err != nil {
+			return fmt.Errorf("couldn't get status of KfApp: %w", err)
+		}
+		if err := printStatus(cmd.OutOrStdout(), outputFormat, statuses); err != nil {
+			return err
+		}
+
+		degraded := 0
+		for _, s := range statuses {
+			if s.Degraded {
+				degraded++
+			}
+		}
+		if degraded > 0 {
+			return kfapis.NewKfError(kfapis.ErrNotReady, "%v of %v applications are degraded", degraded, len(statuses))
+		}
+		return nil
+	},
+}
+
+// printStatus writes statuses to w as a table per application, JSON or YAML.
+func printStatus(w io.Writer, output string, statuses []kftypes.ApplicationStatus) error {
+	if output != outputText {
+		return printDocument(w, output, statuses)
+	}
+
+	for i, s := range statuses {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		health := "Healthy"
+		if s.Degraded {
+			health = "Degraded"
+		}
+		fmt.Fprintf(w, "APPLICATION %v: %v\n", s.Name, health)
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "OBJECT\tHEALTH\tMESSAGE")
+		for _, o := range s.Objects {
+			fmt.Fprintf(tw, "%v\t%v\t%v\n", o.Key, o.Health, o.Message)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	statusCfg.SetConfigName("app")
+	statusCfg.SetConfigType("yaml")
+
+	statusCmd.PersistentFlags().StringVarP(&configFilePath, string(kftypes.FILE), "f", "",
+		`Static config file to use. Can be either a local path or a URL.`)
+
+	// verbose output
+	statusCmd.Flags().BoolP(string(kftypes.VERBOSE), "V", false,
+		string(kftypes.VERBOSE)+" output default is false")
+	bindErr := statusCfg.BindPFlag(string(kftypes.VERBOSE), statusCmd.Flags().Lookup(string(kftypes.VERBOSE)))
+	if bindErr != nil {
+		log.Errorf("Couldn't set flag --%v: %v", string(kftypes.VERBOSE), bindErr)
+		return
+	}
+
+	// only check some of the applications.
+	addAppSelectionFlags(statusCmd, statusCfg)
+}
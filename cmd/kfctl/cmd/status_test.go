@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	kftypes "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
+)
+
+func TestPrintStatus(t *testing.T) {
+	statuses := []kftypes.ApplicationStatus{
+		{
+			Name:     "centraldashboard",
+			Degraded: true,
+			Objects: []kftypes.ObjectStatus{
+				{Key: "Service/kubeflow/centraldashboard", Health: kftypes.ObjectReady},
+				{Key: "Deployment/kubeflow/centraldashboard", Health: kftypes.ObjectNotReady,
+					Message: "0 of 1 updated replicas available"},
+			},
+		},
+	}
+
+	tests := map[string]string{
+		"text": "APPLICATION centraldashboard: Degraded\n" +
+			"OBJECT                                HEALTH    MESSAGE\n" +
+			"Service/kubeflow/centraldashboard     Ready     \n" +
+			"Deployment/kubeflow/centraldashboard  NotReady  0 of 1 updated replicas available\n",
+		"yaml": "- degraded: true\n" +
+			"  name: centraldashboard\n" +
+			"  objects:\n" +
+			"  - health: Ready\n" +
+			"    key: Service/kubeflow/centraldashboard\n" +
+			"  - health: NotReady\n" +
+			"    key: Deployment/kubeflow/centraldashboard\n" +
+			"    message: 0 of 1 updated replicas available\n",
+	}
+	for output, expected := range tests {
+		var buf bytes.Buffer
+		if err := printStatus(&buf, output, statuses); err != nil {
+			t.Fatalf("printStatus(%v) failed: %v", output, err)
+		}
+		if buf.String() != expected {
+			t.Errorf("printStatus(%v) =\n%v\nwant\n%v", output, buf.String(), expected)
+		}
+	}
+}
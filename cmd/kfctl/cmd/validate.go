@@ -0,0 +1,102 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	kftypes "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
+	"github.com/kubeflow/kfctl/v3/pkg/kfconfig"
+	kfloaders "github.com/kubeflow/kfctl/v3/pkg/kfconfig/loaders"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var validateCfg = viper.New()
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Args:  cobra.NoArgs,
+	Use:   "validate -f ${CONFIG}",
+	Short: "Check a KFDef config for problems without contacting the cluster.",
+	Long: `'kfctl validate' checks a KFDef config and reports every problem with the path of the field.` + "\n" +
+		`It catches duplicate applications, references to undeclared repos, secrets or applications,` + "\n" +
+		`overlays missing from the repo cache, invalid names and unknown plugin kinds.` + "\n" +
+		`To check a config run -> ` + ColorPrint("kfctl validate -f ${CONFIG}"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.SetLevel(log.InfoLevel)
+		if validateCfg.GetBool(string(kftypes.VERBOSE)) != true {
+			log.SetLevel(log.WarnLevel)
+		}
+
+		if configFilePath == "" {
+			return fmt.Errorf("Must pass in -f configFile")
+		}
+		cmd.SilenceUsage = true
+
+		config, err := kfloaders.LoadConfigFromURI(configFilePath, configLoadOption())
+		if err != nil {
+			return emitResult(cmd, nil, err, fmt.Errorf("couldn't load KfDef %v: %w", configFilePath, err))
+		}
+		errs := config.Validate()
+		validationErr := kfconfig.ValidationError(errs)
+		if structuredOutput() {
+			result := newCommandResult(cmd, nil, validationErr)
+			result.Problems = newProblems(errs)
+			if err := printDocument(cmd.OutOrStdout(), outputFormat, result); err != nil {
+				return err
+			}
+		} else {
+			printProblems(cmd.OutOrStdout(), configFilePath, errs)
+		}
+		if validationErr != nil {
+			return fmt.Errorf("%v has %v problems: %w", configFilePath, len(errs), validationErr)
+		}
+		return nil
+	},
+}
+
+// printProblems writes one line per problem, or a confirmation if there are none.
+func printProblems(w io.Writer, configFile string, errs field.ErrorList) {
+	if len(errs) == 0 {
+		fmt.Fprintf(w, "%v is valid\n", configFile)
+		return
+	}
+	for _, err := range errs {
+		fmt.Fprintln(w, err.Error())
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCfg.SetConfigName("app")
+	validateCfg.SetConfigType("yaml")
+
+	validateCmd.PersistentFlags().StringVarP(&configFilePath, string(kftypes.FILE), "f", "",
+		`Static config file to use. Can be either a local path or a URL.`)
+
+	// verbose output
+	validateCmd.Flags().BoolP(string(kftypes.VERBOSE), "V", false,
+		string(kftypes.VERBOSE)+" output default is false")
+	bindErr := validateCfg.BindPFlag(string(kftypes.VERBOSE), validateCmd.Flags().Lookup(string(kftypes.VERBOSE)))
+	if bindErr != nil {
+		log.Errorf("Couldn't set flag --%v: %v", string(kftypes.VERBOSE), bindErr)
+		return
+	}
+}
@@ -25,8 +25,16 @@ var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version of kfctl.",
 	Long:  `Print the version of kfctl.`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if structuredOutput() {
+			return printDocument(cmd.OutOrStdout(), outputFormat, &commandResult{
+				Command:   cmd.CommandPath(),
+				Succeeded: true,
+				Version:   VERSION,
+			})
+		}
 		fmt.Println(rootCmd.Use + " " + VERSION)
+		return nil
 	}}
 
 func init() {
@@ -103,6 +103,27 @@ const (
 	FILE                  CliOption = "file"
 	FORCE_DELETION        CliOption = "force-deletion"
 	DUMP                  CliOption = "dump"
+	DUMP_CONFIG           CliOption = "dump-config"
+	FORCE_CONFLICTS       CliOption = "force-conflicts"
+	PRUNE                 CliOption = "prune"
+	PRUNE_DRY_RUN         CliOption = "prune-dry-run"
+	WAIT                  CliOption = "wait"
+	WAIT_TIMEOUT          CliOption = "wait-timeout"
+	PARALLELISM           CliOption = "parallelism"
+	OUTPUT                CliOption = "output"
+	OUT_FILE              CliOption = "out-file"
+	STRICT                CliOption = "strict"
+	TO                    CliOption = "to"
+	VAR                   CliOption = "var"
+	VAR_FILE              CliOption = "var-file"
+	APP                   CliOption = "app"
+	SKIP_APP              CliOption = "skip-app"
+	SHOW_SECRETS          CliOption = "show-secrets"
+	OLDER_THAN            CliOption = "older-than"
+	PRUNE_ALL             CliOption = "all"
+	REMOVE                CliOption = "remove"
+	OFFLINE               CliOption = "offline"
+	BUNDLE                CliOption = "bundle"
 )
 
 //
@@ -134,6 +155,76 @@ type KfShow interface {
 	Show(resources ResourceEnum) error
 }
 
+//
+// This is used by `kfctl diff` to compare the rendered manifests against the cluster
+//
+type KfDiff interface {
+	Diff(resources ResourceEnum) error
+}
+
+//
+// This is used by `kfctl status` to report the health of the applications in the cluster
+//
+type KfStatus interface {
+	Status(resources ResourceEnum) ([]ApplicationStatus, error)
+}
+
+//
+// This is used by `kfctl alpha bundle create` to list the container images of the rendered applications
+//
+type KfImages interface {
+	Images() ([]string, error)
+}
+
+//
+// This is used by the --output flag to report the applications and objects processed by a command
+//
+type KfResults interface {
+	Results() []ApplicationResult
+}
+
+// ObjectResult is what a command did to a single object, e.g. created, configured or deleted.
+type ObjectResult struct {
+	Key     string `json:"key"`
+	Action  string `json:"action"`
+	Message string `json:"message,omitempty"`
+}
+
+// ApplicationResult lists the objects a command touched for an application.
+type ApplicationResult struct {
+	Name    string         `json:"name"`
+	Objects []ObjectResult `json:"objects,omitempty"`
+}
+
+// ObjectHealth describes the state of a rendered object in the cluster.
+type ObjectHealth string
+
+const (
+	// ObjectReady means the object exists, is ready and matches the rendered manifests.
+	ObjectReady ObjectHealth = "Ready"
+	// ObjectDrifted means the object is ready but differs from the rendered manifests.
+	ObjectDrifted ObjectHealth = "Drifted"
+	// ObjectNotReady means the object exists but didn't reach its ready state.
+	ObjectNotReady ObjectHealth = "NotReady"
+	// ObjectMissing means the object doesn't exist in the cluster.
+	ObjectMissing ObjectHealth = "Missing"
+)
+
+// ObjectStatus is the health of a single object of an application.
+type ObjectStatus struct {
+	Key     string       `json:"key"`
+	Health  ObjectHealth `json:"health"`
+	Message string       `json:"message,omitempty"`
+}
+
+// ApplicationStatus is the health of an application.
+// An application is degraded if any of its objects is missing or not ready.
+type ApplicationStatus struct {
+	Name     string         `json:"name"`
+	Degraded bool           `json:"degraded"`
+	Objects  []ObjectStatus `json:"objects,omitempty"`
+}
+
 // QuoteItems will place quotes around the string arrays items
 func QuoteItems(items []string) []string {
 	var withQuotes []string
@@ -605,7 +605,7 @@ func IsPluginNotFound(e error) bool {
 	if e == nil {
 		return false
 	}
-	err, ok := e.(*kfapis.KfError)
+	err, ok := kfapis.AsKfError(e)
 	return ok && err.Code == int(kfapis.NOT_FOUND) && strings.HasPrefix(err.Message, pluginNotFoundErrPrefix)
 }
 
@@ -613,7 +613,7 @@ func IsConditionNotFound(e error) bool {
 	if e == nil {
 		return false
 	}
-	err, ok := e.(*kfapis.KfError)
+	err, ok := kfapis.AsKfError(e)
 	return ok && err.Code == int(kfapis.NOT_FOUND) &&
 		strings.HasPrefix(err.Message, conditionNotFoundErrPrefix)
 }
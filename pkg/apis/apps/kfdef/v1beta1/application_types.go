@@ -49,11 +49,15 @@ type KfDefList struct {
 }
 
 type KfDefSpec struct {
+	// Bases are the URIs of KfDefs this KfDef is an overlay of, relative to this KfDef.
+	// The loader merges this KfDef into the bases in order, with strategic merge patch
+	// semantics: applications, repos and secrets are merged by name and plugins by kind.
+	Bases        []string      `json:"bases,omitempty"`
 	Version      string        `json:"version,omitempty"`
-	Applications []Application `json:"applications,omitempty"`
-	Plugins      []Plugin      `json:"plugins,omitempty"`
-	Secrets      []Secret      `json:"secrets,omitempty"`
-	Repos        []Repo        `json:"repos,omitempty"`
+	Applications []Application `json:"applications,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+	Plugins      []Plugin      `json:"plugins,omitempty" patchStrategy:"merge" patchMergeKey:"kind"`
+	Secrets      []Secret      `json:"secrets,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+	Repos        []Repo        `json:"repos,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
 }
 
 // Application defines an application to install
@@ -64,8 +68,8 @@ type Application struct {
 
 type KustomizeConfig struct {
 	RepoRef    *RepoRef    `json:"repoRef,omitempty"`
-	Overlays   []string    `json:"overlays,omitempty"`
-	Parameters []NameValue `json:"parameters,omitempty"`
+	Overlays   []string    `json:"overlays,omitempty" patchStrategy:"merge"`
+	Parameters []NameValue `json:"parameters,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
 }
 
 type RepoRef struct {
@@ -94,18 +98,54 @@ type Secret struct {
 }
 
 type SecretSource struct {
-	LiteralSource *LiteralSource `json:"literalSource,omitempty"`
-	EnvSource     *EnvSource     `json:"envSource,omitempty"`
+	LiteralSource   *LiteralSource   `json:"literalSource,omitempty"`
+	EnvSource       *EnvSource       `json:"envSource,omitempty"`
+	FileSource      *FileSource      `json:"fileSource,omitempty"`
+	K8sSecretSource *K8sSecretSource `json:"k8sSecretSource,omitempty"`
+	VaultSource     *VaultSource     `json:"vaultSource,omitempty"`
+	SopsSource      *SopsSource      `json:"sopsSource,omitempty"`
 }
 
 type LiteralSource struct {
-	Value string `json:"value,omitempty"`
+	Value string `json:"value,omitempty" kfctl:"secret"`
 }
 
 type EnvSource struct {
 	Name string `json:"name,omitempty"`
 }
 
+// FileSource reads the secret from a file, relative to the app dir. A trailing newline is
+// not part of the secret.
+type FileSource struct {
+	Path string `json:"path,omitempty"`
+}
+
+// K8sSecretSource reads the secret from a key of a Kubernetes Secret. The namespace
+// defaults to the namespace of the KfDef.
+type K8sSecretSource struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Key       string `json:"key,omitempty"`
+}
+
+// VaultSource reads the secret from a key of a HashiCorp Vault KV secret. The address
+// defaults to $VAULT_ADDR, the mount to secret and the KV version to 2.
+type VaultSource struct {
+	Address   string `json:"address,omitempty"`
+	Mount     string `json:"mount,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Key       string `json:"key,omitempty"`
+	KVVersion int    `json:"kvVersion,omitempty"`
+}
+
+// SopsSource reads the secret from a top level key of a document encrypted with SOPS,
+// either the file Path, relative to the app dir, or Value.
+type SopsSource struct {
+	Path  string `json:"path,omitempty"`
+	Value string `json:"value,omitempty"`
+	Key   string `json:"key,omitempty"`
+}
+
 // SecretRef is a reference to a secret
 type SecretRef struct {
 	// Name of the secret
@@ -121,6 +161,14 @@ type Repo struct {
 	// Can use any URI understood by go-getter:
 	// https://github.com/hashicorp/go-getter/blob/master/README.md#installation-and-usage
 	URI string `json:"uri,omitempty"`
+	// Sha256 is the digest of the archive at URI. If set, kfctl fails if the downloaded
+	// archive has another digest. kfctl alpha lock sets it.
+	Sha256 string `json:"sha256,omitempty"`
+	// Ref is the branch, tag, commit or other ref, e.g. pull/<ID>/head, of a git:: repo.
+	// The default branch is used if it's empty.
+	Ref string `json:"ref,omitempty"`
+	// SubPath is the directory of the repo that applications are relative to.
+	SubPath string `json:"subPath,omitempty"`
 }
 
 // KfDefStatus defines the observed state of KfDef
@@ -133,6 +181,10 @@ type KfDefStatus struct {
 type RepoCache struct {
 	Name      string `json:"name,omitempty"`
 	LocalPath string `json:"localPath,string"`
+	// Digest of the archive the repo was fetched from, e.g. sha256:0123....
+	Digest string `json:"digest,omitempty"`
+	// Commit of a git:: repo that Ref resolved to.
+	Commit string `json:"commit,omitempty"`
 }
 
 type KfDefConditionType string
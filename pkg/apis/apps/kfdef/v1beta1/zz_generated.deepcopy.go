@@ -61,6 +61,38 @@ func (in *EnvSource) DeepCopy() *EnvSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileSource) DeepCopyInto(out *FileSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileSource.
+func (in *FileSource) DeepCopy() *FileSource {
+	if in == nil {
+		return nil
+	}
+	out := new(FileSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *K8sSecretSource) DeepCopyInto(out *K8sSecretSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new K8sSecretSource.
+func (in *K8sSecretSource) DeepCopy() *K8sSecretSource {
+	if in == nil {
+		return nil
+	}
+	out := new(K8sSecretSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KfDef) DeepCopyInto(out *KfDef) {
 	*out = *in
@@ -143,6 +175,11 @@ func (in *KfDefList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KfDefSpec) DeepCopyInto(out *KfDefSpec) {
 	*out = *in
+	if in.Bases != nil {
+		in, out := &in.Bases, &out.Bases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Applications != nil {
 		in, out := &in.Applications, &out.Applications
 		*out = make([]Application, len(*in))
@@ -394,6 +431,26 @@ func (in *SecretSource) DeepCopyInto(out *SecretSource) {
 		*out = new(EnvSource)
 		**out = **in
 	}
+	if in.FileSource != nil {
+		in, out := &in.FileSource, &out.FileSource
+		*out = new(FileSource)
+		**out = **in
+	}
+	if in.K8sSecretSource != nil {
+		in, out := &in.K8sSecretSource, &out.K8sSecretSource
+		*out = new(K8sSecretSource)
+		**out = **in
+	}
+	if in.VaultSource != nil {
+		in, out := &in.VaultSource, &out.VaultSource
+		*out = new(VaultSource)
+		**out = **in
+	}
+	if in.SopsSource != nil {
+		in, out := &in.SopsSource, &out.SopsSource
+		*out = new(SopsSource)
+		**out = **in
+	}
 	return
 }
 
@@ -406,3 +463,35 @@ func (in *SecretSource) DeepCopy() *SecretSource {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SopsSource) DeepCopyInto(out *SopsSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SopsSource.
+func (in *SopsSource) DeepCopy() *SopsSource {
+	if in == nil {
+		return nil
+	}
+	out := new(SopsSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultSource) DeepCopyInto(out *VaultSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSource.
+func (in *VaultSource) DeepCopy() *VaultSource {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultSource)
+	in.DeepCopyInto(out)
+	return out
+}
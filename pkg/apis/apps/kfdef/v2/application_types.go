@@ -0,0 +1,217 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KfDef is the Schema for the kfdefs API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+type KfDef struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KfDefSpec   `json:"spec,omitempty"`
+	Status KfDefStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// KfDefList contains a list of KfDef
+type KfDefList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KfDef `json:"items"`
+}
+
+// KfDefSpec defines the desired state of KfDef. Platform specific settings such as the
+// GCP project only live in the spec of the platform plugin.
+type KfDefSpec struct {
+	// Bases are the URIs of KfDefs this KfDef is an overlay of, relative to this KfDef.
+	// The loader merges this KfDef into the bases in order, with strategic merge patch
+	// semantics: applications, repos and secrets are merged by name and plugins by kind.
+	Bases        []string      `json:"bases,omitempty"`
+	Version      string        `json:"version,omitempty"`
+	Applications []Application `json:"applications,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+	Plugins      []Plugin      `json:"plugins,omitempty" patchStrategy:"merge" patchMergeKey:"kind"`
+	Secrets      []Secret      `json:"secrets,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+	Repos        []Repo        `json:"repos,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+}
+
+// Application defines an application to install
+type Application struct {
+	Name string `json:"name,omitempty"`
+	// Namespace the objects of the application are installed in.
+	// Defaults to the namespace of the KfDef.
+	Namespace string `json:"namespace,omitempty"`
+	// Enabled is false for applications that are declared but not installed. Defaults to true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// Labels are added to every object of the application.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Version of the application. It is set as the app.kubernetes.io/version label of its objects.
+	Version string `json:"version,omitempty"`
+	// DependsOn lists the applications that must be applied and ready before this one.
+	DependsOn       []string         `json:"dependsOn,omitempty"`
+	KustomizeConfig *KustomizeConfig `json:"kustomizeConfig,omitempty"`
+}
+
+type KustomizeConfig struct {
+	RepoRef    *RepoRef    `json:"repoRef,omitempty"`
+	Overlays   []string    `json:"overlays,omitempty" patchStrategy:"merge"`
+	Parameters []NameValue `json:"parameters,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+}
+
+type RepoRef struct {
+	Name string `json:"name,omitempty"`
+	Path string `json:"path,omitempty"`
+}
+
+type NameValue struct {
+	Name  string `json:"name,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// Plugin can be used to customize the generation and deployment of Kubeflow
+type Plugin struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec *runtime.RawExtension `json:"spec,omitempty"`
+}
+
+// Secret provides information about secrets needed to configure Kubeflow.
+// Secrets can be provided via references.
+type Secret struct {
+	Name         string        `json:"name,omitempty"`
+	SecretSource *SecretSource `json:"secretSource,omitempty"`
+}
+
+type SecretSource struct {
+	LiteralSource   *LiteralSource   `json:"literalSource,omitempty"`
+	EnvSource       *EnvSource       `json:"envSource,omitempty"`
+	FileSource      *FileSource      `json:"fileSource,omitempty"`
+	K8sSecretSource *K8sSecretSource `json:"k8sSecretSource,omitempty"`
+	VaultSource     *VaultSource     `json:"vaultSource,omitempty"`
+	SopsSource      *SopsSource      `json:"sopsSource,omitempty"`
+}
+
+type LiteralSource struct {
+	Value string `json:"value,omitempty" kfctl:"secret"`
+}
+
+type EnvSource struct {
+	Name string `json:"name,omitempty"`
+}
+
+// FileSource reads the secret from a file, relative to the app dir. A trailing newline is
+// not part of the secret.
+type FileSource struct {
+	Path string `json:"path,omitempty"`
+}
+
+// K8sSecretSource reads the secret from a key of a Kubernetes Secret. The namespace
+// defaults to the namespace of the KfDef.
+type K8sSecretSource struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Key       string `json:"key,omitempty"`
+}
+
+// VaultSource reads the secret from a key of a HashiCorp Vault KV secret. The address
+// defaults to $VAULT_ADDR, the mount to secret and the KV version to 2.
+type VaultSource struct {
+	Address   string `json:"address,omitempty"`
+	Mount     string `json:"mount,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Key       string `json:"key,omitempty"`
+	KVVersion int    `json:"kvVersion,omitempty"`
+}
+
+// SopsSource reads the secret from a top level key of a document encrypted with SOPS,
+// either the file Path, relative to the app dir, or Value.
+type SopsSource struct {
+	Path  string `json:"path,omitempty"`
+	Value string `json:"value,omitempty"`
+	Key   string `json:"key,omitempty"`
+}
+
+// Repo provides information about a repository providing config (e.g. kustomize packages,
+// Deployment manager configs, etc...)
+type Repo struct {
+	// Name is a name to identify the repository.
+	Name string `json:"name,omitempty"`
+	// URI where repository can be obtained.
+	// Can use any URI understood by go-getter:
+	// https://github.com/hashicorp/go-getter/blob/master/README.md#installation-and-usage
+	URI string `json:"uri,omitempty"`
+	// Sha256 is the digest of the archive at URI. If set, kfctl fails if the downloaded
+	// archive has another digest. kfctl alpha lock sets it.
+	Sha256 string `json:"sha256,omitempty"`
+	// Ref is the branch, tag, commit or other ref, e.g. pull/<ID>/head, of a git:: repo.
+	// The default branch is used if it's empty.
+	Ref string `json:"ref,omitempty"`
+	// SubPath is the directory of the repo that applications are relative to.
+	SubPath string `json:"subPath,omitempty"`
+}
+
+// KfDefStatus defines the observed state of KfDef
+type KfDefStatus struct {
+	Conditions []KfDefCondition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// ReposCache is used to cache information about local caching of the URIs.
+	ReposCache []RepoCache `json:"reposCache,omitempty"`
+}
+
+type RepoCache struct {
+	Name      string `json:"name,omitempty"`
+	LocalPath string `json:"localPath,omitempty"`
+	// Digest of the archive the repo was fetched from, e.g. sha256:0123....
+	Digest string `json:"digest,omitempty"`
+	// Commit of a git:: repo that Ref resolved to.
+	Commit string `json:"commit,omitempty"`
+}
+
+type KfDefConditionType string
+
+const (
+	// KfAvailable means Kubeflow is serving.
+	KfAvailable KfDefConditionType = "Available"
+
+	// KfDegraded means one or more Kubeflow services are not healthy.
+	KfDegraded KfDefConditionType = "Degraded"
+
+	// Pending means Kubeflow services is being updated.
+	Pending KfDefConditionType = "Pending"
+)
+
+type KfDefCondition struct {
+	// Type of deployment condition.
+	Type KfDefConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status v1.ConditionStatus `json:"status"`
+	// The last time this condition was updated.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// The reason for the condition's last transition.
+	Reason string `json:"reason,omitempty"`
+	// A human readable message indicating details about the transition.
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,44 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build ignore
+// +build ignore
+
+// gen_crd regenerates the validation of the KfDef CRD given as its only argument.
+package main
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/kubeflow/kfctl/v3/pkg/apis/apps/schema"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatalf("usage: gen_crd CRD_FILE")
+	}
+	crd, err := ioutil.ReadFile(os.Args[1])
+	if err != nil {
+		log.Fatalf("Couldn't read %v: %v", os.Args[1], err)
+	}
+	updated, err := schema.UpdateKfDefCRD(crd)
+	if err != nil {
+		log.Fatalf("Couldn't update %v: %v", os.Args[1], err)
+	}
+	if err := ioutil.WriteFile(os.Args[1], updated, 0644); err != nil {
+		log.Fatalf("Couldn't write %v: %v", os.Args[1], err)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	imagemirrorv1alpha1 "github.com/kubeflow/kfctl/v3/pkg/apis/apps/imagemirror/v1alpha1"
+	kfdefv1 "github.com/kubeflow/kfctl/v3/pkg/apis/apps/kfdef/v1"
+	kfupgradev1alpha1 "github.com/kubeflow/kfctl/v3/pkg/apis/apps/kfupgrade/v1alpha1"
+	"github.com/kubeflow/kfctl/v3/pkg/kfconfig"
+	"github.com/kubeflow/kfctl/v3/pkg/kfconfig/awsplugin"
+	"github.com/kubeflow/kfctl/v3/pkg/kfconfig/gcpplugin"
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// JSONSchemaURL is the $schema of the documents returned by Document.
+const JSONSchemaURL = "http://json-schema.org/draft-04/schema#"
+
+const (
+	KfDefKind       = "KfDef"
+	KfUpgradeKind   = "KfUpgrade"
+	ReplicationKind = "Replication"
+)
+
+// pluginKinds maps the plugin kinds with a typed spec to their Go type.
+var pluginKinds = map[kfconfig.PluginKindType]interface{}{
+	kfconfig.GCP_PLUGIN_KIND: gcpplugin.KfGcpPlugin{},
+	kfconfig.AWS_PLUGIN_KIND: awsplugin.KfAwsPlugin{},
+}
+
+// Kinds returns the kinds a schema can be generated for.
+func Kinds() []string {
+	kinds := []string{KfDefKind, KfUpgradeKind, ReplicationKind}
+	for kind := range pluginKinds {
+		kinds = append(kinds, string(kind))
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// ForKind returns the schema of the given kind; see Kinds.
+func ForKind(kind string) (*apiextv1beta1.JSONSchemaProps, error) {
+	var s apiextv1beta1.JSONSchemaProps
+	switch kind {
+	case KfDefKind:
+		s = KfDef()
+	case KfUpgradeKind:
+		s = ForObject(kfupgradev1alpha1.KfUpgrade{})
+		s.Description = "KfUpgrade upgrades a Kubeflow deployment to a new KfDef"
+	case ReplicationKind:
+		s = ForObject(imagemirrorv1alpha1.Replication{})
+		s.Description = "Replication lists the images kfctl alpha mirror copies to another registry"
+	default:
+		obj, ok := pluginKinds[kfconfig.PluginKindType(kind)]
+		if !ok {
+			return nil, kfapis.NewKfError(kfapis.ErrPluginNotFound, "no schema for kind %v; must be one of %v",
+				kind, Kinds())
+		}
+		s = ForObject(obj)
+		s.Description = fmt.Sprintf("%v configures the %v plugin of a KfDef", kind, kind)
+	}
+	return &s, nil
+}
+
+// Document returns the schema of kind as a standalone JSON schema document.
+func Document(kind string) (*apiextv1beta1.JSONSchemaProps, error) {
+	s, err := ForKind(kind)
+	if err != nil {
+		return nil, err
+	}
+	s.Schema = JSONSchemaURL
+	s.Title = kind
+	return s, nil
+}
+
+// KfDef returns the schema of KfDef v1. The spec of every plugin is checked against the
+// schema of its kind; plugins of other kinds may have any spec.
+func KfDef() apiextv1beta1.JSONSchemaProps {
+	s := ForObject(kfdefv1.KfDef{})
+	s.Description = "KfDef is the Schema for the kfdefs API"
+
+	spec := s.Properties["spec"]
+	spec.Description = "KfDefSpec defines the desired state of KfDef"
+	plugins := spec.Properties["plugins"]
+	plugin := *plugins.Items.Schema
+
+	kinds := []string{}
+	for kind := range pluginKinds {
+		kinds = append(kinds, string(kind))
+	}
+	sort.Strings(kinds)
+	others := apiextv1beta1.JSONSchemaProps{
+		Properties: map[string]apiextv1beta1.JSONSchemaProps{
+			"kind": {Not: &apiextv1beta1.JSONSchemaProps{Enum: enum(kinds)}},
+		},
+	}
+	for _, kind := range kinds {
+		typed := ForObject(pluginKinds[kfconfig.PluginKindType(kind)])
+		plugin.AnyOf = append(plugin.AnyOf, apiextv1beta1.JSONSchemaProps{
+			Properties: map[string]apiextv1beta1.JSONSchemaProps{
+				"kind": {Enum: enum([]string{kind})},
+				"spec": typed.Properties["spec"],
+			},
+		})
+	}
+	plugin.AnyOf = append(plugin.AnyOf, others)
+
+	plugins.Items.Schema = &plugin
+	spec.Properties["plugins"] = plugins
+	s.Properties["spec"] = spec
+
+	status := s.Properties["status"]
+	status.Description = "KfDefStatus defines the observed state of KfDef"
+	s.Properties["status"] = status
+	return s
+}
+
+// KfDefCRDValidation returns the validation of the KfDef CustomResourceDefinition in deploy/crds.
+func KfDefCRDValidation() *apiextv1beta1.CustomResourceValidation {
+	s := KfDef()
+	return &apiextv1beta1.CustomResourceValidation{OpenAPIV3Schema: &s}
+}
+
+func enum(values []string) []apiextv1beta1.JSON {
+	e := []apiextv1beta1.JSON{}
+	for _, v := range values {
+		raw, _ := json.Marshal(v)
+		e = append(e, apiextv1beta1.JSON{Raw: raw})
+	}
+	return e
+}
+
+//go:generate go run gen_crd.go ../../../../deploy/crds/kfdef.apps.kubeflow.org_kfdefs_crd.yaml
+
+// UpdateKfDefCRD replaces the validation of the KfDef CustomResourceDefinition in crd,
+// a YAML document, with KfDefCRDValidation and returns the new document.
+func UpdateKfDefCRD(crd []byte) ([]byte, error) {
+	obj := map[string]interface{}{}
+	if err := yaml.Unmarshal(crd, &obj); err != nil {
+		return nil, err
+	}
+	validation, err := runtime.DefaultUnstructuredConverter.ToUnstructured(KfDefCRDValidation())
+	if err != nil {
+		return nil, err
+	}
+	if err := unstructured.SetNestedField(obj, validation, "spec", "validation"); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(obj)
+}
@@ -0,0 +1,145 @@
+// Copyright 2018 The Kubeflow Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schema generates JSON schemas for the kfctl API types.
+package schema
+
+import (
+	"reflect"
+	"strings"
+
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// knownTypes are types whose JSON encoding doesn't follow from their Go definition.
+var knownTypes = map[reflect.Type]apiextv1beta1.JSONSchemaProps{
+	reflect.TypeOf(metav1.Time{}):          {Type: "string", Format: "date-time"},
+	reflect.TypeOf(metav1.ObjectMeta{}):    {Type: "object"},
+	reflect.TypeOf(metav1.ListMeta{}):      {Type: "object"},
+	reflect.TypeOf(runtime.RawExtension{}): {Type: "object"},
+}
+
+// typeMeta describes the fields of an inlined metav1.TypeMeta.
+var typeMeta = map[string]apiextv1beta1.JSONSchemaProps{
+	"apiVersion": {
+		Description: "APIVersion defines the versioned schema of this representation of an object. " +
+			"Servers should convert recognized schemas to the latest internal value, and may reject " +
+			"unrecognized values. More info: " +
+			"https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+		Type: "string",
+	},
+	"kind": {
+		Description: "Kind is a string value representing the REST resource this object represents. " +
+			"Servers may infer this from the endpoint the client submits requests to. Cannot be updated. " +
+			"In CamelCase. More info: " +
+			"https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+		Type: "string",
+	},
+}
+
+// ForObject returns the schema of the JSON encoding of obj, which must be a struct or a pointer to one.
+// Fields are named after their json tags. Nothing is required because kfctl fills in defaults.
+func ForObject(obj interface{}) apiextv1beta1.JSONSchemaProps {
+	return forType(reflect.TypeOf(obj), map[reflect.Type]bool{})
+}
+
+// forType returns the schema of t. visiting holds the structs being described and stops recursion.
+func forType(t reflect.Type, visiting map[reflect.Type]bool) apiextv1beta1.JSONSchemaProps {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if s, ok := knownTypes[t]; ok {
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return apiextv1beta1.JSONSchemaProps{Type: "string"}
+	case reflect.Bool:
+		return apiextv1beta1.JSONSchemaProps{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return apiextv1beta1.JSONSchemaProps{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return apiextv1beta1.JSONSchemaProps{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return apiextv1beta1.JSONSchemaProps{Type: "string", Format: "byte"}
+		}
+		items := forType(t.Elem(), visiting)
+		return apiextv1beta1.JSONSchemaProps{
+			Type:  "array",
+			Items: &apiextv1beta1.JSONSchemaPropsOrArray{Schema: &items},
+		}
+	case reflect.Map:
+		values := forType(t.Elem(), visiting)
+		return apiextv1beta1.JSONSchemaProps{
+			Type:                 "object",
+			AdditionalProperties: &apiextv1beta1.JSONSchemaPropsOrBool{Allows: true, Schema: &values},
+		}
+	case reflect.Struct:
+		if visiting[t] {
+			return apiextv1beta1.JSONSchemaProps{Type: "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		s := apiextv1beta1.JSONSchemaProps{
+			Type:       "object",
+			Properties: map[string]apiextv1beta1.JSONSchemaProps{},
+		}
+		addFields(&s, t, visiting)
+		return s
+	}
+	// Interfaces and anything else can hold any value.
+	return apiextv1beta1.JSONSchemaProps{}
+}
+
+// addFields adds the JSON fields of the struct type t to the properties of s, descending
+// into embedded structs the way encoding/json does.
+func addFields(s *apiextv1beta1.JSONSchemaProps, t reflect.Type, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "" && f.Anonymous {
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft == reflect.TypeOf(metav1.TypeMeta{}) {
+				for k, v := range typeMeta {
+					s.Properties[k] = v
+				}
+				continue
+			}
+			if ft.Kind() == reflect.Struct {
+				addFields(s, ft, visiting)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			// Unexported.
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = forType(f.Type, visiting)
+	}
+}
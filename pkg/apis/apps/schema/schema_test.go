@@ -0,0 +1,114 @@
+package schema
+
+import (
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type testEmbedded struct {
+	Embedded string `json:"embedded,omitempty"`
+}
+
+type testObject struct {
+	metav1.TypeMeta `json:",inline"`
+	testEmbedded
+	Name     string            `json:"name,omitempty"`
+	Count    *int              `json:"count"`
+	Ratio    float64           `json:"ratio,omitempty"`
+	Enabled  *bool             `json:"enabled,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Data     []byte            `json:"data,omitempty"`
+	Created  metav1.Time       `json:"created,omitempty"`
+	Ignored  string            `json:"-"`
+	Untagged string
+	private  string
+}
+
+func TestForObject(t *testing.T) {
+	str := apiextv1beta1.JSONSchemaProps{Type: "string"}
+	expected := apiextv1beta1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextv1beta1.JSONSchemaProps{
+			"apiVersion": typeMeta["apiVersion"],
+			"kind":       typeMeta["kind"],
+			"embedded":   str,
+			"name":       str,
+			"count":      {Type: "integer"},
+			"ratio":      {Type: "number"},
+			"enabled":    {Type: "boolean"},
+			"tags": {
+				Type:  "array",
+				Items: &apiextv1beta1.JSONSchemaPropsOrArray{Schema: &str},
+			},
+			"labels": {
+				Type:                 "object",
+				AdditionalProperties: &apiextv1beta1.JSONSchemaPropsOrBool{Allows: true, Schema: &str},
+			},
+			"data":     {Type: "string", Format: "byte"},
+			"created":  {Type: "string", Format: "date-time"},
+			"Untagged": str,
+		},
+	}
+	actual := ForObject(&testObject{})
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("ForObject:\ngot  %+v\nwant %+v", actual, expected)
+	}
+}
+
+func TestKfDefPlugins(t *testing.T) {
+	s := KfDef()
+	plugin := s.Properties["spec"].Properties["plugins"].Items.Schema
+	if len(plugin.AnyOf) != 3 {
+		t.Fatalf("plugin schema has %v alternatives; want 3", len(plugin.AnyOf))
+	}
+	gcp := plugin.AnyOf[1]
+	if string(gcp.Properties["kind"].Enum[0].Raw) != `"KfGcpPlugin"` {
+		t.Fatalf("second alternative is for kind %s; want KfGcpPlugin", gcp.Properties["kind"].Enum[0].Raw)
+	}
+	secret := gcp.Properties["spec"].Properties["auth"].Properties["iap"].Properties["oAuthClientSecret"]
+	if secret.Properties["name"].Type != "string" {
+		t.Errorf("auth.iap.oAuthClientSecret.name has type %q; want string", secret.Properties["name"].Type)
+	}
+	if plugin.AnyOf[2].Properties["kind"].Not == nil {
+		t.Errorf("last alternative should accept any other kind")
+	}
+}
+
+func TestForKind(t *testing.T) {
+	for _, kind := range Kinds() {
+		s, err := Document(kind)
+		if err != nil {
+			t.Errorf("Document(%v) failed: %v", kind, err)
+			continue
+		}
+		if s.Title != kind || s.Schema != JSONSchemaURL || s.Properties["spec"].Type != "object" {
+			t.Errorf("Document(%v) = %+v; want an object with a spec", kind, s)
+		}
+	}
+
+	if _, err := ForKind("KfNoSuchPlugin"); !errors.Is(err, kfapis.ErrPluginNotFound) {
+		t.Errorf("ForKind of an unknown kind returned %v; want ErrPluginNotFound", err)
+	}
+}
+
+func TestKfDefCRDUpToDate(t *testing.T) {
+	crdFile := "../../../../deploy/crds/kfdef.apps.kubeflow.org_kfdefs_crd.yaml"
+	crd, err := ioutil.ReadFile(crdFile)
+	if err != nil {
+		t.Fatalf("Couldn't read %v: %v", crdFile, err)
+	}
+	updated, err := UpdateKfDefCRD(crd)
+	if err != nil {
+		t.Fatalf("UpdateKfDefCRD failed: %v", err)
+	}
+	if string(updated) != string(crd) {
+		t.Errorf("%v is out of date; run go generate ./pkg/apis/apps/schema", crdFile)
+	}
+}
@@ -15,26 +15,74 @@
 package apis
 
 import (
+	"errors"
 	"fmt"
+
 	log "github.com/sirupsen/logrus"
 )
 
 type StatusCode int
 
 const (
-	OK               StatusCode = 200
-	INVALID_ARGUMENT StatusCode = 400
-	NOT_FOUND        StatusCode = 404
-	INTERNAL_ERROR   StatusCode = 500
-	UNKNOWN          StatusCode = 520
+	OK StatusCode = 200
+	// PARTIAL_FAILURE means some of the resources were processed and others failed,
+	// e.g. a delete that couldn't remove every object.
+	PARTIAL_FAILURE   StatusCode = 207
+	INVALID_ARGUMENT  StatusCode = 400
+	NOT_FOUND         StatusCode = 404
+	INTERNAL_ERROR    StatusCode = 500
+	UNAVAILABLE       StatusCode = 503
+	DEADLINE_EXCEEDED StatusCode = 504
+	UNKNOWN           StatusCode = 520
+)
+
+// Sentinel errors for the kinds of failures callers branch on. Use errors.Is to test for
+// them; the KfErrors returned by kfctl carry their kind and the matching code.
+var (
+	// ErrConfigInvalid means the KfDef or the arguments are invalid.
+	ErrConfigInvalid = errors.New("invalid config")
+	// ErrPluginNotFound means a platform or plugin isn't known or configured.
+	ErrPluginNotFound = errors.New("plugin not found")
+	// ErrClusterMismatch means the KfDef belongs to a cluster other than the current kubeconfig context.
+	ErrClusterMismatch = errors.New("cluster mismatch")
+	// ErrClusterUnreachable means the API server couldn't be reached.
+	ErrClusterUnreachable = errors.New("cluster unreachable")
+	// ErrNotReady means objects didn't become ready in time.
+	ErrNotReady = errors.New("not ready")
+	// ErrPartialFailure means some of the resources were processed and others failed.
+	ErrPartialFailure = errors.New("partial failure")
 )
 
+// kindCodes are the codes of the sentinel errors.
+var kindCodes = map[error]StatusCode{
+	ErrConfigInvalid:      INVALID_ARGUMENT,
+	ErrPluginNotFound:     NOT_FOUND,
+	ErrClusterMismatch:    INVALID_ARGUMENT,
+	ErrClusterUnreachable: UNAVAILABLE,
+	ErrNotReady:           DEADLINE_EXCEEDED,
+	ErrPartialFailure:     PARTIAL_FAILURE,
+}
+
+// codeKinds are the sentinel errors of KfErrors that were created with a code only.
+// INVALID_ARGUMENT isn't mapped to ErrConfigInvalid because many of those errors are
+// transient; only validation and strict loading errors are created with ErrConfigInvalid.
+var codeKinds = map[StatusCode]error{
+	UNAVAILABLE:       ErrClusterUnreachable,
+	DEADLINE_EXCEEDED: ErrNotReady,
+	PARTIAL_FAILURE:   ErrPartialFailure,
+}
+
 // KfError stands for Kubeflow error. This is the standard error interface
 // for Kubeflow components.
 type KfError struct {
 	// Code is the HTTP response status code.
 	Code    int    `json:"code"`
 	Message string `json:"message,omitempty"`
+
+	// kind is the sentinel error this error is an instance of, if any.
+	kind error
+	// cause is the error this error wraps, if any.
+	cause error
 }
 
 func (e *KfError) Error() string {
@@ -42,8 +90,92 @@ func (e *KfError) Error() string {
 		e.Code, e.Message)
 }
 
+// Unwrap returns the error e wraps.
+func (e *KfError) Unwrap() error {
+	return e.cause
+}
+
+// Is returns true if target is the sentinel error e is an instance of. KfErrors created
+// without a kind match the sentinel error of their code, e.g. ErrClusterUnreachable for
+// UNAVAILABLE.
+func (e *KfError) Is(target error) bool {
+	if e.kind != nil {
+		return e.kind == target
+	}
+	kind, ok := codeKinds[StatusCode(e.Code)]
+	return ok && kind == target
+}
+
+// NewKfError returns a KfError of the given kind, one of the sentinel errors.
+// Its code is the code of kind and errors.Is(err, kind) holds.
+func NewKfError(kind error, format string, args ...interface{}) error {
+	return &KfError{
+		Code:    int(codeOf(kind)),
+		Message: fmt.Sprintf(format, args...),
+		kind:    kind,
+	}
+}
+
+// WrapKfError returns a KfError that adds context to err. The code of err is kept
+// (see Code); errors without a code are reported as INTERNAL_ERROR.
+func WrapKfError(err error, format string, args ...interface{}) error {
+	code := codeOf(err)
+	if code == UNKNOWN {
+		code = INTERNAL_ERROR
+	}
+	return &KfError{
+		Code:    int(code),
+		Message: fmt.Sprintf(format, args...) + ": " + message(err),
+		cause:   err,
+	}
+}
+
+// WrapKfErrorAs returns a KfError of the given kind that adds context to err.
+// Both errors.Is(result, kind) and errors.Is(result, err) hold.
+func WrapKfErrorAs(kind error, err error, format string, args ...interface{}) error {
+	return &KfError{
+		Code:    int(codeOf(kind)),
+		Message: fmt.Sprintf(format, args...) + ": " + message(err),
+		kind:    kind,
+		cause:   err,
+	}
+}
+
+// AsKfError returns the first KfError in the chain of err.
+func AsKfError(err error) (*KfError, bool) {
+	var kfErr *KfError
+	ok := errors.As(err, &kfErr)
+	return kfErr, ok
+}
+
+// Code returns the code of the first KfError or sentinel error in the chain of err,
+// or UNKNOWN if there is none.
+func Code(err error) StatusCode {
+	return codeOf(err)
+}
+
+func codeOf(err error) StatusCode {
+	if kfErr, ok := AsKfError(err); ok {
+		return StatusCode(kfErr.Code)
+	}
+	for kind, code := range kindCodes {
+		if errors.Is(err, kind) {
+			return code
+		}
+	}
+	return UNKNOWN
+}
+
+// message returns the message of err without the KfError decoration.
+func message(err error) string {
+	if kfErr, ok := err.(*KfError); ok {
+		return kfErr.Message
+	}
+	return err.Error()
+}
+
 func IsNotFound(e error) bool {
-	kfError, ok := e.(*KfError)
+	kfError, ok := AsKfError(e)
 	return ok && kfError.Code == int(NOT_FOUND)
 }
 
@@ -52,19 +184,21 @@ func IsNotFound(e error) bool {
 // TODO(jlewi): Not sure this is the best way to propogate the error messages and turn them
 // into KfErrors. There was a lot of code that was doing this but not asserting that the error
 // was a KfError which was causing segmentation faults so I wrote this helper method.
+// New code should use WrapKfError, which reports errors without a code as INTERNAL_ERROR.
 func NewKfErrorWithMessage(e error, msg string) error {
-	kErr, ok := e.(*KfError)
-
+	kErr, ok := AsKfError(e)
 	if !ok {
 		log.Infof("Error is not a KfError; %v", e)
 
 		return &KfError{
 			Code:    int(UNKNOWN),
 			Message: msg + "; " + e.Error(),
+			cause:   e,
 		}
 	}
 	return &KfError{
 		Code:    kErr.Code,
-		Message: msg + "; " + kErr.Message,
+		Message: msg + "; " + message(e),
+		cause:   e,
 	}
 }
@@ -0,0 +1,110 @@
+package apis
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestWrapKfError(t *testing.T) {
+	notReady := NewKfError(ErrNotReady, "objects not ready: Deployment/kubeflow/jupyter")
+	wrapped := WrapKfError(notReady, "kfApp Apply failed for %v", "kustomize")
+	kfErr, ok := AsKfError(fmt.Errorf("failed to apply: %w", wrapped))
+	if !ok {
+		t.Fatalf("AsKfError didn't find the KfError in the chain")
+	}
+	if kfErr.Code != int(DEADLINE_EXCEEDED) {
+		t.Errorf("code = %v; want %v", kfErr.Code, DEADLINE_EXCEEDED)
+	}
+	expected := "kfApp Apply failed for kustomize: objects not ready: Deployment/kubeflow/jupyter"
+	if kfErr.Message != expected {
+		t.Errorf("message = %q; want %q", kfErr.Message, expected)
+	}
+	if !errors.Is(wrapped, ErrNotReady) || !errors.Is(wrapped, notReady) {
+		t.Errorf("wrapped error doesn't match its cause")
+	}
+	if errors.Is(wrapped, ErrConfigInvalid) {
+		t.Errorf("wrapped error matches ErrConfigInvalid")
+	}
+
+	if code := Code(WrapKfError(fmt.Errorf("connection reset"), "could not sync cache")); code != INTERNAL_ERROR {
+		t.Errorf("code of a wrapped foreign error = %v; want %v", code, INTERNAL_ERROR)
+	}
+}
+
+func TestKfErrorIs(t *testing.T) {
+	cause := fmt.Errorf("dial tcp 127.0.0.1:6443: connection refused")
+	type testCase struct {
+		err      error
+		kind     error
+		expected bool
+	}
+	tests := []testCase{
+		{
+			err:      NewKfError(ErrClusterMismatch, "cluster name doesn't match"),
+			kind:     ErrClusterMismatch,
+			expected: true,
+		},
+		{
+			err:      NewKfError(ErrClusterMismatch, "cluster name doesn't match"),
+			kind:     ErrConfigInvalid,
+			expected: false,
+		},
+		{
+			err:      &KfError{Code: int(INVALID_ARGUMENT), Message: "couldn't create namespace kubeflow"},
+			kind:     ErrConfigInvalid,
+			expected: false,
+		},
+		{
+			err:      WrapKfError(NewKfError(ErrConfigInvalid, "unknown field spec.repo"), "couldn't load KfDef"),
+			kind:     ErrConfigInvalid,
+			expected: true,
+		},
+		{
+			err:      &KfError{Code: int(INTERNAL_ERROR), Message: "server-side apply failed"},
+			kind:     ErrConfigInvalid,
+			expected: false,
+		},
+		{
+			err:      WrapKfErrorAs(ErrClusterUnreachable, cause, "couldn't get namespace"),
+			kind:     ErrClusterUnreachable,
+			expected: true,
+		},
+		{
+			err:      WrapKfErrorAs(ErrClusterUnreachable, cause, "couldn't get namespace"),
+			kind:     cause,
+			expected: true,
+		},
+	}
+	for _, c := range tests {
+		if actual := errors.Is(c.err, c.kind); actual != c.expected {
+			t.Errorf("errors.Is(%v, %v) = %v; want %v", c.err, c.kind, actual, c.expected)
+		}
+	}
+}
+
+func TestCode(t *testing.T) {
+	type testCase struct {
+		err      error
+		expected StatusCode
+	}
+	tests := []testCase{
+		{
+			err:      fmt.Errorf("loading platform: %w", ErrPluginNotFound),
+			expected: NOT_FOUND,
+		},
+		{
+			err:      NewKfErrorWithMessage(&KfError{Code: int(NOT_FOUND)}, "couldn't load"),
+			expected: NOT_FOUND,
+		},
+		{
+			err:      fmt.Errorf("unknown flag"),
+			expected: UNKNOWN,
+		},
+	}
+	for _, c := range tests {
+		if actual := Code(c.err); actual != c.expected {
+			t.Errorf("Code(%v) = %v; want %v", c.err, actual, c.expected)
+		}
+	}
+}
@@ -2,16 +2,17 @@ package kfdef
 
 import (
 	"context"
+	goerrors "errors"
 	"io/ioutil"
 	"os"
 	"path"
 	"strings"
 
 	"github.com/ghodss/yaml"
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
 	kftypesv3 "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
 	kfdefv1 "github.com/kubeflow/kfctl/v3/pkg/apis/apps/kfdef/v1"
 	"github.com/kubeflow/kfctl/v3/pkg/kfapp/coordinator"
-	kfloaders "github.com/kubeflow/kfctl/v3/pkg/kfconfig/loaders"
 	kfutils "github.com/kubeflow/kfctl/v3/pkg/utils"
 	log "github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -356,6 +357,11 @@ func (r *ReconcileKfDef) Reconcile(request reconcile.Request) (reconcile.Result,
 			b2ndController = true
 		}
 	}
+	// Requeueing can't fix an invalid KfDef; it is reconciled again once it is updated.
+	if goerrors.Is(err, kfapis.ErrConfigInvalid) {
+		log.Errorf("KfDef %v is invalid; not retrying: %v", instance.GetName(), err)
+		return reconcile.Result{}, nil
+	}
 	// If deployment created successfully - don't requeue
 	return reconcile.Result{}, err
 }
@@ -387,66 +393,59 @@ func kfDelete(instance *kfdefv1.KfDef) error {
 }
 
 func kfLoadConfig(instance *kfdefv1.KfDef, action string) (kftypesv3.KfApp, error) {
-	// Define kfApp
-	kfdefBytes, _ := yaml.Marshal(instance)
-
 	// Make the kfApp directory
 	kfAppDir := path.Join("/tmp", instance.GetNamespace(), instance.GetName())
-	if err := os.MkdirAll(kfAppDir, 0755); err != nil {
-		log.Errorf("Failed to create the app directory. Error: %v.", err)
+	configFilePath, err := writeConfig(instance, action, kfAppDir)
+	if err != nil {
 		return nil, err
 	}
 
-	configFilePath := path.Join(kfAppDir, "config.yaml")
-	err := ioutil.WriteFile(configFilePath, kfdefBytes, 0644)
+	kfApp, err := coordinator.NewLoadKfAppFromURI(configFilePath)
 	if err != nil {
-		log.Errorf("Failed to write config.yaml. Error: %v.", err)
+		log.Errorf("failed to build kfApp from URI %v: Error: %v.", configFilePath, err)
+
 		return nil, err
 	}
+	return kfApp, nil
+}
 
+// writeConfig writes instance with the annotations of action to config.yaml in kfAppDir and
+// returns its path. The file is written as is rather than with WriteConfigToFile, which would
+// redact the secrets the KfApp reads back from it.
+func writeConfig(instance *kfdefv1.KfDef, action string, kfAppDir string) (string, error) {
+	instance = instance.DeepCopy()
+	annotations := instance.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
 	if action == "apply" {
 		// Indicate to add annotation to the top level resources
-		setAnnotationAnn := strings.Join([]string{kfutils.KfDefAnnotation, kfutils.SetAnnotation}, "/")
-		setAnnotations(configFilePath, map[string]string{
-			setAnnotationAnn: "true",
-		})
+		annotations[strings.Join([]string{kfutils.KfDefAnnotation, kfutils.SetAnnotation}, "/")] = "true"
 	}
 
 	if action == "delete" {
 		// Enable force delete since inClusterConfig has no ./kube/config file to pass the delete safety check.
-		forceDeleteAnn := strings.Join([]string{kfutils.KfDefAnnotation, kfutils.ForceDelete}, "/")
-		setAnnotations(configFilePath, map[string]string{
-			forceDeleteAnn: "true",
-		})
+		annotations[strings.Join([]string{kfutils.KfDefAnnotation, kfutils.ForceDelete}, "/")] = "true"
 
 		// Indicate the Kubeflow is installed by the operator
-		byOperatorAnn := strings.Join([]string{kfutils.KfDefAnnotation, kfutils.InstallByOperator}, "/")
-		setAnnotations(configFilePath, map[string]string{
-			byOperatorAnn: "true",
-		})
+		annotations[strings.Join([]string{kfutils.KfDefAnnotation, kfutils.InstallByOperator}, "/")] = "true"
 	}
-
-	kfApp, err := coordinator.NewLoadKfAppFromURI(configFilePath)
+	instance.SetAnnotations(annotations)
+	kfdefBytes, err := yaml.Marshal(instance)
 	if err != nil {
-		log.Errorf("failed to build kfApp from URI %v: Error: %v.", configFilePath, err)
-
-		return nil, err
+		log.Errorf("Failed to marshal KfDef. Error: %v.", err)
+		return "", err
 	}
-	return kfApp, nil
-}
 
-func setAnnotations(configPath string, annotations map[string]string) error {
-	config, err := kfloaders.LoadConfigFromURI(configPath)
-	if err != nil {
-		return err
-	}
-	anns := config.GetAnnotations()
-	if anns == nil {
-		anns = map[string]string{}
+	if err := os.MkdirAll(kfAppDir, 0755); err != nil {
+		log.Errorf("Failed to create the app directory. Error: %v.", err)
+		return "", err
 	}
-	for ann, val := range annotations {
-		anns[ann] = val
+
+	configFilePath := path.Join(kfAppDir, "config.yaml")
+	if err := ioutil.WriteFile(configFilePath, kfdefBytes, 0644); err != nil {
+		log.Errorf("Failed to write config.yaml. Error: %v.", err)
+		return "", err
 	}
-	config.SetAnnotations(anns)
-	return kfloaders.WriteConfigToFile(*config)
+	return configFilePath, nil
 }
@@ -0,0 +1,64 @@
+package kfdef
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	kfdefv1 "github.com/kubeflow/kfctl/v3/pkg/apis/apps/kfdef/v1"
+	"github.com/kubeflow/kfctl/v3/pkg/kfconfig"
+	"github.com/kubeflow/kfctl/v3/pkg/kfconfig/awsplugin"
+	kfloaders "github.com/kubeflow/kfctl/v3/pkg/kfconfig/loaders"
+	kfutils "github.com/kubeflow/kfctl/v3/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestWriteConfig_secrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kfctl-operator-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	instance := &kfdefv1.KfDef{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "kfdef.apps.kubeflow.org/v1", Kind: "KfDef"},
+		ObjectMeta: metav1.ObjectMeta{Name: "kubeflow-aws", Namespace: "kubeflow"},
+		Spec: kfdefv1.KfDefSpec{
+			Plugins: []kfdefv1.Plugin{{
+				TypeMeta:   metav1.TypeMeta{Kind: string(kfconfig.AWS_PLUGIN_KIND)},
+				ObjectMeta: metav1.ObjectMeta{Name: "aws"},
+				Spec: &runtime.RawExtension{Raw: []byte(`{"region": "us-west-2", "auth": {` +
+					`"basicAuth": {"username": "admin", "password": "basic-password"},` +
+					`"oidc": {"oAuthClientId": "kubeflow", "oAuthClientSecret": "client-secret"}}}`)},
+			}},
+		},
+	}
+	configFile, err := writeConfig(instance, "apply", dir)
+	if err != nil {
+		t.Fatalf("writeConfig() failed: %v", err)
+	}
+	if len(instance.GetAnnotations()) != 0 {
+		t.Errorf("writeConfig() changed the annotations of the instance to %v", instance.GetAnnotations())
+	}
+
+	// The KfApp loads the config the way kfLoadConfig does.
+	config, err := kfloaders.LoadConfigFromURI(configFile)
+	if err != nil {
+		t.Fatalf("LoadConfigFromURI() failed: %v", err)
+	}
+	if config.GetAnnotations()[strings.Join([]string{kfutils.KfDefAnnotation, kfutils.SetAnnotation}, "/")] != "true" {
+		t.Errorf("Annotations = %v; want %v set", config.GetAnnotations(), kfutils.SetAnnotation)
+	}
+	spec := &awsplugin.AwsPluginSpec{}
+	if err := config.GetPluginSpec(kfconfig.AWS_PLUGIN_KIND, spec); err != nil {
+		t.Fatalf("GetPluginSpec() failed: %v", err)
+	}
+	if err := kfutils.RedactedFieldsError("spec", spec); err != nil {
+		t.Errorf("Operator config has redacted secrets: %v", err)
+	}
+	if spec.Auth.BasicAuth.Password != "basic-password" || spec.Auth.Oidc.OAuthClientSecret != "client-secret" {
+		t.Errorf("Plugin spec auth = %+v, %+v; want the secrets kept", spec.Auth.BasicAuth, spec.Auth.Oidc)
+	}
+}
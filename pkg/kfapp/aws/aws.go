@@ -166,6 +166,16 @@ func (aws *Aws) GetPluginSpec() (*awsplugin.AwsPluginSpec, error) {
 	return awsPluginSpec, err
 }
 
+// checkRedactedSecrets returns an error if the plugin spec was read from a KfDef in which
+// kfctl redacted the secrets. Delete doesn't need them and doesn't check.
+func (aws *Aws) checkRedactedSecrets() error {
+	awsPluginSpec, err := aws.GetPluginSpec()
+	if err != nil {
+		return err
+	}
+	return utils.RedactedFieldsError(string(kfconfig.AWS_PLUGIN_KIND)+" spec", awsPluginSpec)
+}
+
 func (aws *Aws) attachPoliciesToRoles(roles []string) error {
 	awsPluginSpec, err := aws.GetPluginSpec()
 	if err != nil {
@@ -325,11 +335,7 @@ func (aws *Aws) generateInfraConfigs() error {
 		destFile := filepath.Join(destDir, file.Name())
 		copyErr := copyFile(sourceFile, destFile)
 		if copyErr != nil {
-			return &kfapis.KfError{
-				Code: copyErr.(*kfapis.KfError).Code,
-				Message: fmt.Sprintf("Could not copy %v to %v: %v",
-					sourceFile, destFile, copyErr.(*kfapis.KfError).Message),
-			}
+			return kfapis.WrapKfError(copyErr, "Could not copy %v to %v", sourceFile, destFile)
 		}
 	}
 
@@ -430,19 +436,14 @@ func (aws *Aws) Generate(resources kftypes.ResourceEnum) error {
 	}
 
 	if setAwsPluginDefaultsErr := aws.setAwsPluginDefaults(); setAwsPluginDefaultsErr != nil {
-		return &kfapis.KfError{
-			Code: setAwsPluginDefaultsErr.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("set aws plugin defaults: %v",
-				setAwsPluginDefaultsErr.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(setAwsPluginDefaultsErr, "set aws plugin defaults")
+	}
+	if err := aws.checkRedactedSecrets(); err != nil {
+		return err
 	}
 
 	if awsConfigFilesErr := aws.generateInfraConfigs(); awsConfigFilesErr != nil {
-		return &kfapis.KfError{
-			Code: awsConfigFilesErr.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("Could not generate cluster configs under %v Error: %v",
-				KUBEFLOW_AWS_INFRA_DIR, awsConfigFilesErr.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(awsConfigFilesErr, "Could not generate cluster configs under %v", KUBEFLOW_AWS_INFRA_DIR)
 	}
 
 	pluginSpec, err := aws.GetPluginSpec()
@@ -653,20 +654,15 @@ func (aws *Aws) Apply(resources kftypes.ResourceEnum) error {
 	}
 
 	if err := aws.setAwsPluginDefaults(); err != nil {
-		return &kfapis.KfError{
-			Code: err.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("aws set aws plugin defaults: %v",
-				err.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(err, "aws set aws plugin defaults")
+	}
+	if err := aws.checkRedactedSecrets(); err != nil {
+		return err
 	}
 
 	// 1. Create EKS cluster if needed
 	if err := aws.createEKSCluster(); err != nil {
-		return &kfapis.KfError{
-			Code: err.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("Failed to create EKS cluster %v",
-				err.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(err, "Failed to create EKS cluster")
 	}
 
 	// 2. For non-eks cluster (kops) or user doesn't enable pod level IAM policy,
@@ -720,29 +716,17 @@ func (aws *Aws) Apply(resources kftypes.ResourceEnum) error {
 
 	// 3. Attach policies to worker node groups. This will be used by both EKS and non-EKS AWS Kubernetes clusters.
 	if err := aws.attachPoliciesToRoles(aws.roles); err != nil {
-		return &kfapis.KfError{
-			Code: err.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("Failed to attach IAM policies %v",
-				err.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(err, "Failed to attach IAM policies")
 	}
 
 	// 4. Update cluster configs to enable master log or private access config.
 	if err := aws.updateEKSClusterConfig(); err != nil {
-		return &kfapis.KfError{
-			Code: err.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("Failed to update eks cluster configs %v",
-				err.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(err, "Failed to update eks cluster configs")
 	}
 
 	// 5. Setup OIDC create OIDC secret for ALB
 	if err := aws.setupOIDC(); err != nil {
-		return &kfapis.KfError{
-			Code: err.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("Failed to update create OIDC secret for ALB %v",
-				err.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(err, "Failed to update create OIDC secret for ALB")
 	}
 
 	return nil
@@ -759,43 +743,27 @@ func (aws *Aws) Delete(resources kftypes.ResourceEnum) error {
 
 	setAwsPluginDefaultsErr := aws.setAwsPluginDefaults()
 	if setAwsPluginDefaultsErr != nil {
-		return &kfapis.KfError{
-			Code: setAwsPluginDefaultsErr.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("aws set aws plugin defaults: %v",
-				setAwsPluginDefaultsErr.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(setAwsPluginDefaultsErr, "aws set aws plugin defaults")
 	}
 
 	// 1. Delete ingress and istio, cert-manager dependencies
 	if err := aws.uninstallK8sDependencies(); err != nil {
-		return &kfapis.KfError{
-			Code:    err.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("Could not uninstall eks cluster Error: %v", err.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(err, "Could not uninstall eks cluster")
 	}
 
 	// 2. Detach inline policies from worker IAM Roles
 	if err := aws.detachPoliciesFromWorkerRoles(); err != nil {
-		return &kfapis.KfError{
-			Code:    err.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("Could not detach iam role Error: %v", err.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(err, "Could not detach iam role")
 	}
 
 	// 3. Delete WebIdentityIAMRole and OIDC Provider and pre-configured roles
 	if err := aws.deleteWebIdentityRolesAndProvider(); err != nil {
-		return &kfapis.KfError{
-			Code:    err.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("Could not detach iam role Error: %v", err.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(err, "Could not detach iam role")
 	}
 
 	// 4. Delete EKS cluster
 	if err := aws.deleteEKSCluster(); err != nil {
-		return &kfapis.KfError{
-			Code:    err.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("Could not uninstall eks cluster Error: %v", err.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(err, "Could not uninstall eks cluster")
 	}
 
 	return nil
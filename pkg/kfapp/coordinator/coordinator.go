@@ -67,7 +67,7 @@ func getPlatform(kfdef *kfconfig.KfConfig) (kftypesv3.Platform, error) {
 		// TODO(https://github.com/kubeflow/kubeflow/issues/3520) Fix dynamic loading
 		// of platform plugins.
 		log.Infof("** Unrecognized platform %v **", kfdef.Spec.Platform)
-		return nil, fmt.Errorf("Unrecognized platform %v", kfdef.Spec.Platform)
+		return nil, kfapis.NewKfError(kfapis.ErrPluginNotFound, "Unrecognized platform %v", kfdef.Spec.Platform)
 	}
 }
 
@@ -157,13 +157,11 @@ func isDirEmpty(dir string) bool {
 // This is the entrypoint for commands like build or apply.
 // NewLoadKfAppFromURI takes in a config file and constructs the KfApp
 // used by the build and apply semantics for kfctl
-func NewLoadKfAppFromURI(configFile string) (kftypesv3.KfApp, error) {
-	kfdef, err := kfconfigloaders.LoadConfigFromURI(configFile)
+// opts are passed to the loader, e.g. to set annotations that configure the KfApp.
+func NewLoadKfAppFromURI(configFile string, opts ...kfconfigloaders.LoadOption) (kftypesv3.KfApp, error) {
+	kfdef, err := kfconfigloaders.LoadConfigFromURI(configFile, opts...)
 	if err != nil {
-		return nil, &kfapis.KfError{
-			Code:    int(kfapis.INVALID_ARGUMENT),
-			Message: fmt.Sprintf("Error creating KfApp from config file: %v", err),
-		}
+		return nil, kfapis.WrapKfError(err, "Error creating KfApp from config file")
 	}
 
 	isRemoteFile, err := utils.IsRemoteFile(configFile)
@@ -182,10 +180,7 @@ func NewLoadKfAppFromURI(configFile string) (kftypesv3.KfApp, error) {
 		}
 		_, err = CreateKfAppCfgFile(kfdef)
 		if err != nil {
-			return nil, &kfapis.KfError{
-				Code:    int(kfapis.INVALID_ARGUMENT),
-				Message: fmt.Sprintf("Error creating KfApp from config file: %v", err),
-			}
+			return nil, kfapis.WrapKfError(err, "Error creating KfApp from config file")
 		}
 	}
 
@@ -213,7 +208,7 @@ func NewLoadKfAppFromURI(configFile string) (kftypesv3.KfApp, error) {
 	if platform != "" {
 		_platform, _platformErr := getPlatform(c.KfDef)
 		if _platformErr != nil {
-			log.Fatalf("Could not get platform %v: %v **", platform, _platformErr)
+			log.Errorf("Could not get platform %v: %v **", platform, _platformErr)
 			return nil, _platformErr
 		}
 		if _platform != nil {
@@ -222,7 +217,7 @@ func NewLoadKfAppFromURI(configFile string) (kftypesv3.KfApp, error) {
 	}
 	pkg, pkgErr := getPackageManager(c.KfDef)
 	if pkgErr != nil {
-		log.Fatalf("Could not get package manager %v: %v **", kftypesv3.KUSTOMIZE, pkgErr)
+		log.Errorf("Could not get package manager %v: %v **", kftypesv3.KUSTOMIZE, pkgErr)
 		return nil, pkgErr
 	}
 	if pkg != nil {
@@ -231,11 +226,11 @@ func NewLoadKfAppFromURI(configFile string) (kftypesv3.KfApp, error) {
 
 	initErr := c.Init(kftypesv3.ALL)
 	if initErr != nil {
-		return nil, fmt.Errorf("KfApp initiliazation failed: %v", initErr)
+		return nil, kfapis.WrapKfError(initErr, "KfApp initiliazation failed")
 	}
 	generateErr := c.Generate(kftypesv3.ALL)
 	if generateErr != nil {
-		return nil, fmt.Errorf("couldn't generate KfApp: %v", generateErr)
+		return nil, kfapis.WrapKfError(generateErr, "couldn't generate KfApp")
 	}
 
 	return c, nil
@@ -354,11 +349,7 @@ func (kfapp *coordinator) Dump(resources kftypesv3.ResourceEnum) error {
 	for packageManagerName, packageManager := range kfapp.PackageManagers {
 		err := packageManager.Dump(kftypesv3.K8S)
 		if err != nil {
-			return &kfapis.KfError{
-				Code: int(kfapis.INTERNAL_ERROR),
-				Message: fmt.Sprintf("kfApp Apply failed for %v: %v",
-					packageManagerName, err),
-			}
+			return kfapis.WrapKfError(err, "kfApp Dump failed for %v", packageManagerName)
 		}
 	}
 	return nil
@@ -371,18 +362,10 @@ func (kfapp *coordinator) Apply(resources kftypesv3.ResourceEnum) error {
 			if platform != nil {
 				platformErr := platform.Apply(resources)
 				if platformErr != nil {
-					return &kfapis.KfError{
-						Code: int(kfapis.INTERNAL_ERROR),
-						Message: fmt.Sprintf("coordinator Apply failed for %v: %v",
-							kfapp.KfDef.Spec.Platform, platformErr),
-					}
+					return kfapis.WrapKfError(platformErr, "coordinator Apply failed for %v", kfapp.KfDef.Spec.Platform)
 				}
 			} else {
-				return &kfapis.KfError{
-					Code: int(kfapis.INTERNAL_ERROR),
-					Message: fmt.Sprintf("%v not in Platforms",
-						kfapp.KfDef.Spec.Platform),
-				}
+				return kfapis.NewKfError(kfapis.ErrPluginNotFound, "%v not in Platforms", kfapp.KfDef.Spec.Platform)
 			}
 		}
 		return nil
@@ -392,20 +375,16 @@ func (kfapp *coordinator) Apply(resources kftypesv3.ResourceEnum) error {
 		for packageManagerName, packageManager := range kfapp.PackageManagers {
 			packageManagerErr := packageManager.Apply(kftypesv3.K8S)
 			if packageManagerErr != nil {
-				return &kfapis.KfError{
-					Code: int(kfapis.INTERNAL_ERROR),
-					Message: fmt.Sprintf("kfApp Apply failed for %v: %v",
-						packageManagerName, packageManagerErr),
+				// Persist the conditions the package manager recorded, e.g. Degraded after --wait timed out.
+				if updateConfigErr := kfconfigloaders.WriteConfigToFile(*kfapp.KfDef); updateConfigErr != nil {
+					log.Warnf("cannot update config file %v: %v", kftypesv3.KfConfigFile, updateConfigErr)
 				}
+				return kfapis.WrapKfError(packageManagerErr, "kfApp Apply failed for %v", packageManagerName)
 			}
 		}
 		updateConfigErr := kfconfigloaders.WriteConfigToFile(*kfapp.KfDef)
 		if updateConfigErr != nil {
-			return &kfapis.KfError{
-				Code: updateConfigErr.(*kfapis.KfError).Code,
-				Message: fmt.Sprintf("cannot update config file %v: %v", kftypesv3.KfConfigFile,
-					updateConfigErr.(*kfapis.KfError).Message),
-			}
+			return kfapis.WrapKfError(updateConfigErr, "cannot update config file %v", kftypesv3.KfConfigFile)
 		}
 		return nil
 	}
@@ -431,10 +410,7 @@ func (kfapp *coordinator) Apply(resources kftypesv3.ResourceEnum) error {
 	}
 
 	if err := kfapp.KfDef.SyncCache(); err != nil {
-		return &kfapis.KfError{
-			Code:    int(kfapis.INTERNAL_ERROR),
-			Message: fmt.Sprintf("could not sync cache. Error: %v", err),
-		}
+		return kfapis.WrapKfError(err, "could not sync cache")
 	}
 
 	switch resources {
@@ -466,18 +442,10 @@ func (kfapp *coordinator) Delete(resources kftypesv3.ResourceEnum) error {
 			if platform != nil {
 				platformErr := platform.Delete(resources)
 				if platformErr != nil {
-					return &kfapis.KfError{
-						Code: int(kfapis.INTERNAL_ERROR),
-						Message: fmt.Sprintf("coordinator Delete failed for %v: %v",
-							kfapp.KfDef.Spec.Platform, platformErr),
-					}
+					return kfapis.WrapKfError(platformErr, "coordinator Delete failed for %v", kfapp.KfDef.Spec.Platform)
 				}
 			} else {
-				return &kfapis.KfError{
-					Code: int(kfapis.INTERNAL_ERROR),
-					Message: fmt.Sprintf("%v not in Platforms",
-						kfapp.KfDef.Spec.Platform),
-				}
+				return kfapis.NewKfError(kfapis.ErrPluginNotFound, "%v not in Platforms", kfapp.KfDef.Spec.Platform)
 			}
 		}
 		return nil
@@ -487,21 +455,14 @@ func (kfapp *coordinator) Delete(resources kftypesv3.ResourceEnum) error {
 		for packageManagerName, packageManager := range kfapp.PackageManagers {
 			packageManagerErr := packageManager.Delete(kftypesv3.K8S)
 			if packageManagerErr != nil {
-				return &kfapis.KfError{
-					Code: int(kfapis.INTERNAL_ERROR),
-					Message: fmt.Sprintf("kfApp Delete failed for %v: %v",
-						packageManagerName, packageManagerErr),
-				}
+				return kfapis.WrapKfError(packageManagerErr, "kfApp Delete failed for %v", packageManagerName)
 			}
 		}
 		return nil
 	}
 
 	if err := kfapp.KfDef.SyncCache(); err != nil {
-		return &kfapis.KfError{
-			Code:    int(kfapis.INTERNAL_ERROR),
-			Message: fmt.Sprintf("could not sync cache. Error: %v", err),
-		}
+		return kfapis.WrapKfError(err, "could not sync cache")
 	}
 
 	switch resources {
@@ -536,26 +497,14 @@ func (kfapp *coordinator) Generate(resources kftypesv3.ResourceEnum) error {
 			if platform != nil {
 				platformErr := platform.Generate(resources)
 				if platformErr != nil {
-					return &kfapis.KfError{
-						Code: int(kfapis.INTERNAL_ERROR),
-						Message: fmt.Sprintf("coordinator Generate failed for %v: %v",
-							kfapp.KfDef.Spec.Platform, platformErr),
-					}
+					return kfapis.WrapKfError(platformErr, "coordinator Generate failed for %v", kfapp.KfDef.Spec.Platform)
 				}
 				createConfigErr := kfconfigloaders.WriteConfigToFile(*kfapp.KfDef)
 				if createConfigErr != nil {
-					return &kfapis.KfError{
-						Code: createConfigErr.(*kfapis.KfError).Code,
-						Message: fmt.Sprintf("cannot create config file %v: %v", kftypesv3.KfConfigFile,
-							createConfigErr.(*kfapis.KfError).Message),
-					}
+					return kfapis.WrapKfError(createConfigErr, "cannot create config file %v", kftypesv3.KfConfigFile)
 				}
 			} else {
-				return &kfapis.KfError{
-					Code: int(kfapis.INTERNAL_ERROR),
-					Message: fmt.Sprintf("%v not in Platforms",
-						kfapp.KfDef.Spec.Platform),
-				}
+				return kfapis.NewKfError(kfapis.ErrPluginNotFound, "%v not in Platforms", kfapp.KfDef.Spec.Platform)
 			}
 		}
 		return nil
@@ -565,11 +514,7 @@ func (kfapp *coordinator) Generate(resources kftypesv3.ResourceEnum) error {
 		for packageManagerName, packageManager := range kfapp.PackageManagers {
 			packageManagerErr := packageManager.Generate(kftypesv3.K8S)
 			if packageManagerErr != nil {
-				return &kfapis.KfError{
-					Code: int(kfapis.INTERNAL_ERROR),
-					Message: fmt.Sprintf("kfApp Generate failed for %v: %v",
-						packageManagerName, packageManagerErr),
-				}
+				return kfapis.WrapKfError(packageManagerErr, "kfApp Generate failed for %v", packageManagerName)
 			}
 		}
 		return nil
@@ -579,10 +524,7 @@ func (kfapp *coordinator) Generate(resources kftypesv3.ResourceEnum) error {
 	usageReportWarn(kfapp.KfDef.Spec.Applications)
 
 	if err := kfapp.KfDef.SyncCache(); err != nil {
-		return &kfapis.KfError{
-			Code:    int(kfapis.INTERNAL_ERROR),
-			Message: fmt.Sprintf("could not sync cache. Error: %v", err),
-		}
+		return kfapis.WrapKfError(err, "could not sync cache")
 	}
 
 	switch resources {
@@ -606,26 +548,14 @@ func (kfapp *coordinator) Init(resources kftypesv3.ResourceEnum) error {
 			if platform != nil {
 				platformErr := platform.Init(resources)
 				if platformErr != nil {
-					return &kfapis.KfError{
-						Code: int(kfapis.INTERNAL_ERROR),
-						Message: fmt.Sprintf("coordinator Init failed for %v: %v",
-							kfapp.KfDef.Spec.Platform, platformErr),
-					}
+					return kfapis.WrapKfError(platformErr, "coordinator Init failed for %v", kfapp.KfDef.Spec.Platform)
 				}
 				createConfigErr := kfconfigloaders.WriteConfigToFile(*kfapp.KfDef)
 				if createConfigErr != nil {
-					return &kfapis.KfError{
-						Code: createConfigErr.(*kfapis.KfError).Code,
-						Message: fmt.Sprintf("cannot create config file %v: %v", kftypesv3.KfConfigFile,
-							createConfigErr.(*kfapis.KfError).Message),
-					}
+					return kfapis.WrapKfError(createConfigErr, "cannot create config file %v", kftypesv3.KfConfigFile)
 				}
 			} else {
-				return &kfapis.KfError{
-					Code: int(kfapis.INTERNAL_ERROR),
-					Message: fmt.Sprintf("%v not in Platforms",
-						kfapp.KfDef.Spec.Platform),
-				}
+				return kfapis.NewKfError(kfapis.ErrPluginNotFound, "%v not in Platforms", kfapp.KfDef.Spec.Platform)
 			}
 		}
 		return nil
@@ -635,11 +565,7 @@ func (kfapp *coordinator) Init(resources kftypesv3.ResourceEnum) error {
 		for packageManagerName, packageManager := range kfapp.PackageManagers {
 			packageManagerErr := packageManager.Init(kftypesv3.K8S)
 			if packageManagerErr != nil {
-				return &kfapis.KfError{
-					Code: int(kfapis.INTERNAL_ERROR),
-					Message: fmt.Sprintf("kfApp Init failed for %v: %v",
-						packageManagerName, packageManagerErr),
-				}
+				return kfapis.WrapKfError(packageManagerErr, "kfApp Init failed for %v", packageManagerName)
 			}
 		}
 		return nil
@@ -659,6 +585,47 @@ func (kfapp *coordinator) Init(resources kftypesv3.ResourceEnum) error {
 	return nil
 }
 
+// Diff compares the rendered applications of every package manager against the cluster.
+func (kfapp *coordinator) Diff(resources kftypesv3.ResourceEnum) error {
+	for packageManagerName, packageManager := range kfapp.PackageManagers {
+		diff, ok := packageManager.(kftypesv3.KfDiff)
+		if !ok || diff == nil {
+			continue
+		}
+		if diffErr := diff.Diff(kftypesv3.K8S); diffErr != nil {
+			return kfapis.WrapKfError(diffErr, "kfApp Diff failed for %v", packageManagerName)
+		}
+	}
+	return nil
+}
+
+func (kfapp *coordinator) Status(resources kftypesv3.ResourceEnum) ([]kftypesv3.ApplicationStatus, error) {
+	statuses := []kftypesv3.ApplicationStatus{}
+	for packageManagerName, packageManager := range kfapp.PackageManagers {
+		status, ok := packageManager.(kftypesv3.KfStatus)
+		if !ok || status == nil {
+			continue
+		}
+		appStatuses, statusErr := status.Status(kftypesv3.K8S)
+		if statusErr != nil {
+			return nil, kfapis.WrapKfError(statusErr, "kfApp Status failed for %v", packageManagerName)
+		}
+		statuses = append(statuses, appStatuses...)
+	}
+	return statuses, nil
+}
+
+// Results returns the applications and objects the package managers processed.
+func (kfapp *coordinator) Results() []kftypesv3.ApplicationResult {
+	results := []kftypesv3.ApplicationResult{}
+	for _, packageManager := range kfapp.PackageManagers {
+		if r, ok := packageManager.(kftypesv3.KfResults); ok && r != nil {
+			results = append(results, r.Results()...)
+		}
+	}
+	return results
+}
+
 func (kfapp *coordinator) Show(resources kftypesv3.ResourceEnum) error {
 	switch resources {
 	case kftypesv3.K8S:
@@ -672,11 +639,7 @@ func (kfapp *coordinator) Show(resources kftypesv3.ResourceEnum) error {
 			if ok && show != nil {
 				showErr := show.Show(resources)
 				if showErr != nil {
-					return &kfapis.KfError{
-						Code: int(kfapis.INTERNAL_ERROR),
-						Message: fmt.Sprintf("coordinator Show failed for %v: %v",
-							kfapp.KfDef.Spec.Platform, showErr),
-					}
+					return kfapis.WrapKfError(showErr, "coordinator Show failed for %v", kfapp.KfDef.Spec.Platform)
 				}
 			} else {
 				return &kfapis.KfError{
@@ -697,11 +660,7 @@ func (kfapp *coordinator) Show(resources kftypesv3.ResourceEnum) error {
 			if ok && show != nil {
 				showErr := show.Show(kftypesv3.K8S)
 				if showErr != nil {
-					return &kfapis.KfError{
-						Code: int(kfapis.INTERNAL_ERROR),
-						Message: fmt.Sprintf("kfApp Show failed for %v: %v",
-							packageManagerName, showErr),
-					}
+					return kfapis.WrapKfError(showErr, "kfApp Show failed for %v", packageManagerName)
 				}
 			}
 		}
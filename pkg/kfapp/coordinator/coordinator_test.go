@@ -2,12 +2,14 @@ package coordinator
 
 import (
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"os"
 	"path"
 	"reflect"
 	"testing"
 
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
 	kftypesv3 "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
 	"github.com/kubeflow/kfctl/v3/pkg/kfconfig"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -170,6 +172,23 @@ func Test_nameFromAppFile(t *testing.T) {
 	}
 }
 
+func Test_NewLoadKfAppFromURI_invalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kfctl-invalid-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	configFile := path.Join(dir, "app.yaml")
+	kfdef := "apiVersion: kfdef.apps.kubeflow.org/v1\nkind: KfDef\nspec:\n  applicationz: []\n"
+	if err := ioutil.WriteFile(configFile, []byte(kfdef), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewLoadKfAppFromURI(configFile)
+	if !errors.Is(err, kfapis.ErrConfigInvalid) {
+		t.Errorf("NewLoadKfAppFromURI() of an invalid KfDef = %v; want ErrConfigInvalid", err)
+	}
+}
+
 // Pformat returns a pretty format output of any value.
 func Pformat(value interface{}) (string, error) {
 	if s, ok := value.(string); ok {
@@ -791,11 +791,7 @@ func (gcp *Gcp) updateDM(resources kftypesv3.ResourceEnum) error {
 		iamPolicy, iamPolicyErr := utils.ReadIamBindingsYAML(
 			filepath.Join(gcpConfigDir, "iam_bindings.yaml"))
 		if iamPolicyErr != nil {
-			return &kfapis.KfError{
-				Code: iamPolicyErr.(*kfapis.KfError).Code,
-				Message: fmt.Sprintf("Read IAM policy YAML error: %v",
-					iamPolicyErr.(*kfapis.KfError).Message),
-			}
+			return kfapis.WrapKfError(iamPolicyErr, "Read IAM policy YAML error")
 		}
 
 		exp.Reset()
@@ -803,19 +799,11 @@ func (gcp *Gcp) updateDM(resources kftypesv3.ResourceEnum) error {
 			// Need to read policy again as latest Etag changed.
 			newPolicy, policyErr := utils.GetIamPolicy(gcp.kfDef.Spec.Project, gcpClient)
 			if policyErr != nil {
-				return &kfapis.KfError{
-					Code: policyErr.(*kfapis.KfError).Code,
-					Message: fmt.Sprintf("GetIamPolicy error: %v",
-						policyErr.(*kfapis.KfError).Message),
-				}
+				return kfapis.WrapKfError(policyErr, "GetIamPolicy error")
 			}
 			utils.RewriteIamPolicy(newPolicy, iamPolicy)
 			if err := utils.SetIamPolicy(gcp.kfDef.Spec.Project, newPolicy, gcpClient); err != nil {
-				return &kfapis.KfError{
-					Code: err.(*kfapis.KfError).Code,
-					Message: fmt.Sprintf("Set New IamPolicy error: %v",
-						err.(*kfapis.KfError).Message),
-				}
+				return kfapis.WrapKfError(err, "Set New IamPolicy error")
 			}
 			return nil
 		}, exp)
@@ -825,11 +813,7 @@ func (gcp *Gcp) updateDM(resources kftypesv3.ResourceEnum) error {
 	}
 
 	if err := gcp.ConfigK8s(); err != nil {
-		return &kfapis.KfError{
-			Code: err.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("Configure K8s is failed: %v",
-				err.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(err, "Configure K8s is failed")
 	}
 
 	if gcp.runGetCredentials {
@@ -879,29 +863,17 @@ func (gcp *Gcp) Apply(resources kftypesv3.ResourceEnum) error {
 	// Update deployment manager
 	updateDMErr := gcp.updateDM(resources)
 	if updateDMErr != nil {
-		return &kfapis.KfError{
-			Code: updateDMErr.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("gcp apply could not update deployment manager: %v",
-				updateDMErr.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(updateDMErr, "gcp apply could not update deployment manager")
 	}
 	// Insert secrets into the cluster
 	secretsErr := gcp.createSecrets()
 	if secretsErr != nil {
-		return &kfapis.KfError{
-			Code: secretsErr.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("gcp apply could not create secrets: %v",
-				secretsErr.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(secretsErr, "gcp apply could not create secrets")
 	}
 	gcpAdminSa := fmt.Sprintf("%v-admin@%v.iam.gserviceaccount.com", gcp.kfDef.Name, gcp.kfDef.Spec.Project)
 	gcpUserSa := fmt.Sprintf("%v-user@%v.iam.gserviceaccount.com", gcp.kfDef.Name, gcp.kfDef.Spec.Project)
 	if err = gcp.allowAdmineditUserSA(gcpAdminSa, gcpUserSa); err != nil {
-		return &kfapis.KfError{
-			Code: err.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("Fail to setup workload identity:: %v",
-				err.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(err, "Fail to setup workload identity")
 	}
 	// Create the role binding for k8s service account
 	kubeflowWorkloadIdentityMapping := map[string]string{
@@ -909,21 +881,13 @@ func (gcp *Gcp) Apply(resources kftypesv3.ResourceEnum) error {
 		"kf-user":  gcpUserSa,
 	}
 	if err = gcp.setupWorkloadIdentity(gcp.kfDef.Namespace, kubeflowWorkloadIdentityMapping); err != nil {
-		return &kfapis.KfError{
-			Code: err.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("Fail to setup workload identity:: %v",
-				err.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(err, "Fail to setup workload identity")
 	}
 	istioWorkloadIdentityMapping := map[string]string{
 		"kf-admin": gcpAdminSa,
 	}
 	if err = gcp.setupWorkloadIdentity(gcp.getIstioNamespace(), istioWorkloadIdentityMapping); err != nil {
-		return &kfapis.KfError{
-			Code: err.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("Fail to setup workload identity:: %v",
-				err.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(err, "Fail to setup workload identity")
 	}
 
 	return nil
@@ -961,11 +925,7 @@ func deleteDeployment(deploymentmanagerService *deploymentmanager.Service, ctx c
 		action:        "Deleting " + name,
 	}}
 	if err = blockingWait(project, deploymentmanagerService, deleteEntry); err != nil {
-		return &kfapis.KfError{
-			Code: err.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("Gcp.Delete is failed for %v/%v: %v",
-				project, name, err.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(err, "Gcp.Delete is failed for %v/%v", project, name)
 	}
 	return nil
 }
@@ -1093,10 +1053,7 @@ func (gcp *Gcp) Delete(resources kftypesv3.ResourceEnum) error {
 
 	policy, err := utils.GetIamPolicy(project, gcp.client)
 	if err != nil {
-		return &kfapis.KfError{
-			Code:    err.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("Error when getting IAM policy: %v", err.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(err, "Error when getting IAM policy")
 	}
 	saSet := mapset.NewSet(
 		"serviceAccount:"+getSA(gcp.kfDef.Name, "admin", project),
@@ -1114,10 +1071,7 @@ func (gcp *Gcp) Delete(resources kftypesv3.ResourceEnum) error {
 		policy.Bindings[idx].Members = cleanedMembers
 	}
 	if err = utils.SetIamPolicy(project, policy, gcp.client); err != nil {
-		return &kfapis.KfError{
-			Code:    err.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("Error when cleaning IAM policy: %v", err.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(err, "Error when cleaning IAM policy")
 	}
 	if err = gcp.deleteEndpoints(ctx); err != nil {
 		return err
@@ -1401,11 +1355,7 @@ func (gcp *Gcp) generateDMConfigs() error {
 		destFile := filepath.Join(gcpConfigDir, file)
 		copyErr := gcp.copyFile(sourceFile, destFile)
 		if copyErr != nil {
-			return &kfapis.KfError{
-				Code: copyErr.(*kfapis.KfError).Code,
-				Message: fmt.Sprintf("could not copy %v to %v using repo local path %v: %v",
-					sourceFile, destFile, repo.LocalPath, copyErr.(*kfapis.KfError).Message),
-			}
+			return kfapis.WrapKfError(copyErr, "could not copy %v to %v using repo local path %v", sourceFile, destFile, repo.LocalPath)
 		}
 	}
 
@@ -1814,11 +1764,7 @@ func (gcp *Gcp) SetupWorkloadIdentityPermission() error {
 		"profiles-controller-service-account": fmt.Sprintf("%v-admin@%v.iam.gserviceaccount.com", gcp.kfDef.Name, gcp.kfDef.Spec.Project),
 	}
 	if err := gcp.setupWorkloadIdentity(gcp.kfDef.Namespace, kubeflowWorkloadIdentityMapping); err != nil {
-		return &kfapis.KfError{
-			Code: err.(*kfapis.KfError).Code,
-			Message: fmt.Sprintf("Fail to setup workload identity:: %v",
-				err.(*kfapis.KfError).Message),
-		}
+		return kfapis.WrapKfError(err, "Fail to setup workload identity")
 	}
 	return nil
 }
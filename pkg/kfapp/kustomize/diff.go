@@ -0,0 +1,264 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kustomize
+
+import (
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	kfapisv3 "github.com/kubeflow/kfctl/v3/pkg/apis"
+	kftypesv3 "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
+	"github.com/kubeflow/kfctl/v3/pkg/kfconfig"
+	"github.com/kubeflow/kfctl/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DiffAction describes how a rendered object relates to the object in the cluster.
+type DiffAction string
+
+const (
+	DiffAdded     DiffAction = "added"
+	DiffChanged   DiffAction = "changed"
+	DiffUnchanged DiffAction = "unchanged"
+	// DiffRemoved marks objects recorded in the inventory that are no longer rendered.
+	// They are deleted by apply --prune.
+	DiffRemoved DiffAction = "removed"
+)
+
+// ObjectDiff is the difference between a rendered object and its live counterpart.
+type ObjectDiff struct {
+	Key    string     `json:"key"`
+	Action DiffAction `json:"action"`
+	Diff   string     `json:"diff,omitempty"`
+}
+
+// ApplicationDiff holds the object differences for a single application.
+type ApplicationDiff struct {
+	Name    string       `json:"name"`
+	Objects []ObjectDiff `json:"objects,omitempty"`
+}
+
+// Count returns the number of objects with the given action.
+func (d *ApplicationDiff) Count(action DiffAction) int {
+	count := 0
+	for _, o := range d.Objects {
+		if o.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// objectClient initializes a client for reading and writing arbitrary objects.
+func (kustomize *kustomize) objectClient() (*utils.ObjectClient, error) {
+	if err := kustomize.initK8sClients(); err != nil {
+		return nil, err
+	}
+	return utils.NewObjectClient(kustomize.restConfig, utils.KubeConfigNamespace())
+}
+
+// diffObject compares a rendered object against the cluster.
+func diffObject(client *utils.ObjectClient, desired *unstructured.Unstructured) (ObjectDiff, error) {
+	live, err := client.Get(desired)
+	if err != nil {
+		return ObjectDiff{}, err
+	}
+	return diffLiveObject(live, desired)
+}
+
+// diffLiveObject compares a rendered object against live, which is nil if the object doesn't exist.
+func diffLiveObject(live *unstructured.Unstructured, desired *unstructured.Unstructured) (ObjectDiff, error) {
+	key := utils.ObjectKey(desired)
+	desiredYaml, err := yaml.Marshal(desired.Object)
+	if err != nil {
+		return ObjectDiff{}, err
+	}
+	if live == nil {
+		return ObjectDiff{
+			Key:    key,
+			Action: DiffAdded,
+			Diff:   utils.UnifiedDiff("live/"+key, "rendered/"+key, "", string(desiredYaml)),
+		}, nil
+	}
+
+	projected := utils.ProjectLiveObject(utils.StripServerFields(live), desired)
+	liveYaml, err := yaml.Marshal(projected.Object)
+	if err != nil {
+		return ObjectDiff{}, err
+	}
+	diff := utils.UnifiedDiff("live/"+key, "rendered/"+key, string(liveYaml), string(desiredYaml))
+	if diff == "" {
+		return ObjectDiff{Key: key, Action: DiffUnchanged}, nil
+	}
+	return ObjectDiff{Key: key, Action: DiffChanged, Diff: diff}, nil
+}
+
+// diffRemovedObject reports an object that would be pruned. It returns nil if the object is already gone.
+func diffRemovedObject(client *utils.ObjectClient, ref utils.ObjectRef) (*ObjectDiff, error) {
+	live, err := client.Get(ref.Object())
+	if err != nil || live == nil {
+		return nil, err
+	}
+	liveYaml, err := yaml.Marshal(utils.StripServerFields(live).Object)
+	if err != nil {
+		return nil, err
+	}
+	key := utils.ObjectKey(live)
+	return &ObjectDiff{
+		Key:    key,
+		Action: DiffRemoved,
+		Diff:   utils.UnifiedDiff("live/"+key, "rendered/"+key, string(liveYaml), ""),
+	}, nil
+}
+
+// diffApplication renders app and compares each of its objects against the cluster.
+// Objects in previous, the inventory of the last apply, that are no longer rendered are reported as removed.
+func (kustomize *kustomize) diffApplication(client *utils.ObjectClient, app kfconfig.Application,
+	previous []utils.ObjectRef) (*ApplicationDiff, error) {
+	data, err := kustomize.render(app)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := utils.ParseObjects(data)
+	if err != nil {
+		return nil, &kfapisv3.KfError{
+			Code:    int(kfapisv3.INTERNAL_ERROR),
+			Message: fmt.Sprintf("error splitting yaml for %v: %v", app.Name, err),
+		}
+	}
+
+	appDiff := &ApplicationDiff{Name: app.Name}
+	current := []utils.ObjectRef{}
+	for _, obj := range objects {
+		objDiff, err := diffObject(client, obj)
+		if err != nil {
+			return nil, &kfapisv3.KfError{
+				Code:    int(kfapisv3.INTERNAL_ERROR),
+				Message: fmt.Sprintf("error comparing %v of application %v: %v", utils.ObjectKey(obj), app.Name, err),
+			}
+		}
+		appDiff.Objects = append(appDiff.Objects, objDiff)
+		current = append(current, utils.NewObjectRef(obj))
+	}
+	removed, err := kustomize.diffRemoved(client, app.Name, previous, current)
+	if err != nil {
+		return nil, err
+	}
+	appDiff.Objects = append(appDiff.Objects, removed...)
+	return appDiff, nil
+}
+
+// diffRemoved reports the objects of previous that are not in current.
+func (kustomize *kustomize) diffRemoved(client *utils.ObjectClient, app string, previous []utils.ObjectRef,
+	current []utils.ObjectRef) ([]ObjectDiff, error) {
+	removed := []ObjectDiff{}
+	for _, ref := range kustomize.obsoleteObjects(previous, current) {
+		objDiff, err := diffRemovedObject(client, ref)
+		if err != nil {
+			return nil, &kfapisv3.KfError{
+				Code:    int(kfapisv3.INTERNAL_ERROR),
+				Message: fmt.Sprintf("error comparing %v of application %v: %v", ref.Key(), app, err),
+			}
+		}
+		if objDiff != nil {
+			removed = append(removed, *objDiff)
+		}
+	}
+	return removed, nil
+}
+
+// loadInventory returns the objects recorded by the last apply keyed by application.
+func (kustomize *kustomize) loadInventory() (map[string][]utils.ObjectRef, error) {
+	clientset, err := kubernetes.NewForConfig(kustomize.restConfig)
+	if err != nil {
+		return nil, &kfapisv3.KfError{
+			Code:    int(kfapisv3.INTERNAL_ERROR),
+			Message: fmt.Sprintf("could not get clientset: %v", err),
+		}
+	}
+	return utils.NewInventory(clientset, kustomize.kfDef.ObjectMeta.Namespace, kustomize.kfDef.Name).Load()
+}
+
+// printApplicationDiff writes the summary line and the diffs of the changed objects of appDiff.
+func (kustomize *kustomize) printApplicationDiff(appDiff *ApplicationDiff) {
+	fmt.Fprintf(kustomize.out, "=== Application %v: %v added, %v changed, %v unchanged, %v removed\n", appDiff.Name,
+		appDiff.Count(DiffAdded), appDiff.Count(DiffChanged), appDiff.Count(DiffUnchanged), appDiff.Count(DiffRemoved))
+	for _, o := range appDiff.Objects {
+		if o.Action == DiffUnchanged {
+			continue
+		}
+		fmt.Fprint(kustomize.out, o.Diff)
+	}
+}
+
+// Diff prints the difference between the rendered applications and the live cluster to stdout.
+func (kustomize *kustomize) Diff(resources kftypesv3.ResourceEnum) error {
+	client, err := kustomize.objectClient()
+	if err != nil {
+		return err
+	}
+	previous, err := kustomize.loadInventory()
+	if err != nil {
+		return err
+	}
+	selection, err := kustomize.kfDef.ApplicationSelection()
+	if err != nil {
+		return err
+	}
+
+	applications := make(map[string]bool)
+	for _, app := range kustomize.kfDef.Spec.Applications {
+		if applications[app.Name] == true {
+			continue
+		}
+		applications[app.Name] = true
+		if !selection.Selects(app.Name) {
+			continue
+		}
+		// Apply prunes the objects of disabled applications, like removed ones.
+		if !app.IsEnabled() {
+			removed, err := kustomize.diffRemoved(client, app.Name, previous[app.Name], nil)
+			if err != nil {
+				return err
+			}
+			kustomize.printApplicationDiff(&ApplicationDiff{Name: app.Name, Objects: removed})
+			continue
+		}
+
+		log.Infof("Comparing application %v", app.Name)
+		appDiff, err := kustomize.diffApplication(client, app, previous[app.Name])
+		if err != nil {
+			return err
+		}
+		kustomize.printApplicationDiff(appDiff)
+	}
+
+	// Applications removed from the KfDef don't render any objects anymore.
+	for _, name := range utils.Applications(previous) {
+		if applications[name] || selection.IsSubset() {
+			continue
+		}
+		removed, err := kustomize.diffRemoved(client, name, previous[name], nil)
+		if err != nil {
+			return err
+		}
+		kustomize.printApplicationDiff(&ApplicationDiff{Name: name, Objects: removed})
+	}
+	return nil
+}
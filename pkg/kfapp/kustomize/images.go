@@ -0,0 +1,84 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kustomize
+
+import (
+	"fmt"
+	"sort"
+
+	kfapisv3 "github.com/kubeflow/kfctl/v3/pkg/apis"
+	"github.com/kubeflow/kfctl/v3/pkg/utils"
+)
+
+// containerFields are the fields of pod specs holding containers.
+var containerFields = []string{"containers", "initContainers", "ephemeralContainers"}
+
+// Images returns the container images of the rendered applications, sorted and without
+// duplicates.
+func (kustomize *kustomize) Images() ([]string, error) {
+	ordered, err := kustomize.kfDef.ApplicationOrder()
+	if err != nil {
+		return nil, err
+	}
+	images := map[string]bool{}
+	for _, app := range ordered {
+		data, err := kustomize.render(app)
+		if err != nil {
+			return nil, err
+		}
+		objects, err := utils.ParseObjects(data)
+		if err != nil {
+			return nil, &kfapisv3.KfError{
+				Code:    int(kfapisv3.INTERNAL_ERROR),
+				Message: fmt.Sprintf("error splitting yaml for %v: %v", app.Name, err),
+			}
+		}
+		for _, obj := range objects {
+			collectImages(obj.Object, images)
+		}
+	}
+	sorted := []string{}
+	for image := range images {
+		sorted = append(sorted, image)
+	}
+	sort.Strings(sorted)
+	return sorted, nil
+}
+
+// collectImages adds the images of all containers in v to images. Pod specs are found
+// anywhere in v so that pod templates of workloads and custom resources are covered too.
+func collectImages(v interface{}, images map[string]bool) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for _, field := range containerFields {
+			containers, _ := value[field].([]interface{})
+			for _, c := range containers {
+				container, _ := c.(map[string]interface{})
+				if image, ok := container["image"].(string); ok && image != "" {
+					images[image] = true
+				}
+			}
+		}
+		for _, child := range value {
+			collectImages(child, images)
+		}
+	case []interface{}:
+		for _, child := range value {
+			collectImages(child, images)
+		}
+	}
+}
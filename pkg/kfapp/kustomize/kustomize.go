@@ -28,6 +28,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	errutil "k8s.io/apimachinery/pkg/util/errors"
@@ -105,8 +106,10 @@ type kustomize struct {
 	componentMap     map[string]bool
 	packageMap       map[string]*[]string
 	restConfig       *rest.Config
-	// when set to true, apply() will skip local kube config, directly build config from restConfig
-	configOverwrite bool
+	// mu guards kfDef and results while applications are applied in parallel.
+	mu sync.Mutex
+	// results records the applications and objects processed by the last command.
+	results []kftypesv3.ApplicationResult
 }
 
 const (
@@ -160,6 +163,7 @@ func (kustomize *kustomize) render(app kfconfig.Application) ([]byte, error) {
 	}
 
 	sortResourceByKind(resMap, utils.InstallOrder)
+	addLabels(resMap, app.ObjectLabels())
 
 	// check to set owner references for resources if installed through kubeflow operator
 	annotations := kustomize.kfDef.GetAnnotations()
@@ -211,6 +215,10 @@ func (kustomize *kustomize) render(app kfconfig.Application) ([]byte, error) {
 
 // Dump prints the kustomize generated resources to stdout
 func (kustomize *kustomize) Dump(resources kftypesv3.ResourceEnum) error {
+	selection, err := kustomize.kfDef.ApplicationSelection()
+	if err != nil {
+		return err
+	}
 
 	applications := make(map[string]bool)
 	for _, app := range kustomize.kfDef.Spec.Applications {
@@ -219,24 +227,102 @@ func (kustomize *kustomize) Dump(resources kftypesv3.ResourceEnum) error {
 			continue
 		}
 		applications[app.Name] = true
+		if !app.IsEnabled() || !selection.Selects(app.Name) {
+			continue
+		}
 
 		data, err := kustomize.render(app)
 		if err != nil {
 			return err
 		}
-		fmt.Println(string(data))
+		fmt.Println(utils.RedactString(string(data)))
 		fmt.Println("---")
 	}
 	return nil
 }
 
+// getAnnotation returns the value of the kfctl annotation with the given name.
+func (kustomize *kustomize) getAnnotation(name string) string {
+	return kustomize.kfDef.GetAnnotations()[strings.Join([]string{utils.KfDefAnnotation, name}, "/")]
+}
+
+// getBoolAnnotation returns the boolean value of the kfctl annotation with the given name.
+// It returns false if the annotation isn't set or can't be parsed.
+func (kustomize *kustomize) getBoolAnnotation(name string) bool {
+	if b, err := strconv.ParseBool(kustomize.getAnnotation(name)); err == nil {
+		return b
+	}
+	return false
+}
+
+// applyApplication renders and applies a single application, records its inventory and,
+// if wait is set, waits for its workloads to become ready.
+func (kustomize *kustomize) applyApplication(apply *utils.ServerSideApply, inventory *utils.Inventory,
+	app kfconfig.Application, previous []utils.ObjectRef, wait bool) error {
+	logger := apply.Logger()
+	logger.Infof("Deploying application %v", app.Name)
+	data, err := kustomize.render(app)
+	if err != nil {
+		return err
+	}
+
+	// TODO(https://github.com/kubeflow/manifests/issues/806): Bump the timeout because cert-manager takes
+	// a long time to start. Any application that needs to create a certificate will fail because it won't
+	// be able to create certificates if cert-manager is unavailable.
+	// Applications that declare dependsOn cert-manager wait for it to be ready instead. Conflicts and
+	// objects the API server rejects fail right away.
+	var results utils.ApplyResults
+	b := utils.NewDefaultBackoff()
+	b.MaxElapsedTime = 10 * time.Minute
+	err = backoff.RetryNotify(
+		func() error {
+			results, err = apply.Apply(data)
+			if err != nil && results.Permanent() {
+				return backoff.Permanent(err)
+			}
+			return err
+		},
+		b,
+		func(e error, duration time.Duration) {
+			logger.Warnf("Encountered error applying application %v: %v", app.Name, e)
+			logger.Warnf("Will retry in %.0f seconds.", duration.Seconds())
+		})
+	kustomize.recordObjects(app.Name, results.ObjectResults()...)
+	if err != nil {
+		logger.Errorf("Permanently failed applying application %v: %v", app.Name, err)
+		return err
+	}
+	logger.Infof("Successfully applied application %v: %v", app.Name, results.Summary())
+	if err := kustomize.updateInventory(apply, inventory, app.Name, previous, results.Refs()); err != nil {
+		return err
+	}
+	// Dependents are only applied once their dependencies are ready.
+	if wait {
+		return kustomize.waitForApplication(apply, app.Name, results.Refs())
+	}
+	return nil
+}
+
 // Apply deploys kustomize generated resources to the kubenetes api server
 func (kustomize *kustomize) Apply(resources kftypesv3.ResourceEnum) error {
-	var restConfig *rest.Config = nil
-	if kustomize.configOverwrite && kustomize.restConfig != nil {
-		restConfig = kustomize.restConfig
+	ordered, err := kustomize.kfDef.ApplicationOrder()
+	if err != nil {
+		return err
 	}
-	apply, err := utils.NewApply(kustomize.kfDef.ObjectMeta.Namespace, restConfig)
+	dependencies := kustomize.kfDef.Dependencies()
+	selection, err := kustomize.kfDef.ApplicationSelection()
+	if err != nil {
+		return err
+	}
+
+	apply, err := utils.NewServerSideApply(kustomize.kfDef.ObjectMeta.Namespace, kustomize.restConfig)
+	if err != nil {
+		return err
+	}
+	apply.Force = kustomize.getBoolAnnotation(utils.ForceConflicts)
+	wait := kustomize.getBoolAnnotation(utils.Wait)
+	inventory := apply.Inventory(kustomize.kfDef.ObjectMeta.Namespace, kustomize.kfDef.Name)
+	previous, err := inventory.Load()
 	if err != nil {
 		return err
 	}
@@ -255,40 +341,43 @@ func (kustomize *kustomize) Apply(resources kftypesv3.ResourceEnum) error {
 		}
 	}
 
+	// Applications are applied after the applications they depend on.
 	applications := make(map[string]bool)
-	for _, app := range kustomize.kfDef.Spec.Applications {
-		if applications[app.Name] == true {
-			// if the application name already
-			continue
-		}
+	for _, app := range ordered {
 		applications[app.Name] = true
-
-		log.Infof("Deploying application %v", app.Name)
-		data, err := kustomize.render(app)
-		if err != nil {
-			return err
+	}
+	err = kustomize.applyApplications(ordered, kustomize.parallelism(), func(app kfconfig.Application) error {
+		// Applications left out with --app or --skip-app keep what they have in the cluster.
+		if !selection.Selects(app.Name) {
+			return nil
 		}
+		// Disabled applications don't render any objects, like removed ones.
+		if !app.IsEnabled() {
+			log.Infof("Skipping disabled application %v", app.Name)
+			return kustomize.updateInventory(apply.ForApplication(app.Name), inventory, app.Name, previous[app.Name], nil)
+		}
+		return kustomize.applyApplication(apply.ForApplication(app.Name), inventory, app, previous[app.Name],
+			wait || dependencies[app.Name])
+	})
+	if err != nil {
+		return err
+	}
 
-		// TODO(https://github.com/kubeflow/manifests/issues/806): Bump the timeout because cert-manager takes
-		// a long time to start. Any application that needs to create a certificate will fail because it won't
-		// be able to create certificates if cert-manager is unavailable. We should try to identify Permanent Errors
-		// and return a PermanentError to avoid retrying and taking 10 minutes to fail.
-		b := utils.NewDefaultBackoff()
-		b.MaxElapsedTime = 10 * time.Minute
-		err = backoff.RetryNotify(
-			func() error {
-				return apply.Apply(data)
-			},
-			b,
-			func(e error, duration time.Duration) {
-				log.Warnf("Encountered error applying application %v: %v", app.Name, e)
-				log.Warnf("Will retry in %.0f seconds.", duration.Seconds())
-			})
-		if err != nil {
-			log.Errorf("Permanently failed applying application %v: %v", app.Name, err)
+	// Applications removed from the KfDef don't render any objects anymore.
+	for _, name := range utils.Applications(previous) {
+		if applications[name] || selection.IsSubset() {
+			continue
+		}
+		if err := kustomize.updateInventory(apply.ForApplication(name), inventory, name, previous[name], nil); err != nil {
 			return err
 		}
-		log.Infof("Successfully applied application %v", app.Name)
+	}
+	if wait && !selection.IsSubset() {
+		kustomize.setAvailable()
+	}
+	if selection.IsSubset() {
+		// The default user namespace is created by an application that may have been left out.
+		return nil
 	}
 
 	// Default user namespace when multi-tenancy enabled
@@ -300,10 +389,7 @@ func (kustomize *kustomize) Apply(resources kftypesv3.ResourceEnum) error {
 		if !(apply.IfNamespaceExist(defaultProfileNamespace) || apply.IfNamespaceExist(anonymousNamespace)) {
 			msg := "Default user namespace pending creation..."
 			log.Warnf(msg)
-			return &kfapisv3.KfError{
-				Code:    int(kfapisv3.INVALID_ARGUMENT),
-				Message: msg,
-			}
+			return kfapisv3.NewKfError(kfapisv3.ErrNotReady, msg)
 		}
 		return nil
 	}, b)
@@ -317,7 +403,7 @@ func (kustomize *kustomize) Apply(resources kftypesv3.ResourceEnum) error {
 func (kustomize *kustomize) deleteGlobalResources() error {
 	if err := kustomize.initK8sClients(); err != nil {
 		return &kfapisv3.KfError{
-			Code:    int(kfapisv3.INVALID_ARGUMENT),
+			Code:    int(kfapisv3.INTERNAL_ERROR),
 			Message: fmt.Sprintf("kustomize plugin couldn't initialize a K8s client: %v", err),
 		}
 	}
@@ -334,10 +420,7 @@ func (kustomize *kustomize) deleteGlobalResources() error {
 	}
 	crdsErr := apiextclientset.CustomResourceDefinitions().DeleteCollection(do, lo)
 	if crdsErr != nil {
-		return &kfapisv3.KfError{
-			Code:    int(kfapisv3.INVALID_ARGUMENT),
-			Message: fmt.Sprintf("couldn't delete customresourcedefinitions: %v", crdsErr),
-		}
+		return utils.ClusterError(crdsErr, kfapisv3.INTERNAL_ERROR, "couldn't delete customresourcedefinitions")
 	}
 	rbacclient, err := rbacv1.NewForConfig(kustomize.restConfig)
 	if err != nil {
@@ -348,17 +431,11 @@ func (kustomize *kustomize) deleteGlobalResources() error {
 	}
 	crbsErr := rbacclient.ClusterRoleBindings().DeleteCollection(do, lo)
 	if crbsErr != nil {
-		return &kfapisv3.KfError{
-			Code:    int(kfapisv3.INVALID_ARGUMENT),
-			Message: fmt.Sprintf("couldn't delete clusterrolebindings: %v", crbsErr),
-		}
+		return utils.ClusterError(crbsErr, kfapisv3.INTERNAL_ERROR, "couldn't delete clusterrolebindings")
 	}
 	crsErr := rbacclient.ClusterRoles().DeleteCollection(do, lo)
 	if crsErr != nil {
-		return &kfapisv3.KfError{
-			Code:    int(kfapisv3.INVALID_ARGUMENT),
-			Message: fmt.Sprintf("couldn't delete clusterroles: %v", crsErr),
-		}
+		return utils.ClusterError(crsErr, kfapisv3.INTERNAL_ERROR, "couldn't delete clusterroles")
 	}
 	return nil
 }
@@ -386,6 +463,7 @@ func (kustomize *kustomize) Delete(resources kftypesv3.ResourceEnum) error {
 
 	// Get kubeconfig for cluster and initialize clients
 	msg := ""
+	msgKind := kfapisv3.ErrConfigInvalid
 	kubeconfig := kftypesv3.GetKubeConfig()
 	if kubeconfig == nil {
 		msg = "unable to load .kubeconfig."
@@ -397,6 +475,7 @@ func (kustomize *kustomize) Delete(resources kftypesv3.ResourceEnum) error {
 			if kustomize.kfDef.ClusterName != ctx.Cluster {
 				msg = fmt.Sprintf("cluster name doesn't match: KfDef(%v) v.s. current-context(%v)",
 					kustomize.kfDef.ClusterName, ctx.Cluster)
+				msgKind = kfapisv3.ErrClusterMismatch
 			}
 		}
 	}
@@ -404,26 +483,31 @@ func (kustomize *kustomize) Delete(resources kftypesv3.ResourceEnum) error {
 		if forceDelete {
 			log.Warnf(msg)
 		} else {
-			return &kfapisv3.KfError{
-				Code:    int(kfapisv3.INVALID_ARGUMENT),
-				Message: msg,
-			}
+			return kfapisv3.NewKfError(msgKind, msg)
 		}
 	}
 	kustomize.initK8sClients()
 	kubeclient, err := client.New(kustomize.restConfig, client.Options{})
 	if err != nil {
-		return &kfapisv3.KfError{
-			Code:    int(kfapisv3.INTERNAL_ERROR),
-			Message: fmt.Sprintf("error initializing k8s client: %v", err),
-		}
+		return utils.ClusterError(err, kfapisv3.INTERNAL_ERROR, "error initializing k8s client")
 	}
 
-	// Delete in reverse application order
+	// Delete in reverse dependency order
+	ordered, err := kustomize.kfDef.ApplicationOrder()
+	if err != nil {
+		return err
+	}
+	selection, err := kustomize.kfDef.ApplicationSelection()
+	if err != nil {
+		return err
+	}
 	kustomizeDir := path.Join(kustomize.kfDef.Spec.AppDir, outputDir)
 	errList := []error{}
-	for idx := range kustomize.kfDef.Spec.Applications {
-		app := &kustomize.kfDef.Spec.Applications[len(kustomize.kfDef.Spec.Applications)-1-idx]
+	for idx := range ordered {
+		app := &ordered[len(ordered)-1-idx]
+		if !app.IsEnabled() || !selection.Selects(app.Name) {
+			continue
+		}
 		log.Infof("Deleting application %v", app.Name)
 		resMap, err := EvaluateKustomizeManifest(path.Join(kustomizeDir, app.Name))
 		if err != nil {
@@ -451,26 +535,41 @@ func (kustomize *kustomize) Delete(resources kftypesv3.ResourceEnum) error {
 				Message: fmt.Sprintf("error splitting yaml: %v", err),
 			}
 		}
+		kustomize.recordObjects(app.Name)
 		for _, r := range resources {
+			result := kftypesv3.ObjectResult{Action: "deleted"}
+			if objects, err := utils.ParseObjects(r); err == nil && len(objects) == 1 {
+				result.Key = utils.ObjectKey(objects[0])
+				// Namespaces hold the objects of the applications that are not deleted too.
+				if selection.IsSubset() && objects[0].GetKind() == "Namespace" {
+					log.Infof("Keeping %v: only some applications are deleted", result.Key)
+					continue
+				}
+			}
 			err := utils.DeleteResource(r, kubeclient, 5*time.Minute, byOperator)
 			if err != nil {
 				msg := fmt.Sprintf("error evaluating kustomization manifest for %v: %v", app.Name, err)
 				errList = append(errList, errors.New(msg))
 				log.Warn(msg)
+				result.Action = "failed"
+				result.Message = err.Error()
+			}
+			if result.Key != "" {
+				kustomize.recordObjects(app.Name, result)
 			}
 		}
 	}
 
 	aggrError := errutil.NewAggregate(errList)
 	if aggrError != nil {
-		return &kfapisv3.KfError{
-			Code:    int(kfapisv3.INTERNAL_ERROR),
-			Message: fmt.Sprintf("error deleting kustomize manifests: %v", aggrError),
-		}
+		return kfapisv3.NewKfError(kfapisv3.ErrPartialFailure, "error deleting kustomize manifests: %v", aggrError)
 	}
 
 	// Finally, delete the kubeflow namespace
 	// TODO(yanniszark): Remove this once the Kubeflow namespace is created by kustomize manifests
+	if selection.IsSubset() {
+		return nil
+	}
 
 	corev1client, err := corev1.NewForConfig(kustomize.restConfig)
 	if err != nil {
@@ -524,16 +623,36 @@ func sortResourceByKind(resMap resmap.ResMap, order utils.SortOrder) {
 // One yaml file per component
 func (kustomize *kustomize) Generate(resources kftypesv3.ResourceEnum) error {
 	generate := func() error {
+		selection, err := kustomize.kfDef.ApplicationSelection()
+		if err != nil {
+			return err
+		}
 		kustomizeDir := path.Join(kustomize.kfDef.Spec.AppDir, outputDir)
 
+		// existing is set for the legacy code path if the directory already exists; then only
+		// applications without a directory, e.g. left out by an earlier --app or --skip-app, are generated.
+		existing := false
 		if _, err := os.Stat(kustomizeDir); err == nil {
 			// When using the new stacks code the directory might already exist because it could have
 			// been created by calls to SetApplicationParameter. For the legacy code path (no stacks) we preserve
-			// the existing code path of not rerunning generate if the directory already exists.
+			// the existing code path of not rerunning generate for applications that already have a directory.
 			if !kustomize.kfDef.UsingStacks() {
-				// Noop if the directory already exists.
-				log.Infof("Folder %v exists, skip kustomize.Generate", kustomizeDir)
-				return nil
+				existing = true
+				missing := false
+				for _, app := range kustomize.kfDef.Spec.Applications {
+					if !app.IsEnabled() || !selection.Selects(app.Name) {
+						continue
+					}
+					if _, err := os.Stat(path.Join(kustomizeDir, app.Name)); err != nil {
+						missing = true
+						break
+					}
+				}
+				if !missing {
+					// Noop if the directory already exists.
+					log.Infof("Folder %v exists, skip kustomize.Generate", kustomizeDir)
+					return nil
+				}
 			}
 		} else if !os.IsNotExist(err) {
 			log.Errorf("Stat folder %v error: %v; try deleting it...", kustomizeDir, err)
@@ -566,7 +685,22 @@ func (kustomize *kustomize) Generate(resources kftypesv3.ResourceEnum) error {
 		// determine whether we are using the new pattern of using kustomize to build stacks.
 		// hasStack := kustomize.kfDef.UsingStacks()
 		for _, app := range kustomize.kfDef.Spec.Applications {
+			if !app.IsEnabled() {
+				log.Infof("Skipping disabled application: %v", app.Name)
+				continue
+			}
+			if !selection.Selects(app.Name) {
+				log.Infof("Skipping application: %v", app.Name)
+				continue
+			}
+			if existing {
+				if _, err := os.Stat(path.Join(kustomizeDir, app.Name)); err == nil {
+					log.Infof("Folder %v exists, skip generating application %v", path.Join(kustomizeDir, app.Name), app.Name)
+					continue
+				}
+			}
 			log.Infof("Processing application: %v", app.Name)
+			kustomize.recordObjects(app.Name)
 
 			if app.KustomizeConfig == nil {
 				err := fmt.Errorf("application %v is missing KustomizeConfig", app.Name)
@@ -633,6 +767,11 @@ func (kustomize *kustomize) Generate(resources kftypesv3.ResourceEnum) error {
 					}
 				}
 			}
+			if app.Namespace != "" {
+				if err := setKustomizationNamespace(path.Join(kustomizeDir, app.Name), app.Namespace); err != nil {
+					return err
+				}
+			}
 		}
 		return nil
 	}
@@ -724,6 +863,52 @@ func createStackAppKustomization(stackAppDir string, basePath string) (string, e
 	return kustomizationFile, nil
 }
 
+// setKustomizationNamespace sets the namespace of the kustomization in appDir, so that the
+// namespaced objects of the application are installed in namespace.
+func setKustomizationNamespace(appDir string, namespace string) error {
+	kustomization := GetKustomization(appDir)
+	if kustomization == nil {
+		return &kfapisv3.KfError{
+			Code:    int(kfapisv3.INTERNAL_ERROR),
+			Message: fmt.Sprintf("couldn't read kustomization in %v", appDir),
+		}
+	}
+	kustomization.Namespace = namespace
+	buf, err := yaml.Marshal(kustomization)
+	if err != nil {
+		return &kfapisv3.KfError{
+			Code:    int(kfapisv3.INTERNAL_ERROR),
+			Message: fmt.Sprintf("couldn't marshal kustomization in %v: %v", appDir, err),
+		}
+	}
+	kustomizationFile := filepath.Join(appDir, kftypesv3.KustomizationFile)
+	if err := ioutil.WriteFile(kustomizationFile, buf, 0644); err != nil {
+		return &kfapisv3.KfError{
+			Code:    int(kfapisv3.INTERNAL_ERROR),
+			Message: fmt.Sprintf("error writing to %v: %v", kustomizationFile, err),
+		}
+	}
+	return nil
+}
+
+// addLabels adds labels to the metadata of every resource. Unlike commonLabels in a
+// kustomization they aren't added to selectors, which can't be changed once created.
+func addLabels(resMap resmap.ResMap, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	for _, res := range resMap.Resources() {
+		merged := res.GetLabels()
+		if merged == nil {
+			merged = map[string]string{}
+		}
+		for k, v := range labels {
+			merged[k] = v
+		}
+		res.SetLabels(merged)
+	}
+}
+
 // Init is called from 'kfctl init ...' and creates a <deployment> directory with an app.yaml file that
 // holds deployment information like components, parameters
 func (kustomize *kustomize) Init(resources kftypesv3.ResourceEnum) error {
@@ -777,7 +962,6 @@ func (kustomize *kustomize) mapDirs(dirPath string, root bool, depth int, leafMa
 
 func (kustomize *kustomize) SetK8sRestConfig(r *rest.Config) {
 	kustomize.restConfig = r
-	kustomize.configOverwrite = true
 }
 
 // GetKustomization will read a kustomization.yaml and return Kustomization type
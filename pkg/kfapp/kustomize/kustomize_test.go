@@ -11,7 +11,9 @@ import (
 	"strings"
 	"testing"
 
+	kftypesv3 "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
 	"github.com/kubeflow/kfctl/v3/pkg/kfconfig"
+	"github.com/kubeflow/kfctl/v3/pkg/utils"
 	"github.com/otiai10/copy"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -213,3 +215,82 @@ func TestCreateStackAppKustomization(t *testing.T) {
 		}
 	}
 }
+
+func TestAddLabels(t *testing.T) {
+	resMap, err := EvaluateKustomizeManifest("testdata/operator")
+	if err != nil {
+		t.Fatalf("Failed to evaluate manifest. Error: %v.", err)
+	}
+	app := kfconfig.Application{
+		Name:    "operator",
+		Labels:  map[string]string{"team": "ml"},
+		Version: "v1.0.0",
+	}
+	addLabels(resMap, app.ObjectLabels())
+	for _, res := range resMap.Resources() {
+		labels := res.GetLabels()
+		if labels["team"] != "ml" || labels[kfconfig.VersionLabel] != "v1.0.0" {
+			t.Errorf("%v has labels %v; want team=ml and %v=v1.0.0", res.CurId(), labels, kfconfig.VersionLabel)
+		}
+	}
+}
+
+// TestGenerateSelection checks that applications left out by --app are generated by a later build.
+func TestGenerateSelection(t *testing.T) {
+	appDir, err := ioutil.TempDir("", "kfctl-generate-")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(appDir)
+	manifests, err := filepath.Abs("testdata/kustomizeExample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &kfconfig.KfConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kubeflow",
+			Namespace: "kubeflow",
+			Annotations: map[string]string{
+				strings.Join([]string{utils.KfDefAnnotation, utils.Apps}, "/"): "pytorch-operator",
+			},
+		},
+		Spec: kfconfig.KfConfigSpec{
+			AppDir: appDir,
+			Applications: []kfconfig.Application{
+				{
+					Name: "pytorch-operator",
+					KustomizeConfig: &kfconfig.KustomizeConfig{
+						RepoRef:  &kfconfig.RepoRef{Name: "manifests", Path: "pytorch-operator"},
+						Overlays: []string{"application"},
+					},
+				},
+				{
+					Name: "metadata",
+					KustomizeConfig: &kfconfig.KustomizeConfig{
+						RepoRef:  &kfconfig.RepoRef{Name: "manifests", Path: "metadata"},
+						Overlays: []string{"application"},
+					},
+				},
+			},
+		},
+		Status: kfconfig.Status{
+			Caches: []kfconfig.Cache{{Name: "manifests", LocalPath: manifests}},
+		},
+	}
+	if err := GetKfApp(config).Generate(kftypesv3.K8S); err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(appDir, outputDir, "metadata")); !os.IsNotExist(err) {
+		t.Fatalf("Generate() with --app pytorch-operator generated metadata")
+	}
+
+	delete(config.Annotations, strings.Join([]string{utils.KfDefAnnotation, utils.Apps}, "/"))
+	if err := GetKfApp(config).Generate(kftypesv3.K8S); err != nil {
+		t.Fatalf("Generate() failed: %v", err)
+	}
+	for _, app := range []string{"pytorch-operator", "metadata"} {
+		if _, err := os.Stat(filepath.Join(appDir, outputDir, app, "kustomization.yaml")); err != nil {
+			t.Errorf("Generate() didn't generate %v: %v", app, err)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kustomize
+
+import (
+	"fmt"
+	"strconv"
+
+	kfapisv3 "github.com/kubeflow/kfctl/v3/pkg/apis"
+	"github.com/kubeflow/kfctl/v3/pkg/kfconfig"
+	"github.com/kubeflow/kfctl/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	errutil "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// parallelism returns how many applications may be applied at the same time.
+func (kustomize *kustomize) parallelism() int {
+	value := kustomize.getAnnotation(utils.Parallelism)
+	if value == "" {
+		return 1
+	}
+	parallelism, err := strconv.Atoi(value)
+	if err != nil || parallelism < 1 {
+		log.Warnf("Ignoring invalid %v %q; applying one application at a time", utils.Parallelism, value)
+		return 1
+	}
+	return parallelism
+}
+
+// applyApplications calls applyFn for every application in ordered, running at most parallelism
+// calls at a time. An application is only started once all of its dependencies succeeded;
+// ordered must list dependencies before their dependents. After the first failure no new
+// applications are started and the calls in flight are allowed to finish.
+func (kustomize *kustomize) applyApplications(ordered []kfconfig.Application, parallelism int,
+	applyFn func(app kfconfig.Application) error) error {
+	type result struct {
+		name string
+		err  error
+	}
+	results := make(chan result)
+	started := map[string]bool{}
+	done := map[string]bool{}
+	running := 0
+	errs := []error{}
+
+	ready := func(app kfconfig.Application) bool {
+		for _, dep := range app.DependsOn {
+			if !done[dep] {
+				return false
+			}
+		}
+		return true
+	}
+
+	for len(done) < len(ordered) {
+		if len(errs) == 0 {
+			for _, app := range ordered {
+				if running >= parallelism {
+					break
+				}
+				if started[app.Name] || !ready(app) {
+					continue
+				}
+				started[app.Name] = true
+				running++
+				go func(app kfconfig.Application) {
+					results <- result{name: app.Name, err: applyFn(app)}
+				}(app)
+			}
+		}
+		if running == 0 {
+			break
+		}
+		r := <-results
+		running--
+		if r.err != nil {
+			errs = append(errs, r.err)
+			continue
+		}
+		done[r.name] = true
+	}
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return &kfapisv3.KfError{
+			Code:    int(kfapisv3.INTERNAL_ERROR),
+			Message: fmt.Sprintf("failed to apply applications: %v", errutil.NewAggregate(errs)),
+		}
+	}
+}
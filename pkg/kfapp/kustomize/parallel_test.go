@@ -0,0 +1,77 @@
+package kustomize
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kubeflow/kfctl/v3/pkg/kfconfig"
+)
+
+func TestApplyApplications(t *testing.T) {
+	ordered := []kfconfig.Application{
+		{Name: "cert-manager"},
+		{Name: "istio"},
+		{Name: "pipelines", DependsOn: []string{"cert-manager", "istio"}},
+		{Name: "notebooks", DependsOn: []string{"cert-manager"}},
+		{Name: "katib"},
+	}
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	finished := map[string]bool{}
+	applyFn := func(app kfconfig.Application) error {
+		mu.Lock()
+		for _, dep := range app.DependsOn {
+			if !finished[dep] {
+				t.Errorf("%v started before its dependency %v finished", app.Name, dep)
+			}
+		}
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		finished[app.Name] = true
+		mu.Unlock()
+		return nil
+	}
+
+	kustomize := &kustomize{}
+	if err := kustomize.applyApplications(ordered, 2, applyFn); err != nil {
+		t.Fatalf("applyApplications() failed: %v", err)
+	}
+	if len(finished) != len(ordered) {
+		t.Errorf("applied %v applications; want %v", len(finished), len(ordered))
+	}
+	if maxRunning != 2 {
+		t.Errorf("ran %v applications at the same time; want 2", maxRunning)
+	}
+}
+
+func TestApplyApplicationsStopsOnError(t *testing.T) {
+	ordered := []kfconfig.Application{
+		{Name: "cert-manager"},
+		{Name: "pipelines", DependsOn: []string{"cert-manager"}},
+	}
+	applied := []string{}
+	applyFn := func(app kfconfig.Application) error {
+		applied = append(applied, app.Name)
+		return fmt.Errorf("%v failed", app.Name)
+	}
+
+	kustomize := &kustomize{}
+	err := kustomize.applyApplications(ordered, 4, applyFn)
+	if err == nil || err.Error() != "cert-manager failed" {
+		t.Errorf("applyApplications() error = %v; want cert-manager failed", err)
+	}
+	if len(applied) != 1 {
+		t.Errorf("applied %v; dependents of a failed application must not be applied", applied)
+	}
+}
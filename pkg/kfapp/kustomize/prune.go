@@ -0,0 +1,86 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kustomize
+
+import (
+	"fmt"
+
+	"github.com/kubeflow/kfctl/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// obsoleteObjects returns the objects recorded for an application that are no longer rendered.
+// The KfDef namespace holds the inventories and is never pruned.
+func (kustomize *kustomize) obsoleteObjects(previous []utils.ObjectRef, current []utils.ObjectRef) []utils.ObjectRef {
+	obsolete := []utils.ObjectRef{}
+	for _, ref := range utils.ObsoleteObjects(previous, current) {
+		if ref.Kind == "Namespace" && ref.Name == kustomize.kfDef.ObjectMeta.Namespace {
+			log.Warnf("Not pruning %v: it holds the kfctl inventory", ref.Key())
+			continue
+		}
+		obsolete = append(obsolete, ref)
+	}
+	return obsolete
+}
+
+// updateInventory records the objects current applied for app and, depending on the
+// prune and prune-dry-run options, deletes or reports the objects the previous apply
+// recorded but the current render no longer contains. Objects that are not deleted
+// stay in the inventory so that a later apply with --prune can remove them.
+func (kustomize *kustomize) updateInventory(apply *utils.ServerSideApply, inventory *utils.Inventory, app string,
+	previous []utils.ObjectRef, current []utils.ObjectRef) error {
+	logger := apply.Logger()
+	prune := kustomize.getBoolAnnotation(utils.Prune)
+	dryRun := kustomize.getBoolAnnotation(utils.PruneDryRun)
+
+	obsolete := kustomize.obsoleteObjects(previous, current)
+	remaining := current
+	switch {
+	case len(obsolete) == 0:
+	case dryRun:
+		results, _ := apply.Prune(obsolete, true)
+		for _, result := range results {
+			fmt.Fprintf(kustomize.out, "Application %v: %v would be pruned\n", app, result.Key)
+		}
+		remaining = utils.MergeObjects(current, obsolete)
+	case prune:
+		results, err := apply.Prune(obsolete, false)
+		kustomize.recordObjects(app, results.ObjectResults()...)
+		if err != nil {
+			failed := []utils.ObjectRef{}
+			for _, result := range results {
+				if result.Action == utils.ApplyFailed {
+					failed = append(failed, result.Ref)
+				}
+			}
+			if saveErr := inventory.Save(app, utils.MergeObjects(current, failed)); saveErr != nil {
+				logger.Errorf("Couldn't record inventory of application %v: %v", app, saveErr)
+			}
+			return err
+		}
+		logger.Infof("Pruned %v objects of application %v", len(results), app)
+	default:
+		logger.Warnf("Application %v no longer renders %v objects; run apply with --%v to delete them",
+			app, len(obsolete), utils.Prune)
+		remaining = utils.MergeObjects(current, obsolete)
+	}
+
+	if len(remaining) == 0 {
+		return inventory.Remove(app)
+	}
+	return inventory.Save(app, remaining)
+}
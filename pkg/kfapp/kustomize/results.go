@@ -0,0 +1,42 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kustomize
+
+import (
+	kftypesv3 "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
+)
+
+// recordObjects adds objects to the results of application app.
+// Applications applied in parallel record their results concurrently.
+func (kustomize *kustomize) recordObjects(app string, objects ...kftypesv3.ObjectResult) {
+	kustomize.mu.Lock()
+	defer kustomize.mu.Unlock()
+	for i := range kustomize.results {
+		if kustomize.results[i].Name == app {
+			kustomize.results[i].Objects = append(kustomize.results[i].Objects, objects...)
+			return
+		}
+	}
+	kustomize.results = append(kustomize.results, kftypesv3.ApplicationResult{Name: app, Objects: objects})
+}
+
+// Results returns the applications and objects processed by the last command.
+func (kustomize *kustomize) Results() []kftypesv3.ApplicationResult {
+	kustomize.mu.Lock()
+	defer kustomize.mu.Unlock()
+	return append([]kftypesv3.ApplicationResult{}, kustomize.results...)
+}
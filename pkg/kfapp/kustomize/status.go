@@ -0,0 +1,115 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kustomize
+
+import (
+	"fmt"
+
+	kfapisv3 "github.com/kubeflow/kfctl/v3/pkg/apis"
+	kftypesv3 "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
+	"github.com/kubeflow/kfctl/v3/pkg/kfconfig"
+	"github.com/kubeflow/kfctl/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// objectStatus checks a rendered object in the cluster.
+func objectStatus(client *utils.ObjectClient, desired *unstructured.Unstructured) (kftypesv3.ObjectStatus, error) {
+	live, err := client.Get(desired)
+	if err != nil {
+		return kftypesv3.ObjectStatus{}, err
+	}
+	key := utils.ObjectKey(desired)
+	if live == nil {
+		return kftypesv3.ObjectStatus{Key: key, Health: kftypesv3.ObjectMissing}, nil
+	}
+	if readiness := utils.CheckReadiness(live); !readiness.Ready {
+		return kftypesv3.ObjectStatus{Key: key, Health: kftypesv3.ObjectNotReady, Message: readiness.Message}, nil
+	}
+	objDiff, err := diffLiveObject(live, desired)
+	if err != nil {
+		return kftypesv3.ObjectStatus{}, err
+	}
+	if objDiff.Action == DiffChanged {
+		return kftypesv3.ObjectStatus{Key: key, Health: kftypesv3.ObjectDrifted,
+			Message: "differs from the rendered manifests; run kfctl diff for details"}, nil
+	}
+	return kftypesv3.ObjectStatus{Key: key, Health: kftypesv3.ObjectReady}, nil
+}
+
+// applicationStatus renders app and checks each of its objects in the cluster.
+func (kustomize *kustomize) applicationStatus(client *utils.ObjectClient, app kfconfig.Application) (
+	kftypesv3.ApplicationStatus, error) {
+	status := kftypesv3.ApplicationStatus{Name: app.Name}
+	data, err := kustomize.render(app)
+	if err != nil {
+		return status, err
+	}
+	objects, err := utils.ParseObjects(data)
+	if err != nil {
+		return status, &kfapisv3.KfError{
+			Code:    int(kfapisv3.INTERNAL_ERROR),
+			Message: fmt.Sprintf("error splitting yaml for %v: %v", app.Name, err),
+		}
+	}
+	for _, obj := range objects {
+		objStatus, err := objectStatus(client, obj)
+		if err != nil {
+			return status, &kfapisv3.KfError{
+				Code:    int(kfapisv3.INTERNAL_ERROR),
+				Message: fmt.Sprintf("error checking %v of application %v: %v", utils.ObjectKey(obj), app.Name, err),
+			}
+		}
+		switch objStatus.Health {
+		case kftypesv3.ObjectMissing, kftypesv3.ObjectNotReady:
+			status.Degraded = true
+		}
+		status.Objects = append(status.Objects, objStatus)
+	}
+	return status, nil
+}
+
+// Status reports the health of every enabled and selected application in the cluster.
+// Drifted objects are reported but don't degrade an application.
+func (kustomize *kustomize) Status(resources kftypesv3.ResourceEnum) ([]kftypesv3.ApplicationStatus, error) {
+	ordered, err := kustomize.kfDef.ApplicationOrder()
+	if err != nil {
+		return nil, err
+	}
+	selection, err := kustomize.kfDef.ApplicationSelection()
+	if err != nil {
+		return nil, err
+	}
+	client, err := kustomize.objectClient()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []kftypesv3.ApplicationStatus{}
+	for _, app := range ordered {
+		if !app.IsEnabled() || !selection.Selects(app.Name) {
+			continue
+		}
+		log.Infof("Checking application %v", app.Name)
+		status, err := kustomize.applicationStatus(client, app)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
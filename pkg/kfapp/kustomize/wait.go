@@ -0,0 +1,91 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kustomize
+
+import (
+	"time"
+
+	kfapisv3 "github.com/kubeflow/kfctl/v3/pkg/apis"
+	"github.com/kubeflow/kfctl/v3/pkg/kfconfig"
+	"github.com/kubeflow/kfctl/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+)
+
+const (
+	// defaultWaitTimeout is how long --wait waits for a single application.
+	defaultWaitTimeout  = 5 * time.Minute
+	waitPollInterval    = 5 * time.Second
+	applicationsReady   = "ApplicationsReady"
+	applicationNotReady = "ApplicationNotReady"
+)
+
+// waitTimeout returns the per application timeout set with --wait-timeout.
+func (kustomize *kustomize) waitTimeout() time.Duration {
+	value := kustomize.getAnnotation(utils.WaitTimeout)
+	if value == "" {
+		return defaultWaitTimeout
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		log.Warnf("Ignoring invalid %v %q; using %v", utils.WaitTimeout, value, defaultWaitTimeout)
+		return defaultWaitTimeout
+	}
+	return timeout
+}
+
+// waitForApplication waits until the workloads and CRDs in refs are ready.
+// On failure the KfDef is marked Degraded with the objects that never became ready.
+func (kustomize *kustomize) waitForApplication(apply *utils.ServerSideApply, app string, refs []utils.ObjectRef) error {
+	workloads := []utils.ObjectRef{}
+	for _, ref := range refs {
+		if utils.HasReadiness(ref.Kind) {
+			workloads = append(workloads, ref)
+		}
+	}
+	if len(workloads) == 0 {
+		return nil
+	}
+
+	logger := apply.Logger()
+	timeout := kustomize.waitTimeout()
+	logger.Infof("Waiting up to %v for %v objects of application %v to become ready", timeout, len(workloads), app)
+	if err := apply.WaitForReady(workloads, timeout, waitPollInterval); err != nil {
+		notReady := kfapisv3.WrapKfErrorAs(kfapisv3.ErrNotReady, err, "application %v is not ready", app)
+		kfErr, _ := kfapisv3.AsKfError(notReady)
+		kustomize.setCondition(kfconfig.Available, v1.ConditionFalse, applicationNotReady, kfErr.Message)
+		kustomize.setCondition(kfconfig.Degraded, v1.ConditionTrue, applicationNotReady, kfErr.Message)
+		return notReady
+	}
+	logger.Infof("Application %v is ready", app)
+	return nil
+}
+
+// setAvailable marks the KfDef Available once every application is ready.
+func (kustomize *kustomize) setAvailable() {
+	message := "All applications are ready."
+	kustomize.setCondition(kfconfig.Available, v1.ConditionTrue, applicationsReady, message)
+	kustomize.setCondition(kfconfig.Degraded, v1.ConditionFalse, applicationsReady, message)
+}
+
+// setCondition sets a KfDef condition; applications applied in parallel may report their health concurrently.
+func (kustomize *kustomize) setCondition(condType kfconfig.ConditionType, status v1.ConditionStatus, reason string,
+	message string) {
+	kustomize.mu.Lock()
+	defer kustomize.mu.Unlock()
+	kustomize.kfDef.SetCondition(condType, status, reason, message)
+}
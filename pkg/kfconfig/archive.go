@@ -0,0 +1,321 @@
+package kfconfig
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+)
+
+// extractArchive extracts the tar, gzipped tar or zip archive read from r into dir, which
+// must exist. The format is detected from the content. Entries whose path or link target
+// would be outside dir fail the extraction; devices and other special files are skipped.
+func extractArchive(r io.Reader, dir string) error {
+	x, err := newExtractor(dir)
+	if err != nil {
+		return err
+	}
+	br := bufio.NewReaderSize(r, 512)
+	magic, _ := br.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		var gz *gzip.Reader
+		if gz, err = gzip.NewReader(br); err != nil {
+			return kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "invalid gzip archive")
+		}
+		defer gz.Close()
+		err = x.extractTar(gz)
+	case bytes.HasPrefix(magic, zipMagic):
+		err = x.extractZip(br)
+	default:
+		err = x.extractTar(br)
+	}
+	if err != nil {
+		return err
+	}
+	return x.checkLinks()
+}
+
+// extractor writes the entries of an archive below root.
+type extractor struct {
+	root string
+	// realRoot is root with symlinks resolved.
+	realRoot string
+	// links are the symlinks that were created.
+	links []string
+}
+
+func newExtractor(dir string) (*extractor, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return nil, err
+	}
+	return &extractor{root: root, realRoot: realRoot}, nil
+}
+
+func (x *extractor) extractTar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "invalid tar archive")
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = x.mkdir(header.Name)
+		case tar.TypeReg, tar.TypeRegA:
+			err = x.writeFile(header.Name, tr, os.FileMode(header.Mode))
+		case tar.TypeSymlink:
+			err = x.symlink(header.Name, header.Linkname)
+		case tar.TypeLink:
+			err = x.hardlink(header.Name, header.Linkname)
+		case tar.TypeXGlobalHeader:
+			// GitHub tarballs start with a pax header holding the commit.
+		default:
+			log.Debugf("Skipping %v of type %v", header.Name, string(header.Typeflag))
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// extractZip extracts a zip archive, which has to be written to a file first because its
+// index is at the end.
+func (x *extractor) extractZip(r io.Reader) error {
+	tmp, err := ioutil.TempFile("", "kfctl-archive-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	size, err := io.Copy(tmp, r)
+	if err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(tmp, size)
+	if err != nil {
+		return kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "invalid zip archive")
+	}
+	for _, f := range zr.File {
+		if err := x.extractZipFile(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *extractor) extractZipFile(f *zip.File) error {
+	mode := f.Mode()
+	switch {
+	case mode.IsDir():
+		return x.mkdir(f.Name)
+	case mode&os.ModeSymlink != 0, mode.IsRegular():
+		rc, err := f.Open()
+		if err != nil {
+			return kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "invalid zip entry %v", f.Name)
+		}
+		defer rc.Close()
+		if mode.IsRegular() {
+			return x.writeFile(f.Name, rc, mode)
+		}
+		// The target of a symlink is its content.
+		target, err := ioutil.ReadAll(io.LimitReader(rc, 4096))
+		if err != nil {
+			return kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "invalid zip entry %v", f.Name)
+		}
+		return x.symlink(f.Name, string(target))
+	}
+	log.Debugf("Skipping %v of mode %v", f.Name, mode)
+	return nil
+}
+
+// path returns the path of the entry name below root. It fails if name is absolute or
+// has .. elements leaving root.
+func (x *extractor) path(name string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(clean) || filepath.VolumeName(clean) != "" || strings.HasPrefix(name, "/") ||
+		clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", kfapis.NewKfError(kfapis.ErrConfigInvalid, "archive entry %v is outside the archive", name)
+	}
+	return filepath.Join(x.root, clean), nil
+}
+
+// inside returns true if the real path p is root or below it.
+func (x *extractor) inside(p string) bool {
+	rel, err := filepath.Rel(x.realRoot, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// checkParents fails if a directory of p that exists already is a symlink pointing
+// outside root, e.g. a link to .. created by an earlier entry.
+func (x *extractor) checkParents(p string) error {
+	dir := filepath.Dir(p)
+	for {
+		if _, err := os.Lstat(dir); err == nil {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	if !x.inside(real) {
+		return kfapis.NewKfError(kfapis.ErrConfigInvalid, "archive entry %v is outside the archive",
+			strings.TrimPrefix(p, x.root+string(filepath.Separator)))
+	}
+	return nil
+}
+
+// prepare returns the path of the entry name and creates its directory. An existing file
+// or link of that name is removed so that it's not written through.
+func (x *extractor) prepare(name string) (string, error) {
+	p, err := x.path(name)
+	if err != nil {
+		return "", err
+	}
+	if err := x.checkParents(p); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return "", err
+	}
+	if fi, err := os.Lstat(p); err == nil && !fi.IsDir() {
+		if err := os.Remove(p); err != nil {
+			return "", err
+		}
+	}
+	return p, nil
+}
+
+func (x *extractor) mkdir(name string) error {
+	p, err := x.path(name)
+	if err != nil {
+		return err
+	}
+	if err := x.checkParents(p); err != nil {
+		return err
+	}
+	if fi, err := os.Lstat(p); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		if err := os.Remove(p); err != nil {
+			return err
+		}
+	}
+	return os.MkdirAll(p, 0755)
+}
+
+func (x *extractor) writeFile(name string, r io.Reader, mode os.FileMode) error {
+	p, err := x.prepare(name)
+	if err != nil {
+		return err
+	}
+	if p == x.root {
+		return kfapis.NewKfError(kfapis.ErrConfigInvalid, "archive entry %v isn't a file", name)
+	}
+	// Setuid and the like are dropped; the owner can always read and write.
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "couldn't extract %v", name)
+	}
+	return f.Close()
+}
+
+// symlink creates a link to target, which must be relative and stay below root.
+func (x *extractor) symlink(name string, target string) error {
+	if target == "" || filepath.IsAbs(target) || strings.HasPrefix(target, "/") {
+		return kfapis.NewKfError(kfapis.ErrConfigInvalid, "archive link %v points outside the archive to %v",
+			name, target)
+	}
+	p, err := x.prepare(name)
+	if err != nil {
+		return err
+	}
+	resolved := filepath.Join(filepath.Dir(p), filepath.FromSlash(target))
+	if rel, err := filepath.Rel(x.root, resolved); err != nil || rel == ".." ||
+		strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return kfapis.NewKfError(kfapis.ErrConfigInvalid, "archive link %v points outside the archive to %v",
+			name, target)
+	}
+	if err := os.Symlink(filepath.FromSlash(target), p); err != nil {
+		return err
+	}
+	x.links = append(x.links, p)
+	return nil
+}
+
+// hardlink links name to target, an earlier regular file of the archive.
+func (x *extractor) hardlink(name string, target string) error {
+	src, err := x.path(target)
+	if err != nil {
+		return err
+	}
+	realSrc, err := filepath.EvalSymlinks(src)
+	if err != nil || !x.inside(realSrc) {
+		return kfapis.NewKfError(kfapis.ErrConfigInvalid, "archive link %v points outside the archive to %v",
+			name, target)
+	}
+	if fi, err := os.Lstat(realSrc); err != nil || !fi.Mode().IsRegular() {
+		return kfapis.NewKfError(kfapis.ErrConfigInvalid, "archive link %v doesn't point to a file", name)
+	}
+	p, err := x.prepare(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Link(realSrc, p); err == nil {
+		return nil
+	}
+	// Copy the file if hard links aren't supported.
+	f, err := os.Open(realSrc)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return x.writeFile(name, f, fi.Mode())
+}
+
+// checkLinks fails if a symlink resolves to a path outside root through other links,
+// e.g. a link to x/.. where x is a link to ".".
+func (x *extractor) checkLinks() error {
+	for _, link := range x.links {
+		real, err := filepath.EvalSymlinks(link)
+		if err != nil {
+			// Dangling links are fine; their target is below root.
+			continue
+		}
+		if !x.inside(real) {
+			return kfapis.NewKfError(kfapis.ErrConfigInvalid, "archive link %v points outside the archive",
+				strings.TrimPrefix(link, x.root+string(filepath.Separator)))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+package kfconfig
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testEntry is an entry of a test archive.
+type testEntry struct {
+	name     string
+	typeflag byte
+	content  string
+	linkname string
+}
+
+func file(name string, content string) testEntry {
+	return testEntry{name: name, typeflag: tar.TypeReg, content: content}
+}
+
+func dir(name string) testEntry {
+	return testEntry{name: name, typeflag: tar.TypeDir}
+}
+
+func symlink(name string, target string) testEntry {
+	return testEntry{name: name, typeflag: tar.TypeSymlink, linkname: target}
+}
+
+func hardlink(name string, target string) testEntry {
+	return testEntry{name: name, typeflag: tar.TypeLink, linkname: target}
+}
+
+func tarArchive(t *testing.T, entries []testEntry) []byte {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Typeflag: e.typeflag, Linkname: e.linkname, Mode: 0644}
+		if e.typeflag == tar.TypeReg {
+			hdr.Size = int64(len(e.content))
+		}
+		if e.typeflag == tar.TypeDir {
+			hdr.Mode = 0755
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func gzipArchive(t *testing.T, entries []testEntry) []byte {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	if _, err := gz.Write(tarArchive(t, entries)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zipArchive(t *testing.T, entries []testEntry) []byte {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for _, e := range entries {
+		hdr := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		content := e.content
+		switch e.typeflag {
+		case tar.TypeDir:
+			hdr.SetMode(os.ModeDir | 0755)
+		case tar.TypeSymlink:
+			hdr.SetMode(os.ModeSymlink | 0777)
+			content = e.linkname
+		default:
+			hdr.SetMode(0644)
+		}
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// sandbox returns a dir to extract to inside a dir that must stay empty otherwise.
+func sandbox(t *testing.T) (string, func()) {
+	root, err := ioutil.TempDir("", "kfctl-archive-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(root, "cache", "repo")
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(root) }
+}
+
+// checkSandbox fails if anything was written next to the extraction dir.
+func checkSandbox(t *testing.T, dir string, archive []testEntry) {
+	for _, p := range []string{filepath.Dir(filepath.Dir(dir)), filepath.Dir(dir)} {
+		files, err := ioutil.ReadDir(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 1 {
+			t.Fatalf("extracting %+v wrote outside the extraction dir: %v has %v files", archive, p, len(files))
+		}
+	}
+}
+
+func TestExtractArchive(t *testing.T) {
+	type testCase struct {
+		name        string
+		entries     []testEntry
+		expected    map[string]string
+		expectedErr string
+	}
+	testCases := []testCase{
+		{
+			name: "files and links",
+			entries: []testEntry{
+				dir("manifests-abc/"),
+				file("manifests-abc/jupyter/kustomization.yaml", "resources: []\n"),
+				symlink("manifests-abc/current", "jupyter"),
+				symlink("manifests-abc/jupyter/self", "../jupyter/kustomization.yaml"),
+				hardlink("manifests-abc/copy.yaml", "manifests-abc/jupyter/kustomization.yaml"),
+				file("manifests-abc/./dot/../plain.yaml", "a: 1\n"),
+			},
+			expected: map[string]string{
+				"manifests-abc/current/kustomization.yaml": "resources: []\n",
+				"manifests-abc/jupyter/self":               "resources: []\n",
+				"manifests-abc/copy.yaml":                  "resources: []\n",
+				"manifests-abc/plain.yaml":                 "a: 1\n",
+			},
+		},
+		{
+			name:        "parent dir",
+			entries:     []testEntry{file("../evil.yaml", "evil")},
+			expectedErr: "archive entry ../evil.yaml is outside the archive",
+		},
+		{
+			name:        "nested parent dir",
+			entries:     []testEntry{file("a/b/../../../evil.yaml", "evil")},
+			expectedErr: "is outside the archive",
+		},
+		{
+			name:        "absolute path",
+			entries:     []testEntry{file("/tmp/evil.yaml", "evil")},
+			expectedErr: "is outside the archive",
+		},
+		{
+			name:        "absolute symlink",
+			entries:     []testEntry{symlink("passwd", "/etc/passwd")},
+			expectedErr: "archive link passwd points outside the archive to /etc/passwd",
+		},
+		{
+			name:        "symlink to parent",
+			entries:     []testEntry{symlink("up", "../..")},
+			expectedErr: "points outside the archive",
+		},
+		{
+			name:        "write through symlink",
+			entries:     []testEntry{symlink("a", "."), symlink("b", "a/.."), file("b/evil.yaml", "evil")},
+			expectedErr: "is outside the archive",
+		},
+		{
+			name:        "symlink through symlink",
+			entries:     []testEntry{symlink("a", "."), symlink("b", "a/..")},
+			expectedErr: "archive link b points outside the archive",
+		},
+		{
+			name:        "hard link outside",
+			entries:     []testEntry{hardlink("passwd", "../../../../../../etc/passwd")},
+			expectedErr: "is outside the archive",
+		},
+		{
+			name: "file replacing symlink",
+			entries: []testEntry{
+				file("target.yaml", "original"),
+				symlink("link.yaml", "target.yaml"),
+				file("link.yaml", "replaced"),
+			},
+			expected: map[string]string{"target.yaml": "original", "link.yaml": "replaced"},
+		},
+	}
+
+	formats := map[string]func(*testing.T, []testEntry) []byte{
+		"tar":    tarArchive,
+		"tar.gz": gzipArchive,
+		"zip":    zipArchive,
+	}
+	for _, c := range testCases {
+		for format, archive := range formats {
+			hasHardlink := false
+			for _, e := range c.entries {
+				hasHardlink = hasHardlink || e.typeflag == tar.TypeLink
+			}
+			if format == "zip" && hasHardlink {
+				continue
+			}
+			t.Run(c.name+" "+format, func(t *testing.T) {
+				dir, cleanup := sandbox(t)
+				defer cleanup()
+				err := extractArchive(bytes.NewReader(archive(t, c.entries)), dir)
+				checkSandbox(t, dir, c.entries)
+				if c.expectedErr != "" {
+					if err == nil || !strings.Contains(err.Error(), c.expectedErr) {
+						t.Fatalf("extractArchive() error = %v; want %v", err, c.expectedErr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("extractArchive() failed: %v", err)
+				}
+				for name, content := range c.expected {
+					data, err := ioutil.ReadFile(filepath.Join(dir, name))
+					if err != nil {
+						t.Errorf("couldn't read %v: %v", name, err)
+					} else if string(data) != content {
+						t.Errorf("%v = %q; want %q", name, string(data), content)
+					}
+				}
+			})
+		}
+	}
+}
+
+// TestExtractArchive_random extracts random archives of names and links made of tricky
+// path elements and checks that nothing is ever written outside the extraction dir.
+func TestExtractArchive_random(t *testing.T) {
+	elements := []string{"..", ".", "a", "b", "", "/", "c.yaml"}
+	randomPath := func(r *rand.Rand) string {
+		parts := []string{}
+		for i := 0; i < 1+r.Intn(4); i++ {
+			parts = append(parts, elements[r.Intn(len(elements))])
+		}
+		// tar only allows trailing slashes for directories.
+		if p := strings.TrimRight(strings.Join(parts, "/"), "/"); p != "" {
+			return p
+		}
+		return "/a"
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		entries := []testEntry{}
+		for j := 0; j < 1+r.Intn(5); j++ {
+			switch r.Intn(4) {
+			case 0:
+				entries = append(entries, dir(randomPath(r)))
+			case 1:
+				entries = append(entries, symlink(randomPath(r), randomPath(r)))
+			case 2:
+				entries = append(entries, hardlink(randomPath(r), randomPath(r)))
+			default:
+				entries = append(entries, file(randomPath(r), "evil"))
+			}
+		}
+		dir, cleanup := sandbox(t)
+		err := extractArchive(bytes.NewReader(tarArchive(t, entries)), dir)
+		checkSandbox(t, dir, entries)
+		if err == nil {
+			// Every link that resolves must resolve inside the extraction dir.
+			filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+				if err != nil || info.Mode()&os.ModeSymlink == 0 {
+					return nil
+				}
+				if real, err := filepath.EvalSymlinks(p); err == nil {
+					realDir, _ := filepath.EvalSymlinks(dir)
+					if rel, _ := filepath.Rel(realDir, real); strings.HasPrefix(rel, "..") {
+						t.Errorf("extracting %+v created link %v to %v", entries, p, real)
+					}
+				}
+				return nil
+			})
+		}
+		cleanup()
+	}
+}
+
+func TestDownloadArchive_notFound(t *testing.T) {
+	dir, cleanup := sandbox(t)
+	defer cleanup()
+	_, err := downloadArchive("file:"+filepath.Join(dir, "missing.tar.gz"), "", dir)
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("downloadArchive() of a missing file = %v; want 404", err)
+	}
+}
@@ -43,7 +43,7 @@ type RelationDatabaseConfig struct {
 	Port     *int   `json:"port,omitempty"`
 	Database string `json:"database,omitempty"`
 	Username string `json:"username,omitempty"`
-	Password string `json:"password,omitempty"`
+	Password string `json:"password,omitempty" kfctl:"secret"`
 }
 
 type ObjectStorageConfig struct {
@@ -61,7 +61,7 @@ type Auth struct {
 
 type BasicAuth struct {
 	Username string `json:"username,omitempty"`
-	Password string `json:"password,omitempty"`
+	Password string `json:"password,omitempty" kfctl:"secret"`
 }
 
 type OIDC struct {
@@ -71,7 +71,7 @@ type OIDC struct {
 	OidcUserInfoEndpoint      string `json:"oidcUserInfoEndpoint,omitempty"`
 	CertArn                   string `json:"certArn,omitempty"`
 	OAuthClientId             string `json:"oAuthClientId,omitempty"`
-	OAuthClientSecret         string `json:"oAuthClientSecret,omitempty"`
+	OAuthClientSecret         string `json:"oAuthClientSecret,omitempty" kfctl:"secret"`
 }
 
 type Coginito struct {
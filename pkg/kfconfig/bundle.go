@@ -0,0 +1,316 @@
+package kfconfig
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	gogetter "github.com/hashicorp/go-getter"
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// BundleIndexFile is the index of a bundle, which kfctl --offline looks for next to the config.
+	BundleIndexFile = "bundle.json"
+	// BundleKfDefFile is the self contained KfDef of a bundle.
+	BundleKfDefFile = "kfdef.yaml"
+	// BundleImagesFile lists the container images of a bundle, one per line.
+	BundleImagesFile = "images.txt"
+	// BundleReposDir holds the repo archives of a bundle, named by their digest.
+	BundleReposDir = "repos"
+)
+
+// BundleIndex describes a bundle, a directory with everything kfctl needs to build a KfDef
+// without network access: the KfDef, the archives of its repos and the list of images to
+// transfer into the air-gapped registry.
+type BundleIndex struct {
+	Created time.Time `json:"created"`
+	// KfDef and Images are the paths of the KfDef and the image list in the bundle.
+	KfDef  string       `json:"kfdef"`
+	Images string       `json:"images"`
+	Repos  []BundleRepo `json:"repos"`
+
+	// dir is the directory of the bundle.
+	dir string
+}
+
+// BundleRepo is a repo of a bundle.
+type BundleRepo struct {
+	Name string `json:"name"`
+	URI  string `json:"uri"`
+	// Digest of File, e.g. sha256:0123....
+	Digest string `json:"digest"`
+	// File is the path of the archive in the bundle.
+	File string `json:"file"`
+	// Archive is true if File is the archive the repo was downloaded from and false if
+	// kfctl packed it from a git checkout or a local dir.
+	Archive bool `json:"archive"`
+	// Commit is the commit of a git repo.
+	Commit string `json:"commit,omitempty"`
+}
+
+// ReadBundleIndex reads the index of the bundle in dir.
+func ReadBundleIndex(dir string) (*BundleIndex, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, BundleIndexFile))
+	if err != nil {
+		return nil, kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "%v isn't a kfctl bundle", dir)
+	}
+	index := &BundleIndex{}
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "invalid bundle index %v",
+			filepath.Join(dir, BundleIndexFile))
+	}
+	for _, r := range index.Repos {
+		clean := path.Clean(r.File)
+		if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+			return nil, kfapis.NewKfError(kfapis.ErrConfigInvalid, "repo %v of bundle %v is outside the bundle: %v",
+				r.Name, dir, r.File)
+		}
+	}
+	index.dir = dir
+	return index, nil
+}
+
+// Write writes the index into the bundle dir.
+func (b *BundleIndex) Write() error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return ioutil.WriteFile(filepath.Join(b.dir, BundleIndexFile), append(data, '\n'), 0644)
+}
+
+// Find returns the repo of uri. If digest isn't empty, archives must have that digest; an
+// archive of another URI with the digest is used too.
+func (b *BundleIndex) Find(uri string, digest string) (BundleRepo, bool) {
+	for _, r := range b.Repos {
+		if r.URI == uri && (digest == "" || !r.Archive || r.Digest == digest) {
+			return r, true
+		}
+	}
+	if digest != "" {
+		for _, r := range b.Repos {
+			if r.Archive && r.Digest == digest {
+				return r, true
+			}
+		}
+	}
+	return BundleRepo{}, false
+}
+
+// Path returns the path of the archive of r.
+func (b *BundleIndex) Path(r BundleRepo) string {
+	return filepath.Join(b.dir, filepath.FromSlash(path.Clean(r.File)))
+}
+
+// StageBundle writes the archives of the repos of c into dir, the directory of a new bundle,
+// and returns its index. Archives are written as they are downloaded and c is pinned to their
+// digests; other repos are fetched and packed into a gzipped tarball and git repos are pinned
+// to their commits.
+func (c *KfConfig) StageBundle(dir string) (*BundleIndex, error) {
+	if Offline() {
+		return nil, kfapis.NewKfError(kfapis.ErrConfigInvalid, "bundles can't be created offline")
+	}
+	reposDir := filepath.Join(dir, BundleReposDir)
+	if err := os.MkdirAll(reposDir, os.ModePerm); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	index := &BundleIndex{
+		Created: time.Now().UTC(),
+		KfDef:   BundleKfDefFile,
+		Images:  BundleImagesFile,
+		Repos:   []BundleRepo{},
+		dir:     dir,
+	}
+	for i, r := range c.Spec.Repos {
+		log.Infof("Adding repo %v from %v to the bundle", r.Name, r.URI)
+		var b BundleRepo
+		var err error
+		if r.IsArchive() {
+			b, err = bundleArchive(r, reposDir)
+		} else {
+			b, err = bundleDir(r, reposDir)
+		}
+		if err != nil {
+			return nil, kfapis.WrapKfError(err, "couldn't bundle repo %v", r.Name)
+		}
+		if b.Archive {
+			c.Spec.Repos[i].Sha256 = strings.TrimPrefix(b.Digest, "sha256:")
+		}
+		if b.Commit != "" {
+			c.Spec.Repos[i].Ref = b.Commit
+		}
+		index.Repos = append(index.Repos, b)
+	}
+	return index, nil
+}
+
+// bundleArchive downloads the archive of r into reposDir.
+func bundleArchive(r Repo, reposDir string) (BundleRepo, error) {
+	digest, err := writeBundleFile(reposDir, r.URI, r.Digest(), func(w io.Writer) error {
+		rc, err := openURI(r.URI)
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		if _, err := io.Copy(w, rc); err != nil {
+			return kfapis.WrapKfError(err, "couldn't download URI %v", r.URI)
+		}
+		return nil
+	})
+	if err != nil {
+		return BundleRepo{}, err
+	}
+	return BundleRepo{
+		Name:    r.Name,
+		URI:     r.URI,
+		Digest:  digest,
+		File:    path.Join(BundleReposDir, strings.TrimPrefix(digest, "sha256:")),
+		Archive: true,
+	}, nil
+}
+
+// bundleDir packs r, a local dir, a git repo or another go-getter URI, into reposDir.
+func bundleDir(r Repo, reposDir string) (BundleRepo, error) {
+	src := r.URI
+	commit := ""
+	if r.IsGit() {
+		tmp, err := ioutil.TempDir("", "kfctl-bundle-")
+		if err != nil {
+			return BundleRepo{}, errors.WithStack(err)
+		}
+		defer os.RemoveAll(tmp)
+		src = filepath.Join(tmp, "repo")
+		if commit, err = fetchGit(r, src); err != nil {
+			return BundleRepo{}, err
+		}
+	} else if fi, err := os.Stat(r.URI); err != nil || !fi.Mode().IsDir() {
+		tmp, err := ioutil.TempDir("", "kfctl-bundle-")
+		if err != nil {
+			return BundleRepo{}, errors.WithStack(err)
+		}
+		defer os.RemoveAll(tmp)
+		src = filepath.Join(tmp, "repo")
+		fu, err := gogetter.Detect(r.URI, "", gogetter.Detectors)
+		if err != nil {
+			return BundleRepo{}, kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "invalid URI %v", r.URI)
+		}
+		if err := gogetter.GetAny(src, fu); err != nil {
+			return BundleRepo{}, &kfapis.KfError{
+				Code:    int(kfapis.INVALID_ARGUMENT),
+				Message: fmt.Sprintf("couldn't download URI %v Error %v", fu, err),
+			}
+		}
+	}
+	digest, err := writeBundleFile(reposDir, r.URI, "", func(w io.Writer) error {
+		return ArchiveDir(w, src)
+	})
+	if err != nil {
+		return BundleRepo{}, err
+	}
+	return BundleRepo{
+		Name:   r.Name,
+		URI:    r.URI,
+		Digest: digest,
+		File:   path.Join(BundleReposDir, strings.TrimPrefix(digest, "sha256:")),
+		Commit: commit,
+	}, nil
+}
+
+// writeBundleFile writes a file of uri into dir, named by its digest, and returns the digest.
+// If digest isn't empty the file must have that digest.
+func writeBundleFile(dir string, uri string, digest string, write func(w io.Writer) error) (string, error) {
+	tmp, err := ioutil.TempFile(dir, ".tmp-")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer os.Remove(tmp.Name())
+	h := sha256.New()
+	err = write(io.MultiWriter(tmp, h))
+	if closeErr := tmp.Close(); err == nil && closeErr != nil {
+		err = errors.WithStack(closeErr)
+	}
+	if err != nil {
+		return "", err
+	}
+	actual := fmt.Sprintf("sha256:%x", h.Sum(nil))
+	if digest != "" && actual != digest {
+		return "", kfapis.NewKfError(kfapis.ErrConfigInvalid,
+			"%v has digest %v but the KfDef pins %v; refusing to use it", uri, actual, digest)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, strings.TrimPrefix(actual, "sha256:"))); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return actual, nil
+}
+
+// ArchiveDir writes the files, directories and symlinks below dir as a gzipped tarball to w.
+// .git directories are left out.
+func ArchiveDir(w io.Writer, dir string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	err := filepath.Walk(dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == dir {
+			return nil
+		}
+		if fi.IsDir() && fi.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		link := ""
+		if fi.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		} else if !fi.IsDir() && !fi.Mode().IsRegular() {
+			log.Debugf("Skipping %v of mode %v", p, fi.Mode())
+			return nil
+		}
+		hdr, err := tar.FileInfoHeader(fi, filepath.ToSlash(link))
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if fi.IsDir() {
+			hdr.Name += "/"
+		}
+		hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname = 0, 0, "", ""
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return kfapis.WrapKfError(err, "couldn't archive %v", dir)
+	}
+	if err := tw.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(gz.Close())
+}
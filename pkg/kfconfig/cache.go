@@ -0,0 +1,487 @@
+package kfconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	"github.com/otiai10/copy"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// SharedCacheEnv overrides the directory of the shared cache. Setting it to "off"
+	// disables the shared cache and repos are downloaded into every app dir.
+	SharedCacheEnv = "KFCTL_CACHE_DIR"
+
+	sharedCacheOff    = "off"
+	sharedCacheIndex  = "index.json"
+	sharedCacheLock   = "index.lock"
+	sharedCacheBlobs  = "sha256"
+	sharedCacheTmpDir = "tmp"
+
+	// sharedCacheLockTimeout is how long to wait for another kfctl to release the index and
+	// sharedCacheStaleLock the age after which a lock is assumed to be left by a crashed kfctl.
+	sharedCacheLockTimeout = 30 * time.Second
+	sharedCacheStaleLock   = 10 * time.Minute
+)
+
+// SharedCache is a content addressed cache of the repos of all app dirs of a user. Every
+// archive is extracted once into sha256/<digest of the archive> and app dirs link to it
+// from .cache/<repo name>. index.json maps the URIs to the digests of their archives; a URI
+// has an entry for every digest it was downloaded with that app dirs still link to.
+type SharedCache struct {
+	Dir string
+}
+
+// CacheEntry is an entry of the shared cache index.
+type CacheEntry struct {
+	// URI the archive was downloaded from.
+	URI string `json:"uri"`
+	// Digest of the archive, e.g. sha256:0123....
+	Digest string `json:"digest"`
+	// TreeDigest of the extracted files, which verify compares them with.
+	TreeDigest string `json:"treeDigest"`
+	// Size of the extracted files in bytes.
+	Size     int64     `json:"size"`
+	Fetched  time.Time `json:"fetched"`
+	LastUsed time.Time `json:"lastUsed"`
+	// Links are the cache dirs of the app dirs that point to the entry.
+	Links []string `json:"links,omitempty"`
+}
+
+type cacheIndex struct {
+	Entries []CacheEntry `json:"entries"`
+}
+
+// DefaultSharedCache returns the shared cache in $KFCTL_CACHE_DIR or else in kfctl under the
+// user cache dir, e.g. $XDG_CACHE_HOME/kfctl. It returns nil if $KFCTL_CACHE_DIR is off.
+func DefaultSharedCache() (*SharedCache, error) {
+	dir := os.Getenv(SharedCacheEnv)
+	if dir == sharedCacheOff {
+		return nil, nil
+	}
+	if dir == "" {
+		userDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err,
+				"couldn't find the user cache dir; set $%v", SharedCacheEnv)
+		}
+		dir = filepath.Join(userDir, "kfctl")
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, kfapis.WrapKfError(err, "invalid cache dir %v", dir)
+	}
+	return &SharedCache{Dir: dir}, nil
+}
+
+// Path returns the directory holding the files of e.
+func (s *SharedCache) Path(e CacheEntry) string {
+	return filepath.Join(s.Dir, sharedCacheBlobs, strings.TrimPrefix(e.Digest, "sha256:"))
+}
+
+// Get returns the entry of uri with digest; an entry of another URI with the digest is
+// reused. If there is no entry or digest is empty, fetch is called to download and extract
+// the archive into a new directory and to return its digest. The archive at an unpinned URI
+// may change, so it's always downloaded and cached under the digest of the download.
+func (s *SharedCache) Get(uri string, digest string, fetch func(dir string) (string, error)) (CacheEntry, error) {
+	var entry CacheEntry
+	found := false
+	err := s.update(func(index *cacheIndex) error {
+		match := -1
+		if digest != "" {
+			match = index.find(uri, digest)
+		}
+		if match < 0 {
+			return nil
+		}
+		e := index.Entries[match]
+		if _, err := os.Stat(s.Path(e)); err != nil {
+			log.Infof("Shared cache entry %v of %v is missing; fetching it again", e.Digest, e.URI)
+			index.Entries = append(index.Entries[:match], index.Entries[match+1:]...)
+			return nil
+		}
+		index.Entries[match].LastUsed = time.Now()
+		entry = index.Entries[match]
+		if entry.URI != uri {
+			// Record the entry for uri too so that it's linked and listed under it.
+			entry.URI = uri
+			entry.Links = nil
+			index.Entries = append(index.Entries, entry)
+		}
+		found = true
+		return nil
+	})
+	if err != nil || found {
+		return entry, err
+	}
+
+	// Download without holding the lock; other kfctls can use the cache meanwhile.
+	if err := os.MkdirAll(filepath.Join(s.Dir, sharedCacheTmpDir), os.ModePerm); err != nil {
+		return entry, kfapis.WrapKfError(err, "couldn't create cache dir %v", s.Dir)
+	}
+	tmp, err := ioutil.TempDir(filepath.Join(s.Dir, sharedCacheTmpDir), "fetch-")
+	if err != nil {
+		return entry, kfapis.WrapKfError(err, "couldn't create cache dir %v", s.Dir)
+	}
+	defer os.RemoveAll(tmp)
+	log.Infof("Fetching %v into the shared cache %v", uri, s.Dir)
+	fetched, err := fetch(tmp)
+	if err != nil {
+		return entry, err
+	}
+	if digest != "" && fetched != digest {
+		return entry, kfapis.NewKfError(kfapis.ErrConfigInvalid, "%v has digest %v; want %v", uri, fetched, digest)
+	}
+	treeDigest, size, err := TreeDigest(tmp)
+	if err != nil {
+		return entry, kfapis.WrapKfError(err, "couldn't hash %v", uri)
+	}
+	now := time.Now()
+	entry = CacheEntry{
+		URI:        uri,
+		Digest:     fetched,
+		TreeDigest: treeDigest,
+		Size:       size,
+		Fetched:    now,
+		LastUsed:   now,
+	}
+	err = s.update(func(index *cacheIndex) error {
+		dir := s.Path(entry)
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			if err := os.MkdirAll(filepath.Dir(dir), os.ModePerm); err != nil {
+				return err
+			}
+			if err := os.Rename(tmp, dir); err != nil {
+				return err
+			}
+		}
+		for i, e := range index.Entries {
+			if e.URI == uri && e.Digest == entry.Digest {
+				entry.Links = e.Links
+				index.Entries[i] = entry
+				return nil
+			}
+		}
+		index.Entries = append(index.Entries, entry)
+		return nil
+	})
+	if err != nil {
+		return entry, kfapis.WrapKfError(err, "couldn't add %v to the shared cache", uri)
+	}
+	return entry, nil
+}
+
+// Lookup returns the entry of uri with digest, or the one fetched last if digest is empty,
+// without fetching anything. It returns false if there is no such entry or its files are
+// missing.
+func (s *SharedCache) Lookup(uri string, digest string) (CacheEntry, bool, error) {
+	index, err := s.readIndex()
+	if err != nil {
+		return CacheEntry{}, false, err
+	}
+	match := index.find(uri, digest)
+	if match < 0 {
+		return CacheEntry{}, false, nil
+	}
+	e := index.Entries[match]
+	if _, err := os.Stat(s.Path(e)); err != nil {
+		return CacheEntry{}, false, nil
+	}
+	return e, true, nil
+}
+
+// Link points cacheDir to the files of e and records the link so that prune keeps e.
+// The files are copied if the file system doesn't support symlinks.
+func (s *SharedCache) Link(e CacheEntry, cacheDir string) error {
+	absCacheDir, err := filepath.Abs(cacheDir)
+	if err != nil {
+		return kfapis.WrapKfError(err, "invalid cache dir %v", cacheDir)
+	}
+	if err := os.Symlink(s.Path(e), absCacheDir); err != nil {
+		log.Warnf("Couldn't link %v to the shared cache; copying it: %v", cacheDir, err)
+		return copy.Copy(s.Path(e), absCacheDir)
+	}
+	return s.update(func(index *cacheIndex) error {
+		for i := range index.Entries {
+			if index.Entries[i].URI == e.URI && index.Entries[i].Digest == e.Digest && !containsString(index.Entries[i].Links, absCacheDir) {
+				index.Entries[i].Links = append(index.Entries[i].Links, absCacheDir)
+			}
+		}
+		return nil
+	})
+}
+
+// List returns the entries of the cache ordered by URI.
+func (s *SharedCache) List() ([]CacheEntry, error) {
+	index, err := s.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(index.Entries, func(i, j int) bool { return index.Entries[i].URI < index.Entries[j].URI })
+	return index.Entries, nil
+}
+
+// Prune removes the entries no app dir links to anymore, the entries last used before
+// olderThan ago if it isn't zero, or all entries, and returns the removed entries. App dirs
+// that linked to a removed entry fetch their repos again on the next sync.
+func (s *SharedCache) Prune(olderThan time.Duration, all bool) ([]CacheEntry, error) {
+	removed := []CacheEntry{}
+	err := s.update(func(index *cacheIndex) error {
+		kept := []CacheEntry{}
+		for _, e := range index.Entries {
+			e.Links = s.liveLinks(e)
+			expired := olderThan > 0 && time.Since(e.LastUsed) > olderThan
+			if all || expired || len(e.Links) == 0 {
+				removed = append(removed, e)
+				continue
+			}
+			kept = append(kept, e)
+		}
+		index.Entries = kept
+		return s.removeUnusedFiles(index)
+	})
+	return removed, err
+}
+
+// Verify hashes the files of every entry and returns the entries that are missing or were
+// modified. They are removed from the cache if remove is true.
+func (s *SharedCache) Verify(remove bool) ([]CacheEntry, error) {
+	index, err := s.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	bad := map[string]bool{}
+	failed := []CacheEntry{}
+	for _, e := range index.Entries {
+		treeDigest, _, err := TreeDigest(s.Path(e))
+		if err == nil && treeDigest == e.TreeDigest {
+			continue
+		}
+		if err != nil {
+			log.Warnf("Couldn't hash %v of %v: %v", e.Digest, e.URI, err)
+		} else {
+			log.Warnf("Files of %v (%v) were modified: hash %v; want %v", e.URI, e.Digest, treeDigest,
+				e.TreeDigest)
+		}
+		bad[e.Digest] = true
+		failed = append(failed, e)
+	}
+	if !remove || len(failed) == 0 {
+		return failed, nil
+	}
+	return failed, s.update(func(index *cacheIndex) error {
+		kept := []CacheEntry{}
+		for _, e := range index.Entries {
+			if !bad[e.Digest] {
+				kept = append(kept, e)
+			}
+		}
+		index.Entries = kept
+		for digest := range bad {
+			if err := os.RemoveAll(s.Path(CacheEntry{Digest: digest})); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// liveLinks returns the links of e that still point to it.
+func (s *SharedCache) liveLinks(e CacheEntry) []string {
+	links := []string{}
+	for _, link := range e.Links {
+		if target, err := os.Readlink(link); err == nil && target == s.Path(e) {
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// removeUnusedFiles removes the entry dirs not in index and leftover downloads.
+func (s *SharedCache) removeUnusedFiles(index *cacheIndex) error {
+	used := map[string]bool{}
+	for _, e := range index.Entries {
+		used[s.Path(e)] = true
+	}
+	dirs, err := ioutil.ReadDir(filepath.Join(s.Dir, sharedCacheBlobs))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, d := range dirs {
+		dir := filepath.Join(s.Dir, sharedCacheBlobs, d.Name())
+		if !used[dir] {
+			log.Infof("Removing %v", dir)
+			if err := os.RemoveAll(dir); err != nil {
+				return err
+			}
+		}
+	}
+	// Downloads in progress are younger than the stale lock age.
+	tmps, err := ioutil.ReadDir(filepath.Join(s.Dir, sharedCacheTmpDir))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, t := range tmps {
+		if time.Since(t.ModTime()) > sharedCacheStaleLock {
+			if err := os.RemoveAll(filepath.Join(s.Dir, sharedCacheTmpDir, t.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// find returns the index of the entry of uri fetched last or, if digest isn't empty, of an
+// entry with that digest, preferring the one of uri. It returns -1 if there is none.
+func (index *cacheIndex) find(uri string, digest string) int {
+	match := -1
+	for i, e := range index.Entries {
+		switch {
+		case digest == "" && e.URI == uri:
+			if match < 0 || e.Fetched.After(index.Entries[match].Fetched) {
+				match = i
+			}
+		case digest != "" && e.Digest == digest:
+			if match < 0 || e.URI == uri {
+				match = i
+			}
+		}
+	}
+	return match
+}
+
+func (s *SharedCache) readIndex() (*cacheIndex, error) {
+	index := &cacheIndex{}
+	data, err := ioutil.ReadFile(filepath.Join(s.Dir, sharedCacheIndex))
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, kfapis.WrapKfError(err, "couldn't read the shared cache index")
+	}
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "invalid shared cache index %v",
+			filepath.Join(s.Dir, sharedCacheIndex))
+	}
+	return index, nil
+}
+
+// update applies f to the index while holding the lock of the cache and writes it back.
+func (s *SharedCache) update(f func(index *cacheIndex) error) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	index, err := s.readIndex()
+	if err != nil {
+		return err
+	}
+	if err := f(index); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(s.Dir, sharedCacheIndex+".tmp")
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return kfapis.WrapKfError(err, "couldn't write the shared cache index")
+	}
+	return os.Rename(tmp, filepath.Join(s.Dir, sharedCacheIndex))
+}
+
+// lock creates the lock file of the cache, waiting for other kfctls to remove it.
+func (s *SharedCache) lock() (func(), error) {
+	if err := os.MkdirAll(s.Dir, os.ModePerm); err != nil {
+		return nil, kfapis.WrapKfError(err, "couldn't create cache dir %v", s.Dir)
+	}
+	lockFile := filepath.Join(s.Dir, sharedCacheLock)
+	deadline := time.Now().Add(sharedCacheLockTimeout)
+	for {
+		f, err := os.OpenFile(lockFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%v\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(lockFile) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, kfapis.WrapKfError(err, "couldn't lock the shared cache")
+		}
+		if fi, err := os.Stat(lockFile); err == nil && time.Since(fi.ModTime()) > sharedCacheStaleLock {
+			log.Warnf("Removing stale lock %v", lockFile)
+			os.Remove(lockFile)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, &kfapis.KfError{
+				Code:    int(kfapis.INTERNAL_ERROR),
+				Message: fmt.Sprintf("timed out waiting for %v; remove it if no other kfctl is running", lockFile),
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// TreeDigest returns the sha256 of the names, types and contents of the files under dir
+// and their total size.
+func TreeDigest(dir string) (string, int64, error) {
+	h := sha256.New()
+	var size int64
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(h, "link %v %v\x00", rel, target)
+		case info.IsDir():
+			fmt.Fprintf(h, "dir %v\x00", rel)
+		case info.Mode().IsRegular():
+			fmt.Fprintf(h, "file %v %v\x00", rel, info.Size())
+			f, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if _, err := io.Copy(h, f); err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
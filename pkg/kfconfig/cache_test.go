@@ -0,0 +1,278 @@
+package kfconfig
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testTarball returns a gzipped tarball of files unpacking into a GitHub style commit dir.
+func testTarball(t *testing.T, files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: "manifests-abc123/", Typeflag: tar.TypeDir, Mode: 0755}); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		hdr := &tar.Header{Name: "manifests-abc123/" + name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestSyncCache_sharedCache(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "kfctl-shared-cache-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+	defer os.Setenv(SharedCacheEnv, os.Getenv(SharedCacheEnv))
+	os.Setenv(SharedCacheEnv, filepath.Join(testDir, "shared"))
+
+	tarball := testTarball(t, map[string]string{"kustomization.yaml": "resources: []\n"})
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		w.Write(tarball)
+	}))
+	defer server.Close()
+	uri := server.URL + "/manifests.tar.gz"
+
+	for _, app := range []string{"app1", "app2"} {
+		config := &KfConfig{
+			Spec: KfConfigSpec{
+				AppDir: filepath.Join(testDir, app),
+				Repos:  []Repo{{Name: "manifests", URI: uri}},
+			},
+		}
+		if err := config.SyncCache(); err != nil {
+			t.Fatalf("SyncCache() failed: %v", err)
+		}
+		expected := filepath.Join(testDir, app, DefaultCacheDir, "manifests", "manifests-abc123")
+		if config.Status.Caches[0].LocalPath != expected {
+			t.Errorf("LocalPath = %v; want %v", config.Status.Caches[0].LocalPath, expected)
+		}
+		if _, err := os.Stat(filepath.Join(expected, "kustomization.yaml")); err != nil {
+			t.Errorf("kustomization.yaml wasn't fetched: %v", err)
+		}
+	}
+	// The URI isn't pinned, so every app dir downloads it; the files are stored once.
+	if downloads != 2 {
+		t.Errorf("%v downloads; want 2", downloads)
+	}
+
+	cache, err := DefaultSharedCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := cache.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].URI != uri || len(entries[0].Links) != 2 {
+		t.Fatalf("List() = %+v; want one entry of %v with two links", entries, uri)
+	}
+
+	// Entries are kept as long as an app dir links to them.
+	if err := os.RemoveAll(filepath.Join(testDir, "app1")); err != nil {
+		t.Fatal(err)
+	}
+	if removed, err := cache.Prune(0, false); err != nil || len(removed) != 0 {
+		t.Fatalf("Prune() = %v, %v; want nothing removed", removed, err)
+	}
+	if removed, err := cache.Prune(time.Hour, false); err != nil || len(removed) != 0 {
+		t.Fatalf("Prune(1h) = %v, %v; want nothing removed", removed, err)
+	}
+
+	// Modified files fail verification.
+	app2File := filepath.Join(testDir, "app2", DefaultCacheDir, "manifests", "manifests-abc123", "kustomization.yaml")
+	if err := ioutil.WriteFile(app2File, []byte("resources: [evil.yaml]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	failed, err := cache.Verify(true)
+	if err != nil || len(failed) != 1 {
+		t.Fatalf("Verify() = %v, %v; want one entry", failed, err)
+	}
+	if _, err := os.Stat(cache.Path(entries[0])); !os.IsNotExist(err) {
+		t.Errorf("Verify(true) didn't remove %v", cache.Path(entries[0]))
+	}
+
+	// The app dir links to the removed entry and fetches it again.
+	config := &KfConfig{
+		Spec: KfConfigSpec{
+			AppDir: filepath.Join(testDir, "app2"),
+			Repos:  []Repo{{Name: "manifests", URI: uri}},
+		},
+	}
+	if err := config.SyncCache(); err != nil {
+		t.Fatalf("SyncCache() failed: %v", err)
+	}
+	if downloads != 3 {
+		t.Errorf("%v downloads; want 3", downloads)
+	}
+	if failed, err := cache.Verify(false); err != nil || len(failed) != 0 {
+		t.Errorf("Verify() = %v, %v; want no failures", failed, err)
+	}
+
+	if err := os.RemoveAll(filepath.Join(testDir, "app2")); err != nil {
+		t.Fatal(err)
+	}
+	removed, err := cache.Prune(0, false)
+	if err != nil || len(removed) != 1 {
+		t.Fatalf("Prune() = %v, %v; want the entry removed", removed, err)
+	}
+	if dirs, _ := ioutil.ReadDir(filepath.Join(cache.Dir, sharedCacheBlobs)); len(dirs) != 0 {
+		t.Errorf("Prune() left %v entries", len(dirs))
+	}
+}
+
+func TestSyncCache_sharedCacheChanged(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "kfctl-shared-cache-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+	defer os.Setenv(SharedCacheEnv, os.Getenv(SharedCacheEnv))
+	os.Setenv(SharedCacheEnv, filepath.Join(testDir, "shared"))
+
+	version := "v1"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(testTarball(t, map[string]string{"kustomization.yaml": "# " + version + "\n"}))
+	}))
+	defer server.Close()
+	uri := server.URL + "/manifests.tar.gz"
+
+	// An unpinned URI whose archive changed isn't served from the cache.
+	for _, v := range []string{"v1", "v2"} {
+		version = v
+		config := &KfConfig{
+			Spec: KfConfigSpec{
+				AppDir: filepath.Join(testDir, "app-"+v),
+				Repos:  []Repo{{Name: "manifests", URI: uri}},
+			},
+		}
+		if err := config.SyncCache(); err != nil {
+			t.Fatalf("SyncCache() failed: %v", err)
+		}
+		data, err := ioutil.ReadFile(filepath.Join(config.Status.Caches[0].LocalPath, "kustomization.yaml"))
+		if err != nil || string(data) != "# "+v+"\n" {
+			t.Errorf("kustomization.yaml = %q, %v; want %v", data, err, v)
+		}
+	}
+
+	// Both versions are kept while app dirs link to them.
+	cache, err := DefaultSharedCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := cache.List()
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("List() = %+v, %v; want two entries", entries, err)
+	}
+	for _, e := range entries {
+		if len(e.Links) != 1 {
+			t.Errorf("entry %v has links %v; want one", e.Digest, e.Links)
+		}
+	}
+}
+
+func TestSyncCache_sharedCacheOff(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "kfctl-shared-cache-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+	defer os.Setenv(SharedCacheEnv, os.Getenv(SharedCacheEnv))
+	os.Setenv(SharedCacheEnv, "off")
+
+	tarballPath := filepath.Join(testDir, "manifests.tar.gz")
+	if err := ioutil.WriteFile(tarballPath, testTarball(t, map[string]string{"a.yaml": "a: 1\n"}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := &KfConfig{
+		Spec: KfConfigSpec{
+			AppDir: filepath.Join(testDir, "app"),
+			Repos:  []Repo{{Name: "manifests", URI: "file:" + tarballPath}},
+		},
+	}
+	if err := config.SyncCache(); err != nil {
+		t.Fatalf("SyncCache() failed: %v", err)
+	}
+	cacheDir := filepath.Join(testDir, "app", DefaultCacheDir, "manifests")
+	if fi, err := os.Lstat(cacheDir); err != nil || !fi.IsDir() {
+		t.Errorf("%v isn't a directory: %v", cacheDir, err)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, "manifests-abc123", "a.yaml")); err != nil {
+		t.Errorf("a.yaml wasn't fetched: %v", err)
+	}
+}
+
+func TestSyncCache_sha256(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "kfctl-shared-cache-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+	defer os.Setenv(SharedCacheEnv, os.Getenv(SharedCacheEnv))
+
+	tarball := testTarball(t, map[string]string{"kustomization.yaml": "resources: []\n"})
+	tarballPath := filepath.Join(testDir, "manifests.tar.gz")
+	if err := ioutil.WriteFile(tarballPath, tarball, 0644); err != nil {
+		t.Fatal(err)
+	}
+	digest := fmt.Sprintf("%x", sha256.Sum256(tarball))
+	wrong := fmt.Sprintf("%x", sha256.Sum256([]byte("tampered")))
+
+	for _, shared := range []string{filepath.Join(testDir, "shared"), "off"} {
+		os.Setenv(SharedCacheEnv, shared)
+		appDir := filepath.Join(testDir, "app-"+filepath.Base(shared))
+		config := &KfConfig{
+			Spec: KfConfigSpec{
+				AppDir: appDir,
+				Repos:  []Repo{{Name: "manifests", URI: "file:" + tarballPath, Sha256: wrong}},
+			},
+		}
+		err := config.SyncCache()
+		if err == nil || !strings.Contains(err.Error(), "refusing to use it") {
+			t.Fatalf("SyncCache() with a wrong sha256 = %v; want a digest mismatch", err)
+		}
+		if _, err := os.Stat(filepath.Join(appDir, DefaultCacheDir, "manifests", "manifests-abc123")); !os.IsNotExist(err) {
+			t.Errorf("SyncCache() extracted an archive with a wrong sha256")
+		}
+
+		config.Spec.Repos[0].Sha256 = "sha256:" + strings.ToUpper(digest)
+		if err := config.SyncCache(); err != nil {
+			t.Fatalf("SyncCache() failed: %v", err)
+		}
+		if config.Status.Caches[0].Digest != "sha256:"+digest {
+			t.Errorf("Digest = %v; want sha256:%v", config.Status.Caches[0].Digest, digest)
+		}
+
+		resolved, err := config.Spec.Repos[0].ResolveDigest()
+		if err != nil || resolved != "sha256:"+digest {
+			t.Errorf("ResolveDigest() = %v, %v; want sha256:%v", resolved, err, digest)
+		}
+	}
+}
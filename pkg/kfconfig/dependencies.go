@@ -0,0 +1,93 @@
+package kfconfig
+
+import (
+	"fmt"
+	"strings"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+)
+
+// ApplicationOrder returns the applications of c ordered so that every application comes
+// after the applications it depends on. Applications without ordering constraints keep
+// their order in Spec.Applications. Like Apply, only the first application with a given
+// name is used.
+//
+// An error is returned if an application depends on an application that doesn't exist
+// or if the dependencies contain a cycle.
+func (c *KfConfig) ApplicationOrder() ([]Application, error) {
+	apps := map[string]Application{}
+	names := []string{}
+	for _, app := range c.Spec.Applications {
+		if _, ok := apps[app.Name]; ok {
+			continue
+		}
+		apps[app.Name] = app
+		names = append(names, app.Name)
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	ordered := []Application{}
+	// path holds the chain of applications being visited and is used to report cycles.
+	path := []string{}
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			start := 0
+			for i, n := range path {
+				if n == name {
+					start = i
+				}
+			}
+			cycle := append(append([]string{}, path[start:]...), name)
+			return &kfapis.KfError{
+				Code:    int(kfapis.INVALID_ARGUMENT),
+				Message: fmt.Sprintf("applications have a dependency cycle: %v", strings.Join(cycle, " -> ")),
+			}
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range apps[name].DependsOn {
+			if _, ok := apps[dep]; !ok {
+				return &kfapis.KfError{
+					Code:    int(kfapis.INVALID_ARGUMENT),
+					Message: fmt.Sprintf("application %v depends on unknown application %v", name, dep),
+				}
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		ordered = append(ordered, apps[name])
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// Dependencies returns the names of the applications at least one other application depends on.
+func (c *KfConfig) Dependencies() map[string]bool {
+	deps := map[string]bool{}
+	for _, app := range c.Spec.Applications {
+		for _, dep := range app.DependsOn {
+			deps[dep] = true
+		}
+	}
+	return deps
+}
@@ -0,0 +1,72 @@
+package kfconfig
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplicationOrder(t *testing.T) {
+	type testCase struct {
+		name        string
+		apps        []Application
+		expected    []string
+		expectedErr string
+	}
+	testCases := []testCase{
+		{
+			name:     "list order",
+			apps:     []Application{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name: "dependencies first",
+			apps: []Application{
+				{Name: "kubeflow-apps", DependsOn: []string{"istio", "cert-manager"}},
+				{Name: "istio", DependsOn: []string{"cert-manager"}},
+				{Name: "cert-manager"},
+				{Name: "istio"},
+			},
+			expected: []string{"cert-manager", "istio", "kubeflow-apps"},
+		},
+		{
+			name: "unknown dependency",
+			apps: []Application{
+				{Name: "a", DependsOn: []string{"missing"}},
+			},
+			expectedErr: "application a depends on unknown application missing",
+		},
+		{
+			name: "cycle",
+			apps: []Application{
+				{Name: "a", DependsOn: []string{"b"}},
+				{Name: "b", DependsOn: []string{"c"}},
+				{Name: "c", DependsOn: []string{"b"}},
+			},
+			expectedErr: "applications have a dependency cycle: b -> c -> b",
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			config := &KfConfig{Spec: KfConfigSpec{Applications: c.apps}}
+			ordered, err := config.ApplicationOrder()
+			if c.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.expectedErr) {
+					t.Fatalf("ApplicationOrder() error = %v; want %v", err, c.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplicationOrder() failed: %v", err)
+			}
+			names := []string{}
+			for _, app := range ordered {
+				names = append(names, app.Name)
+			}
+			if !reflect.DeepEqual(names, c.expected) {
+				t.Errorf("ApplicationOrder() = %v; want %v", names, c.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package kfconfig
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"strings"
+
+	gogetter "github.com/hashicorp/go-getter"
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// gitCommand is the command git repos are fetched with.
+	gitCommand = "git"
+	// commitPattern matches full commit SHAs.
+	commitPattern = regexp.MustCompile(`^[0-9a-fA-F]{40}$`)
+	// pullPattern matches GitHub pull request refs without the /head suffix.
+	pullPattern = regexp.MustCompile(`^(refs/)?pull/[0-9]+$`)
+	// invalidRefPattern matches refs that git check-ref-format rejects and refs starting with
+	// a dash, which git would parse as an option.
+	invalidRefPattern = regexp.MustCompile(`^-|^/|/$|\.\.|//|@\{|\.lock$|\.$|/\.|^\.|^@$|[\x00-\x20\x7f~^:?*\[\\]`)
+	// fetchConfigErrorPattern matches the git fetch errors caused by the repo rather than the
+	// network: missing refs, missing repos, unsupported schemes and missing credentials.
+	fetchConfigErrorPattern = regexp.MustCompile(`(?i)couldn't find remote ref|invalid refspec|not our ref|` +
+		`unadvertised object|does not appear to be a git repository|repository '.*' not found|` +
+		`unable to find remote helper|could not read username|authentication failed`)
+)
+
+// IsGit returns true if the repo is fetched with git, i.e. its URI is a git:: URI or
+// one go-getter detects as git, such as github.com/kubeflow/manifests.
+func (r Repo) IsGit() bool {
+	fu, err := gogetter.Detect(r.URI, "", gogetter.Detectors)
+	if err != nil {
+		return false
+	}
+	ms := forcedGetterRegexp.FindStringSubmatch(fu)
+	return ms != nil && ms[1] == "git"
+}
+
+// gitSource returns the URL to clone, the ref and the sub path of a git repo. The go-getter
+// forms git::URL?ref=REF and git::URL//SUBPATH work too but mustn't conflict with Ref and
+// SubPath.
+func (r Repo) gitSource() (string, string, string, error) {
+	fu, err := gogetter.Detect(r.URI, "", gogetter.Detectors)
+	if err != nil {
+		return "", "", "", kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "invalid URI %v", r.URI)
+	}
+	src, subPath := gogetter.SourceDirSubdir(strings.TrimPrefix(fu, "git::"))
+	u, err := url.Parse(src)
+	if err != nil {
+		return "", "", "", kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "invalid URI %v", r.URI)
+	}
+	query := u.Query()
+	ref := query.Get("ref")
+	query.Del("ref")
+	if len(query) > 0 {
+		return "", "", "", kfapis.NewKfError(kfapis.ErrConfigInvalid,
+			"git URI %v has unsupported parameters; only ref is supported", r.URI)
+	}
+	u.RawQuery = ""
+	if ref != "" && r.Ref != "" && ref != r.Ref {
+		return "", "", "", kfapis.NewKfError(kfapis.ErrConfigInvalid,
+			"git URI %v has ref %v but the repo has ref %v", r.URI, ref, r.Ref)
+	}
+	if r.Ref != "" {
+		ref = r.Ref
+	}
+	if subPath != "" && r.SubPath != "" && path.Clean(subPath) != path.Clean(r.SubPath) {
+		return "", "", "", kfapis.NewKfError(kfapis.ErrConfigInvalid,
+			"git URI %v has sub path %v but the repo has subPath %v", r.URI, subPath, r.SubPath)
+	}
+	if r.SubPath != "" {
+		subPath = r.SubPath
+	}
+	if ref != "" && invalidRefPattern.MatchString(ref) {
+		return "", "", "", kfapis.NewKfError(kfapis.ErrConfigInvalid, "git URI %v has invalid ref %q", r.URI, ref)
+	}
+	if err := checkSubPath(subPath); err != nil {
+		return "", "", "", err
+	}
+	// Like DownloadToCache, pull/<ID> means the head of the pull request.
+	if pullPattern.MatchString(ref) {
+		ref += "/head"
+	}
+	return u.String(), ref, subPath, nil
+}
+
+// subPath returns the directory of the repo that applications are relative to, or "".
+func (r Repo) subPath() (string, error) {
+	if !r.IsGit() {
+		return r.SubPath, checkSubPath(r.SubPath)
+	}
+	_, _, subPath, err := r.gitSource()
+	return subPath, err
+}
+
+// checkSubPath fails unless subPath is empty or a relative path inside the repo.
+func checkSubPath(subPath string) error {
+	if subPath == "" {
+		return nil
+	}
+	if clean := path.Clean(subPath); path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return kfapis.NewKfError(kfapis.ErrConfigInvalid, "subPath %v must be a relative path inside the repo", subPath)
+	}
+	return nil
+}
+
+// fetchGit makes a shallow clone of the ref of r in dir and returns the commit it resolved to.
+func fetchGit(r Repo, dir string) (string, error) {
+	remote, ref, _, err := r.gitSource()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", errors.WithStack(err)
+	}
+	if _, err := runGit(dir, "init", "--quiet"); err != nil {
+		return "", err
+	}
+	fetchRef := ref
+	if fetchRef == "" {
+		fetchRef = "HEAD"
+	}
+	log.Infof("Fetching %v of %v", fetchRef, remote)
+	if _, err := runGit(dir, "fetch", "--quiet", "--depth", "1", "--no-tags", "--", remote, fetchRef); err != nil {
+		// Servers may refuse to send commits that no ref points to; then fetch all branches
+		// and tags and look for the commit.
+		if !commitPattern.MatchString(ref) {
+			return "", err
+		}
+		log.Infof("Couldn't fetch commit %v of %v directly; fetching all branches: %v", ref, remote, err)
+		if _, err := runGit(dir, "fetch", "--quiet", "--", remote, "+refs/heads/*:refs/remotes/origin/*",
+			"+refs/tags/*:refs/tags/*"); err != nil {
+			return "", err
+		}
+		fetchRef = ref
+	} else {
+		fetchRef = "FETCH_HEAD"
+	}
+	if _, err := runGit(dir, "checkout", "--quiet", "--detach", fetchRef+"^{commit}"); err != nil {
+		return "", err
+	}
+	commit, err := runGit(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	if commitPattern.MatchString(ref) && !strings.EqualFold(commit, ref) {
+		return "", kfapis.NewKfError(kfapis.ErrConfigInvalid, "%v resolved to commit %v instead of %v", remote, commit, ref)
+	}
+	return commit, nil
+}
+
+// runGit runs git with args in dir and returns its trimmed output.
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command(gitCommand, args...)
+	cmd.Dir = dir
+	// Fail instead of waiting for credentials.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	stderr := &strings.Builder{}
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := fmt.Sprintf("git %v failed: %v: %v", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+		// Fetches fail on network and server errors too; those may succeed on retry.
+		if args[0] == "fetch" {
+			if fetchConfigErrorPattern.MatchString(stderr.String()) {
+				return "", kfapis.NewKfError(kfapis.ErrConfigInvalid, "%v", msg)
+			}
+			return "", kfapis.NewKfError(kfapis.ErrClusterUnreachable, "%v", msg)
+		}
+		return "", &kfapis.KfError{
+			Code:    int(kfapis.INVALID_ARGUMENT),
+			Message: msg,
+		}
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,198 @@
+package kfconfig
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+)
+
+// testGitRepo creates a bare repo with two commits of manifests/kustomization.yaml, the
+// first tagged v1.0, and returns its path and the commits.
+func testGitRepo(t *testing.T, dir string) (string, []string) {
+	work := filepath.Join(dir, "work")
+	if err := os.MkdirAll(filepath.Join(work, "manifests"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	git := func(dir string, args ...string) string {
+		out, err := runGit(dir, append([]string{"-c", "user.name=kfctl", "-c", "user.email=kfctl@example.com"}, args...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	git(work, "init", "--quiet")
+	commits := []string{}
+	for _, version := range []string{"v1", "v2"} {
+		file := filepath.Join(work, "manifests", "kustomization.yaml")
+		if err := ioutil.WriteFile(file, []byte("# "+version+"\nresources: []\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git(work, "add", "-A")
+		git(work, "commit", "--quiet", "-m", version)
+		commits = append(commits, git(work, "rev-parse", "HEAD"))
+		if version == "v1" {
+			git(work, "tag", "-a", "-m", "v1.0", "v1.0")
+		}
+	}
+	bare := filepath.Join(dir, "manifests.git")
+	git(dir, "clone", "--quiet", "--bare", work, bare)
+	return bare, commits
+}
+
+func TestSyncCache_git(t *testing.T) {
+	if _, err := exec.LookPath(gitCommand); err != nil {
+		t.Skipf("git isn't installed: %v", err)
+	}
+	testDir, err := ioutil.TempDir("", "kfctl-git-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+	bare, commits := testGitRepo(t, testDir)
+
+	type testCase struct {
+		name    string
+		repo    Repo
+		commit  string
+		version string
+		subPath string
+	}
+	cases := []testCase{
+		{
+			name:    "default-branch",
+			repo:    Repo{URI: "git::" + bare},
+			commit:  commits[1],
+			version: "v2",
+		},
+		{
+			name:    "tag",
+			repo:    Repo{URI: "git::file://" + bare, Ref: "v1.0", SubPath: "manifests"},
+			commit:  commits[0],
+			version: "v1",
+			subPath: "manifests",
+		},
+		{
+			name:    "commit",
+			repo:    Repo{URI: "git::" + bare, Ref: commits[0], SubPath: "manifests"},
+			commit:  commits[0],
+			version: "v1",
+			subPath: "manifests",
+		},
+		{
+			name:    "go-getter-syntax",
+			repo:    Repo{URI: "git::file://" + bare + "//manifests?ref=v1.0"},
+			commit:  commits[0],
+			version: "v1",
+			subPath: "manifests",
+		},
+	}
+	for _, c := range cases {
+		c.repo.Name = "manifests"
+		config := &KfConfig{
+			Spec: KfConfigSpec{
+				AppDir: filepath.Join(testDir, c.name),
+				Repos:  []Repo{c.repo},
+			},
+		}
+		if err := config.SyncCache(); err != nil {
+			t.Errorf("%v: SyncCache() failed: %v", c.name, err)
+			continue
+		}
+		cache := config.Status.Caches[0]
+		expected := filepath.Join(testDir, c.name, DefaultCacheDir, "manifests", c.subPath)
+		if cache.LocalPath != expected || cache.Commit != c.commit {
+			t.Errorf("%v: cache = %+v; want LocalPath %v and Commit %v", c.name, cache, expected, c.commit)
+		}
+		data, err := ioutil.ReadFile(filepath.Join(testDir, c.name, DefaultCacheDir, "manifests", "manifests", "kustomization.yaml"))
+		if err != nil || string(data[:4]) != "# "+c.version {
+			t.Errorf("%v: kustomization.yaml = %q, %v; want %v", c.name, data, err, c.version)
+		}
+	}
+
+	// Changing the commit fetches the repo again.
+	config := &KfConfig{
+		Spec: KfConfigSpec{
+			AppDir: filepath.Join(testDir, "update"),
+			Repos:  []Repo{{Name: "manifests", URI: "git::" + bare, Ref: commits[0]}},
+		},
+	}
+	if err := config.SyncCache(); err != nil {
+		t.Fatalf("SyncCache() failed: %v", err)
+	}
+	config.Spec.Repos[0].Ref = commits[1]
+	if err := config.SyncCache(); err != nil {
+		t.Fatalf("SyncCache() failed: %v", err)
+	}
+	if last := config.Status.Caches[len(config.Status.Caches)-1]; last.Commit != commits[1] {
+		t.Errorf("Status.Caches = %+v; want an entry for %v", config.Status.Caches, commits[1])
+	}
+
+	// Missing refs and sub paths, refs git would parse as options and sub paths outside the
+	// repo fail.
+	marker := filepath.Join(testDir, "pwned")
+	for _, r := range []Repo{
+		{Name: "manifests", URI: "git::" + bare, Ref: "v9.9"},
+		{Name: "manifests", URI: "git::" + bare, SubPath: "missing"},
+		{Name: "manifests", URI: "git::" + bare, Ref: "--upload-pack=touch " + marker},
+		{Name: "manifests", URI: "git::file://" + bare + "?ref=--upload-pack=touch%20" + marker},
+		{Name: "manifests", URI: "git::" + bare, SubPath: "../../.."},
+		{Name: "manifests", URI: bare, SubPath: "../../.."},
+	} {
+		config := &KfConfig{Spec: KfConfigSpec{AppDir: filepath.Join(testDir, "missing"), Repos: []Repo{r}}}
+		if err := config.SyncCache(); err == nil {
+			t.Errorf("SyncCache() of %+v succeeded; want an error", r)
+		}
+		os.RemoveAll(filepath.Join(testDir, "missing"))
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Errorf("a ref ran a command")
+	}
+}
+
+func TestSyncCache_gitErrors(t *testing.T) {
+	if _, err := exec.LookPath(gitCommand); err != nil {
+		t.Skipf("git isn't installed: %v", err)
+	}
+	testDir, err := ioutil.TempDir("", "kfctl-git-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+	bare, _ := testGitRepo(t, testDir)
+
+	type testCase struct {
+		name string
+		repo Repo
+		kind error
+	}
+	cases := []testCase{
+		{
+			name: "missing-ref",
+			repo: Repo{URI: "git::" + bare, Ref: "v9.9"},
+			kind: kfapis.ErrConfigInvalid,
+		},
+		{
+			name: "missing-repo",
+			repo: Repo{URI: "git::" + filepath.Join(testDir, "missing.git")},
+			kind: kfapis.ErrConfigInvalid,
+		},
+		{
+			name: "unreachable",
+			repo: Repo{URI: "git::http://127.0.0.1:1/manifests.git"},
+			kind: kfapis.ErrClusterUnreachable,
+		},
+	}
+	for _, c := range cases {
+		c.repo.Name = "manifests"
+		config := &KfConfig{Spec: KfConfigSpec{AppDir: filepath.Join(testDir, c.name), Repos: []Repo{c.repo}}}
+		err := config.SyncCache()
+		if !errors.Is(err, c.kind) {
+			t.Errorf("%v: SyncCache() = %v; want %v", c.name, err, c.kind)
+		}
+	}
+}
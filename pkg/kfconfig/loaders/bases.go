@@ -0,0 +1,142 @@
+package loaders
+
+import (
+	netUrl "net/url"
+	"path/filepath"
+	"reflect"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	"github.com/kubeflow/kfctl/v3/pkg/utils"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// mergeBases loads the bases of obj, the KfDef of version in configFile, and merges them
+// and then obj into one KfDef. Bases must have the same version as obj.
+func mergeBases(configFile string, obj map[string]interface{}, version string, bases []string,
+	options *loadOptions, visiting map[string]bool) (map[string]interface{}, error) {
+	var merged map[string]interface{}
+	for _, base := range bases {
+		baseFile, err := resolveBase(configFile, base)
+		if err != nil {
+			return nil, err
+		}
+		baseObj, baseVersion, err := loadKfDef(baseFile, options, visiting)
+		if err != nil {
+			return nil, kfapis.WrapKfError(err, "couldn't load base %v of %v", base, configFile)
+		}
+		if baseVersion != version {
+			return nil, kfapis.NewKfError(kfapis.ErrConfigInvalid,
+				"base %v is a KfDef %v but %v is a KfDef %v; convert it with kfctl alpha convert",
+				base, baseVersion, configFile, version)
+		}
+		if merged == nil {
+			merged = baseObj
+			continue
+		}
+		if merged, err = strategicMerge(merged, baseObj, version); err != nil {
+			return nil, kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "couldn't merge base %v of %v", base, configFile)
+		}
+	}
+
+	unstructured.RemoveNestedField(obj, "spec", "bases")
+	if merged == nil {
+		return obj, nil
+	}
+	merged, err := strategicMerge(merged, obj, version)
+	if err != nil {
+		return nil, kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "couldn't merge %v into its bases", configFile)
+	}
+	return merged, nil
+}
+
+// resolveBase returns the URI of base relative to configFile.
+func resolveBase(configFile string, base string) (string, error) {
+	if isRemote, err := utils.IsRemoteFile(base); err != nil || isRemote || filepath.IsAbs(base) {
+		return base, err
+	}
+	isRemote, err := utils.IsRemoteFile(configFile)
+	if err != nil {
+		return "", err
+	}
+	if !isRemote {
+		return filepath.Join(filepath.Dir(configFile), base), nil
+	}
+	configURL, err := netUrl.Parse(configFile)
+	if err != nil {
+		return "", err
+	}
+	baseURL, err := netUrl.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	return configURL.ResolveReference(baseURL).String(), nil
+}
+
+// strategicMerge merges overlay into base, two KfDefs of version, using the patch strategies
+// of the KfDef type, e.g. applications are merged by name. Like in a strategic merge patch
+// a null value removes a field and an item with "$patch: delete" removes the item.
+func strategicMerge(base map[string]interface{}, overlay map[string]interface{},
+	version string) (map[string]interface{}, error) {
+	meta, err := strategicpatch.NewPatchMetaFromStruct(kfDefTypes[version])
+	if err != nil {
+		return nil, err
+	}
+	return strategicpatch.StrategicMergeMapPatchUsingLookupPatchMeta(base, overlay, kfDefPatchMeta{meta})
+}
+
+// kfDefPatchMeta looks up the patch strategies of the fields of a KfDef. Fields without a
+// Go type, such as plugin specs, are merged like in a JSON merge patch.
+type kfDefPatchMeta struct {
+	strategicpatch.LookupPatchMeta
+}
+
+// untypedPatchMeta has no patch strategies: objects are merged and lists are replaced.
+type untypedPatchMeta struct{}
+
+var rawExtensionType = reflect.TypeOf(runtime.RawExtension{})
+
+func (m kfDefPatchMeta) LookupPatchMetadataForStruct(key string) (strategicpatch.LookupPatchMeta,
+	strategicpatch.PatchMeta, error) {
+	schema, patchMeta, err := m.LookupPatchMeta.LookupPatchMetadataForStruct(key)
+	return wrapPatchMeta(schema, patchMeta, err)
+}
+
+func (m kfDefPatchMeta) LookupPatchMetadataForSlice(key string) (strategicpatch.LookupPatchMeta,
+	strategicpatch.PatchMeta, error) {
+	schema, patchMeta, err := m.LookupPatchMeta.LookupPatchMetadataForSlice(key)
+	return wrapPatchMeta(schema, patchMeta, err)
+}
+
+func wrapPatchMeta(schema strategicpatch.LookupPatchMeta, patchMeta strategicpatch.PatchMeta,
+	err error) (strategicpatch.LookupPatchMeta, strategicpatch.PatchMeta, error) {
+	if err != nil {
+		// Fields unknown to the KfDef type, which non strict loading allows.
+		return untypedPatchMeta{}, strategicpatch.PatchMeta{}, nil
+	}
+	if s, ok := schema.(strategicpatch.PatchMetaFromStruct); ok {
+		t := s.T
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t == rawExtensionType || t.Kind() != reflect.Struct {
+			return untypedPatchMeta{}, patchMeta, nil
+		}
+	}
+	return kfDefPatchMeta{schema}, patchMeta, nil
+}
+
+func (untypedPatchMeta) LookupPatchMetadataForStruct(key string) (strategicpatch.LookupPatchMeta,
+	strategicpatch.PatchMeta, error) {
+	return untypedPatchMeta{}, strategicpatch.PatchMeta{}, nil
+}
+
+func (untypedPatchMeta) LookupPatchMetadataForSlice(key string) (strategicpatch.LookupPatchMeta,
+	strategicpatch.PatchMeta, error) {
+	return untypedPatchMeta{}, strategicpatch.PatchMeta{}, nil
+}
+
+func (untypedPatchMeta) Name() string {
+	return "untyped"
+}
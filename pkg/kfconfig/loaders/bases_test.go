@@ -0,0 +1,127 @@
+package loaders
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	"github.com/kubeflow/kfctl/v3/pkg/kfconfig"
+	kfutils "github.com/kubeflow/kfctl/v3/pkg/utils"
+)
+
+func TestLoadConfigFromURI_bases(t *testing.T) {
+	wd, _ := os.Getwd()
+	config, err := LoadConfigFromURI(path.Join(wd, "testdata", "bases", "team.yaml"))
+	if err != nil {
+		t.Fatalf("Error loading overlay: %v", err)
+	}
+
+	if config.Name != "kubeflow-team" || config.Namespace != "kubeflow" {
+		t.Errorf("Metadata wasn't merged: name %v, namespace %v", config.Name, config.Namespace)
+	}
+	expected := []kfconfig.Application{
+		{
+			Name: "istio",
+			KustomizeConfig: &kfconfig.KustomizeConfig{
+				RepoRef: &kfconfig.RepoRef{Name: "manifests", Path: "istio/istio"},
+				Parameters: []kfconfig.NameValue{
+					{Name: "clusterRbacConfig", Value: "OFF"},
+					{Name: "gatewaySelector", Value: "ingressgateway"},
+				},
+			},
+		},
+		{
+			Name: "jupyter-web-app",
+			KustomizeConfig: &kfconfig.KustomizeConfig{
+				RepoRef: &kfconfig.RepoRef{Name: "manifests", Path: "jupyter/jupyter-web-app"},
+				// Like in a strategic merge patch, the overlays added by team.yaml come first.
+				Overlays: []string{"application", "istio"},
+			},
+		},
+		{
+			Name: "katib",
+			KustomizeConfig: &kfconfig.KustomizeConfig{
+				RepoRef: &kfconfig.RepoRef{Name: "manifests", Path: "katib/katib-controller"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(config.Spec.Applications, expected) {
+		t.Errorf("Merged applications are\n%v\nwant\n%v", kfutils.PrettyPrint(config.Spec.Applications),
+			kfutils.PrettyPrint(expected))
+	}
+	if config.Spec.Project != "kubeflow-team" || config.Spec.Zone != "us-east1-d" {
+		t.Errorf("GCP plugin spec wasn't merged: project %v, zone %v", config.Spec.Project, config.Spec.Zone)
+	}
+	if len(config.Spec.Repos) != 1 || config.Spec.Repos[0].URI != "https://github.com/kubeflow/manifests/archive/v1.0.0.tar.gz" {
+		t.Errorf("Repos weren't merged by name: %v", config.Spec.Repos)
+	}
+}
+
+func TestLoadConfigFromURI_basesCycle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kfctl-bases-")
+	if err != nil {
+		t.Fatalf("Couldn't create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	for name, base := range map[string]string{"a.yaml": "b.yaml", "b.yaml": "a.yaml"} {
+		kfdef := "apiVersion: kfdef.apps.kubeflow.org/v1\nkind: KfDef\nspec:\n  bases:\n  - " + base + "\n"
+		if err := ioutil.WriteFile(path.Join(dir, name), []byte(kfdef), 0644); err != nil {
+			t.Fatalf("Couldn't write %v: %v", name, err)
+		}
+	}
+	if _, err := LoadConfigFromURI(path.Join(dir, "a.yaml")); !errors.Is(err, kfapis.ErrConfigInvalid) {
+		t.Errorf("Loading KfDefs that are bases of each other returned %v; want ErrConfigInvalid", err)
+	}
+}
+
+func TestWriteConfigToFile_bases(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kfctl-bases-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	wd, _ := os.Getwd()
+	for _, name := range []string{"base.yaml", "team.yaml"} {
+		data, err := ioutil.ReadFile(path.Join(wd, "testdata", "bases", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	team := path.Join(dir, "team.yaml")
+	before, err := ioutil.ReadFile(team)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Like kfctl apply, load the overlay and write the KfConfig back twice.
+	for i := 0; i < 2; i++ {
+		config, err := LoadConfigFromURI(team)
+		if err != nil {
+			t.Fatalf("Error loading overlay: %v", err)
+		}
+		if config.Spec.ConfigFileName != "team.merged.yaml" {
+			t.Errorf("ConfigFileName = %v; want team.merged.yaml", config.Spec.ConfigFileName)
+		}
+		if err := WriteConfigToFile(*config); err != nil {
+			t.Fatalf("WriteConfigToFile() failed: %v", err)
+		}
+	}
+	after, err := ioutil.ReadFile(team)
+	if err != nil || string(after) != string(before) {
+		t.Errorf("team.yaml was overwritten with\n%s\n%v; want its bases kept", after, err)
+	}
+	merged, err := LoadConfigFromURI(path.Join(dir, "team.merged.yaml"))
+	if err != nil {
+		t.Fatalf("Error loading the merged KfDef: %v", err)
+	}
+	if merged.Name != "kubeflow-team" || len(merged.Spec.Applications) != 3 {
+		t.Errorf("Merged KfDef has name %v and applications %v", merged.Name, merged.Spec.Applications)
+	}
+}
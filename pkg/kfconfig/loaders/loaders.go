@@ -1,6 +1,7 @@
 package loaders
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -8,14 +9,23 @@ import (
 	"io/ioutil"
 	netUrl "net/url"
 	"path"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/ghodss/yaml"
 	gogetter "github.com/hashicorp/go-getter"
 	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	kfdefv1 "github.com/kubeflow/kfctl/v3/pkg/apis/apps/kfdef/v1"
+	kfdefv1beta1 "github.com/kubeflow/kfctl/v3/pkg/apis/apps/kfdef/v1beta1"
+	kfdefv2 "github.com/kubeflow/kfctl/v3/pkg/apis/apps/kfdef/v2"
 	"github.com/kubeflow/kfctl/v3/pkg/kfconfig"
+	"github.com/kubeflow/kfctl/v3/pkg/kfconfig/awsplugin"
+	"github.com/kubeflow/kfctl/v3/pkg/kfconfig/gcpplugin"
 	"github.com/kubeflow/kfctl/v3/pkg/utils"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 type Loader interface {
@@ -23,10 +33,134 @@ type Loader interface {
 	LoadKfDef(config kfconfig.KfConfig, out interface{}) error
 }
 
+// converters are the loaders of the supported KfDef versions.
+var converters = map[string]Loader{
+	"v1alpha1": V1alpha1{},
+	"v1beta1":  V1beta1{},
+	"v1":       V1{},
+	"v2":       V2{},
+}
+
 const (
 	Api = "kfdef.apps.kubeflow.org"
 )
 
+// LoadOption customizes how LoadConfigFromURI builds the KfConfig.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	annotations map[string]string
+	v1alpha1    bool
+	vars        map[string]string
+	varFiles    []string
+	// unresolvedVars is set to keep variable references.
+	unresolvedVars bool
+	// merged is set by loadKfDef if the KfDef had bases.
+	merged bool
+}
+
+// WithAnnotations merges annotations into the metadata of the loaded KfConfig.
+// kfctl uses annotations to pass command line options to the KfApp implementations,
+// the same way the operator does for KfDef resources.
+func WithAnnotations(annotations map[string]string) LoadOption {
+	return func(o *loadOptions) {
+		if o.annotations == nil {
+			o.annotations = map[string]string{}
+		}
+		for k, v := range annotations {
+			o.annotations[k] = v
+		}
+	}
+}
+
+// WithOptions applies all opts.
+func WithOptions(opts ...LoadOption) LoadOption {
+	return func(o *loadOptions) {
+		for _, opt := range opts {
+			opt(o)
+		}
+	}
+}
+
+// WithV1alpha1 allows loading KfDef v1alpha1, which this binary can't deploy, e.g. to
+// convert it to a newer version.
+func WithV1alpha1() LoadOption {
+	return func(o *loadOptions) {
+		o.v1alpha1 = true
+	}
+}
+
+func newLoadOptions(opts []LoadOption) *loadOptions {
+	o := &loadOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// strict returns false if unknown fields should only be warnings. The annotation passed
+// with WithAnnotations takes precedence over the one in the metadata of the config.
+func (o *loadOptions) strict(obj map[string]interface{}) bool {
+	key := strings.Join([]string{utils.KfDefAnnotation, utils.Strict}, "/")
+	value, ok := o.annotations[key]
+	if !ok {
+		value, ok, _ = unstructured.NestedString(obj, "metadata", "annotations", key)
+	}
+	if !ok {
+		return true
+	}
+	strict, err := strconv.ParseBool(value)
+	return err != nil || strict
+}
+
+// kfDefTypes are the KfDef types configs are checked against for unknown fields.
+var kfDefTypes = map[string]interface{}{
+	"v1beta1": kfdefv1beta1.KfDef{},
+	"v1":      kfdefv1.KfDef{},
+	"v2":      kfdefv2.KfDef{},
+}
+
+// pluginTypes are the plugins whose spec is checked for unknown fields.
+var pluginTypes = utils.KindTypes{
+	string(kfconfig.GCP_PLUGIN_KIND): gcpplugin.KfGcpPlugin{},
+	string(kfconfig.AWS_PLUGIN_KIND): awsplugin.KfAwsPlugin{},
+}
+
+func init() {
+	// Plugin specs can hold secrets, e.g. the passwords of the AWS plugin.
+	utils.RegisterSecretKinds(pluginTypes)
+}
+
+// RedactKfDef replaces the secrets in kfdef, a KfDef of version decoded into maps and
+// slices, unless secrets are shown, and returns their paths.
+func RedactKfDef(kfdef interface{}, version string) []string {
+	kfDefType, ok := kfDefTypes[version]
+	if !ok {
+		return nil
+	}
+	return utils.RedactData(kfdef, kfDefType)
+}
+
+// checkUnknownFields reports the fields of configFile that the KfDef of version would
+// silently drop, with their path and line. They are errors if strict is set and warnings otherwise.
+func checkUnknownFields(configFile string, data []byte, version string, strict bool) error {
+	kfDefType, ok := kfDefTypes[version]
+	if !ok {
+		return nil
+	}
+	unknown, err := utils.UnknownFields(data, kfDefType, pluginTypes)
+	if err != nil {
+		return kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "invalid config file format")
+	}
+	if strict {
+		return utils.UnknownFieldsError(configFile, unknown)
+	}
+	for _, f := range unknown {
+		log.Warnf("%v: %v", configFile, f)
+	}
+	return nil
+}
+
 func isValidUrl(toTest string) bool {
 	_, err := netUrl.ParseRequestURI(toTest)
 	if err != nil {
@@ -41,11 +175,109 @@ func isValidUrl(toTest string) bool {
 // It will set the AppDir and ConfigFilename in kfconfig:
 //   AppDir = cwd if configFile is remote, or it will be the dir of configFile.
 //   ConfigFilename = the file name of configFile.
-func LoadConfigFromURI(configFile string) (*kfconfig.KfConfig, error) {
+func LoadConfigFromURI(configFile string, opts ...LoadOption) (*kfconfig.KfConfig, error) {
+	options := newLoadOptions(opts)
 	if configFile == "" {
 		return nil, fmt.Errorf("config file must be the URI of a KfDef spec")
 	}
 
+	isRemoteFile, err := utils.IsRemoteFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	obj, version, err := loadKfDef(configFile, options, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+	converter := converters[version]
+
+	kfconfig, err := converter.LoadKfConfig(obj)
+	if err != nil {
+		log.Errorf("Failed to convert kfdef to kfconfig: %v", err)
+		return nil, err
+	}
+
+	// Set the AppDir and ConfigFileName for kfconfig
+	if isRemoteFile {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, &kfapis.KfError{
+				Code:    int(kfapis.INTERNAL_ERROR),
+				Message: fmt.Sprintf("could not get current directory for KfDef %v", err),
+			}
+		}
+		kfconfig.Spec.AppDir = cwd
+	} else {
+		kfconfig.Spec.AppDir = filepath.Dir(configFile)
+	}
+	kfconfig.Spec.ConfigFileName = filepath.Base(configFile)
+	// Writing the merged KfDef over an overlay would drop its bases, so it's written next to it.
+	if options.merged {
+		kfconfig.Spec.ConfigFileName = mergedConfigFile(configFile)
+		log.Infof("%v has bases; writing the merged KfDef to %v", configFile, kfconfig.Spec.ConfigFileName)
+	}
+
+	if len(options.annotations) > 0 {
+		anns := kfconfig.GetAnnotations()
+		if anns == nil {
+			anns = map[string]string{}
+		}
+		// The annotations only pass the options of this run; record what the config had so
+		// that the KfDef written back doesn't keep them.
+		original := map[string]*string{}
+		for k, v := range options.annotations {
+			original[k] = nil
+			if old, ok := anns[k]; ok {
+				original[k] = &old
+			}
+			anns[k] = v
+		}
+		data, err := json.Marshal(original)
+		if err != nil {
+			return nil, kfapis.WrapKfError(err, "couldn't record the annotations of %v", configFile)
+		}
+		anns[runAnnotationsKey] = string(data)
+		kfconfig.SetAnnotations(anns)
+	}
+	return kfconfig, nil
+}
+
+// runAnnotationsKey is the annotation LoadConfigFromURI records the values the annotations
+// passed with WithAnnotations replaced in.
+var runAnnotationsKey = strings.Join([]string{utils.KfDefAnnotation, utils.RunAnnotations}, "/")
+
+// restoreAnnotations undoes the annotations passed with WithAnnotations, so that the
+// options of one run aren't written with the KfDef.
+func restoreAnnotations(config *kfconfig.KfConfig) {
+	anns := config.GetAnnotations()
+	data, ok := anns[runAnnotationsKey]
+	if !ok {
+		return
+	}
+	original := map[string]*string{}
+	if err := json.Unmarshal([]byte(data), &original); err != nil {
+		log.Warnf("Ignoring invalid annotation %v: %v", runAnnotationsKey, err)
+	}
+	restored := map[string]string{}
+	for k, v := range anns {
+		restored[k] = v
+	}
+	delete(restored, runAnnotationsKey)
+	for k, v := range original {
+		if v == nil {
+			delete(restored, k)
+			continue
+		}
+		restored[k] = *v
+	}
+	if len(restored) == 0 {
+		restored = nil
+	}
+	config.SetAnnotations(restored)
+}
+
+// readConfigFile returns the contents of configFile, a local file or a remote URI.
+func readConfigFile(configFile string) ([]byte, error) {
 	isRemoteFile, err := utils.IsRemoteFile(configFile)
 	if err != nil {
 		return nil, err
@@ -56,6 +288,10 @@ func LoadConfigFromURI(configFile string) (*kfconfig.KfConfig, error) {
 	appFile := configFile
 	// If config is remote, download it to a temp dir.
 	if isRemoteFile {
+		if u, err := netUrl.Parse(configFile); kfconfig.Offline() && (err != nil || u.Scheme != "file") {
+			return nil, kfapis.NewKfError(kfapis.ErrConfigInvalid,
+				"kfctl is offline and can't fetch %v; use a local copy or the KfDef of a bundle", configFile)
+		}
 		// TODO(jlewi): We should check if configFile doesn't specify a protocol or the protocol
 		// is file:// then we can just read it rather than fetching with go-getter.
 		appDir, err := ioutil.TempDir("", "")
@@ -102,91 +338,224 @@ func LoadConfigFromURI(configFile string) (*kfconfig.KfConfig, error) {
 			Message: fmt.Sprintf("could not read from config file %s: %v", configFile, err),
 		}
 	}
+	return configFileBytes, nil
+}
+
+// loadKfDef reads configFile, resolves its variables and merges it into its bases. It
+// returns the decoded KfDef and its version. visiting holds the KfDefs being loaded and
+// stops cycles of bases.
+func loadKfDef(configFile string, options *loadOptions, visiting map[string]bool) (map[string]interface{}, string, error) {
+	configFileBytes, err := readConfigFile(configFile)
+	if err != nil {
+		return nil, "", err
+	}
 
 	// Check API version.
 	var obj map[string]interface{}
 	if err = yaml.Unmarshal(configFileBytes, &obj); err != nil {
-		return nil, &kfapis.KfError{
-			Code:    int(kfapis.INVALID_ARGUMENT),
-			Message: fmt.Sprintf("invalid config file format: %v", err),
+		return nil, "", kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "invalid config file format")
+	}
+	// The KfConfig, and so the KfDef WriteConfigToFile persists, has the variables resolved.
+	if !options.unresolvedVars {
+		vars, err := options.variables()
+		if err != nil {
+			return nil, "", err
+		}
+		if err := resolveVariables(configFile, obj, vars); err != nil {
+			return nil, "", err
 		}
 	}
 	apiVersion, ok := obj["apiVersion"]
 	if !ok {
-		return nil, &kfapis.KfError{
-			Code:    int(kfapis.INVALID_ARGUMENT),
-			Message: "invalid config: apiVersion is not found.",
-		}
+		return nil, "", kfapis.NewKfError(kfapis.ErrConfigInvalid, "invalid config: apiVersion is not found.")
 	}
 	apiVersionSeparated := strings.Split(apiVersion.(string), "/")
 	if len(apiVersionSeparated) < 2 || apiVersionSeparated[0] != Api {
-		return nil, &kfapis.KfError{
-			Code:    int(kfapis.INVALID_ARGUMENT),
-			Message: fmt.Sprintf("invalid config: apiVersion must be in the format of %v/<version>, got %v", Api, apiVersion),
-		}
+		return nil, "", kfapis.NewKfError(kfapis.ErrConfigInvalid,
+			"invalid config: apiVersion must be in the format of %v/<version>, got %v", Api, apiVersion)
 	}
 
 	// Add this check because kfctl binary can not properly install Kubeflow using v1alpha1 configuration.
 	// See https://github.com/kubeflow/kubeflow/issues/4371.
-	if apiVersionSeparated[1] == "v1alpha1" {
-		return nil, &kfapis.KfError{
-			Code: int(kfapis.INVALID_ARGUMENT),
-			Message: fmt.Sprintf(
-				"KfDef version v1alpha1 is not supported by this binary. Please use configs at %s for to deploy Kubeflow 0.7 or use old kfctl at %s to deploy Kubeflow 0.6",
-				"https://github.com/kubeflow/manifests/tree/v0.7-branch/kfdef",
-				"https://github.com/kubeflow/kubeflow/releases/tag/v0.6.2",
-			)}
+	if apiVersionSeparated[1] == "v1alpha1" && !options.v1alpha1 {
+		return nil, "", kfapis.NewKfError(kfapis.ErrConfigInvalid,
+			"KfDef version v1alpha1 is not supported by this binary. Please use configs at %s for to deploy Kubeflow 0.7 or use old kfctl at %s to deploy Kubeflow 0.6",
+			"https://github.com/kubeflow/manifests/tree/v0.7-branch/kfdef",
+			"https://github.com/kubeflow/kubeflow/releases/tag/v0.6.2",
+		)
 	}
 
-	converters := map[string]Loader{
-		"v1alpha1": V1alpha1{},
-		"v1beta1":  V1beta1{},
-		"v1":       V1{},
+	if _, ok := converters[apiVersionSeparated[1]]; !ok {
+		return nil, "", kfapis.NewKfError(kfapis.ErrConfigInvalid,
+			"invalid config: version not supported; supported versions: %v, got %v",
+			strings.Join(Versions(), ", "), apiVersionSeparated[1])
 	}
 
-	converter, ok := converters[apiVersionSeparated[1]]
+	if err := checkUnknownFields(configFile, configFileBytes, apiVersionSeparated[1], options.strict(obj)); err != nil {
+		return nil, "", err
+	}
+
+	if bases, ok, _ := unstructured.NestedStringSlice(obj, "spec", "bases"); ok {
+		if visiting[configFile] {
+			return nil, "", kfapis.NewKfError(kfapis.ErrConfigInvalid, "%v is a base of itself", configFile)
+		}
+		visiting[configFile] = true
+		defer delete(visiting, configFile)
+		options.merged = true
+		obj, err = mergeBases(configFile, obj, apiVersionSeparated[1], bases, options, visiting)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	return obj, apiVersionSeparated[1], nil
+}
+
+// mergedConfigFile returns the name of the file the KfDef merged from configFile and its bases
+// is written to, e.g. team.merged.yaml for team.yaml.
+func mergedConfigFile(configFile string) string {
+	name := filepath.Base(configFile)
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + ".merged" + ext
+}
+
+func isCwdEmpty() string {
+	cwd, _ := os.Getwd()
+	files, _ := ioutil.ReadDir(cwd)
+	if len(files) > 1 {
+		return ""
+	}
+	return cwd
+}
+
+// ConvertKfDef returns config as a KfDef of version, e.g. v2. Conversions between the
+// versions don't lose anything except literal secrets, which are never written.
+func ConvertKfDef(config kfconfig.KfConfig, version string) (interface{}, error) {
+	converter, ok := converters[version]
 	if !ok {
-		versions := []string{}
-		for key := range converters {
-			versions = append(versions, key)
+		return nil, kfapis.NewKfError(kfapis.ErrConfigInvalid, "KfDef version %v is not supported; supported versions: %v",
+			version, strings.Join(Versions(), ", "))
+	}
+	config.APIVersion = Api + "/" + version
+	restoreAnnotations(&config)
+	var kfdef interface{}
+	if err := converter.LoadKfDef(config, &kfdef); err != nil {
+		return nil, err
+	}
+	return dropEmpty(kfdef), nil
+}
+
+// dropEmpty removes null values and empty objects, e.g. the null creationTimestamp and
+// empty status of a KfDef, from the maps in v.
+func dropEmpty(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, child := range value {
+			child = dropEmpty(child)
+			if m, ok := child.(map[string]interface{}); child == nil || ok && len(m) == 0 {
+				delete(value, k)
+				continue
+			}
+			value[k] = child
 		}
-		return nil, &kfapis.KfError{
-			Code: int(kfapis.INVALID_ARGUMENT),
-			Message: fmt.Sprintf("invalid config: version not supported; supported versions: %v, got %v",
-				strings.Join(versions, ", "), apiVersionSeparated[1]),
+	case []interface{}:
+		for i, child := range value {
+			value[i] = dropEmpty(child)
 		}
 	}
+	return v
+}
 
-	kfconfig, err := converter.LoadKfConfig(obj)
+// UnrepresentableFields returns the paths of the fields of the KfDef config was loaded from
+// that are lost when config is converted to version and back. Literal secrets are included
+// because they are never written.
+func UnrepresentableFields(config kfconfig.KfConfig, version string) ([]string, error) {
+	from := strings.TrimPrefix(config.APIVersion, Api+"/")
+	if _, ok := converters[from]; !ok {
+		return nil, kfapis.NewKfError(kfapis.ErrConfigInvalid, "KfDef version %v is not supported; supported versions: %v",
+			from, strings.Join(Versions(), ", "))
+	}
+	before, err := ConvertKfDef(config, from)
+	if err != nil {
+		return nil, err
+	}
+	converted, err := ConvertKfDef(config, version)
+	if err != nil {
+		return nil, err
+	}
+	back, err := converters[version].LoadKfConfig(converted)
+	if err != nil {
+		return nil, err
+	}
+	after, err := ConvertKfDef(*back, from)
 	if err != nil {
-		log.Errorf("Failed to convert kfdef to kfconfig: %v", err)
 		return nil, err
 	}
 
-	// Set the AppDir and ConfigFileName for kfconfig
-	if isRemoteFile {
-		cwd, err := os.Getwd()
-		if err != nil {
-			return nil, &kfapis.KfError{
-				Code:    int(kfapis.INTERNAL_ERROR),
-				Message: fmt.Sprintf("could not get current directory for KfDef %v", err),
+	lost := lostFields("", before, after)
+	for i, secret := range config.Spec.Secrets {
+		if secret.SecretSource != nil && secret.SecretSource.LiteralSource != nil {
+			lost = append(lost, fmt.Sprintf("spec.secrets[%v].secretSource.literalSource", i))
+		}
+	}
+	return lost, nil
+}
+
+// lostFields returns the paths of the values of before that after doesn't have.
+func lostFields(path string, before interface{}, after interface{}) []string {
+	switch b := before.(type) {
+	case map[string]interface{}:
+		a, ok := after.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := []string{}
+		for k := range b {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		lost := []string{}
+		for _, k := range keys {
+			child := k
+			if path != "" {
+				child = path + "." + k
+			}
+			if _, ok := a[k]; !ok {
+				lost = append(lost, child)
+				continue
 			}
+			lost = append(lost, lostFields(child, b[k], a[k])...)
 		}
-		kfconfig.Spec.AppDir = cwd
-	} else {
-		kfconfig.Spec.AppDir = filepath.Dir(configFile)
+		return lost
+	case []interface{}:
+		a, ok := after.([]interface{})
+		if !ok {
+			break
+		}
+		lost := []string{}
+		for i := range b {
+			child := fmt.Sprintf("%v[%v]", path, i)
+			if i >= len(a) {
+				lost = append(lost, child)
+				continue
+			}
+			lost = append(lost, lostFields(child, b[i], a[i])...)
+		}
+		return lost
 	}
-	kfconfig.Spec.ConfigFileName = filepath.Base(configFile)
-	return kfconfig, nil
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+	return []string{path}
 }
 
-func isCwdEmpty() string {
-	cwd, _ := os.Getwd()
-	files, _ := ioutil.ReadDir(cwd)
-	if len(files) > 1 {
-		return ""
+// Versions returns the supported KfDef versions.
+func Versions() []string {
+	versions := []string{}
+	for key := range converters {
+		versions = append(versions, key)
 	}
-	return cwd
+	sort.Strings(versions)
+	return versions
 }
 
 func WriteConfigToFile(config kfconfig.KfConfig) error {
@@ -203,11 +572,7 @@ func WriteConfigToFile(config kfconfig.KfConfig) error {
 		}
 	}
 	filename := filepath.Join(config.Spec.AppDir, config.Spec.ConfigFileName)
-	converters := map[string]Loader{
-		"v1alpha1": V1alpha1{},
-		"v1beta1":  V1beta1{},
-		"v1":       V1{},
-	}
+	restoreAnnotations(&config)
 	apiVersionSeparated := strings.Split(config.APIVersion, "/")
 	if len(apiVersionSeparated) < 2 || apiVersionSeparated[0] != Api {
 		return &kfapis.KfError{
@@ -231,6 +596,10 @@ func WriteConfigToFile(config kfconfig.KfConfig) error {
 			Message: fmt.Sprintf("error when loading KfDef: %v", err),
 		}
 	}
+	if redacted := RedactKfDef(kfdef, apiVersionSeparated[1]); len(redacted) > 0 {
+		log.Warnf("Redacted %v in %v; kfctl can't use these secrets from %v anymore, run with --show-secrets to keep them",
+			strings.Join(redacted, ", "), filename, filename)
+	}
 	kfdefBytes, err := yaml.Marshal(kfdef)
 	if err != nil {
 		return &kfapis.KfError{
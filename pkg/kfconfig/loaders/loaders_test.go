@@ -1,9 +1,18 @@
 package loaders
 
 import (
+	"errors"
+	"io/ioutil"
 	"os"
 	"path"
+	"reflect"
+	"strings"
 	"testing"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	kfconfigtypes "github.com/kubeflow/kfctl/v3/pkg/kfconfig"
+	"github.com/kubeflow/kfctl/v3/pkg/kfconfig/gcpplugin"
+	"github.com/kubeflow/kfctl/v3/pkg/utils"
 )
 
 // Make sure literal secrets are keeped during load kfdef -> kfconfig
@@ -18,3 +27,126 @@ func Test_loadKfdefLiteralSecrets(t *testing.T) {
 	}
 
 }
+
+func Test_loadKfdefUnknownFields(t *testing.T) {
+	wd, _ := os.Getwd()
+	configFile := path.Join(wd, "testdata", "unknown_fields.yaml")
+	_, err := LoadConfigFromURI(configFile)
+	if !errors.Is(err, kfapis.ErrConfigInvalid) {
+		t.Fatalf("Loading a config with unknown fields returned %v; want ErrConfigInvalid", err)
+	}
+	for _, field := range []string{
+		"line 10: unknown field spec.applications[0].kustomizeConfig.overlay",
+		"line 21: unknown field spec.plugins[0].spec.zonee",
+	} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("Error %v doesn't report %v", err, field)
+		}
+	}
+
+	annotations := map[string]string{strings.Join([]string{utils.KfDefAnnotation, utils.Strict}, "/"): "false"}
+	kfconfig, err := LoadConfigFromURI(configFile, WithAnnotations(annotations))
+	if err != nil {
+		t.Fatalf("Loading with strict=false failed: %v", err)
+	}
+	if kfconfig.Strict() {
+		t.Errorf("KfConfig loaded with strict=false is strict")
+	}
+	spec := gcpplugin.GcpPluginSpec{}
+	if err := kfconfig.GetPluginSpec(kfconfigtypes.GCP_PLUGIN_KIND, &spec); err != nil {
+		t.Errorf("GetPluginSpec with strict=false failed: %v", err)
+	}
+}
+
+func Test_UnrepresentableFields(t *testing.T) {
+	type testCase struct {
+		Input    string
+		Version  string
+		Expected []string
+	}
+	cases := []testCase{
+		{
+			Input:    "v2.yaml",
+			Version:  "v1",
+			Expected: []string{},
+		},
+		{
+			Input:   "v2.yaml",
+			Version: "v1alpha1",
+			Expected: []string{
+				"spec.applications[0].namespace",
+				"spec.applications[0].version",
+				"spec.applications[1].dependsOn",
+				"spec.applications[1].labels",
+				"spec.applications[2].enabled",
+				"spec.secrets[1].secretSource",
+				"spec.secrets[2].secretSource",
+				"spec.secrets[3].secretSource",
+				"spec.secrets[4].secretSource",
+			},
+		},
+		{
+			Input:    "kfctl_gcp_basic_auth.0.7.0.yaml",
+			Version:  "v2",
+			Expected: []string{"spec.secrets[0].secretSource.literalSource"},
+		},
+	}
+
+	wd, _ := os.Getwd()
+	for _, c := range cases {
+		config, err := LoadConfigFromURI(path.Join(wd, "testdata", c.Input))
+		if err != nil {
+			t.Fatalf("Error loading %v: %v", c.Input, err)
+		}
+		lost, err := UnrepresentableFields(*config, c.Version)
+		if err != nil {
+			t.Fatalf("Error converting %v to %v: %v", c.Input, c.Version, err)
+		}
+		if strings.Join(lost, ",") != strings.Join(c.Expected, ",") {
+			t.Errorf("Converting %v to %v loses %v; want %v", c.Input, c.Version, lost, c.Expected)
+		}
+	}
+}
+
+func TestWriteConfigToFile_runAnnotations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kfctl-annotations-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	wd, _ := os.Getwd()
+	data, err := ioutil.ReadFile(path.Join(wd, "testdata", "v1.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	configFile := path.Join(dir, "app.yaml")
+	if err := ioutil.WriteFile(configFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Like kfctl apply --app katib --strict=false, pass flags as annotations and write the config.
+	key := func(name string) string { return strings.Join([]string{utils.KfDefAnnotation, name}, "/") }
+	config, err := LoadConfigFromURI(configFile, WithAnnotations(map[string]string{
+		key(utils.Apps):   "katib",
+		key(utils.Prune):  "true",
+		key(utils.Strict): "false",
+		"testAnnotation":  "changed",
+	}))
+	if err != nil {
+		t.Fatalf("Error loading config: %v", err)
+	}
+	if config.GetAnnotations()[key(utils.Apps)] != "katib" {
+		t.Errorf("Annotations = %v; want the passed ones", config.GetAnnotations())
+	}
+	if err := WriteConfigToFile(*config); err != nil {
+		t.Fatalf("WriteConfigToFile() failed: %v", err)
+	}
+	written, err := LoadConfigFromURI(configFile)
+	if err != nil {
+		t.Fatalf("Error loading the written config: %v", err)
+	}
+	expected := map[string]string{"testAnnotation": "dummy"}
+	if !reflect.DeepEqual(written.GetAnnotations(), expected) {
+		t.Errorf("Written annotations = %v; want %v", written.GetAnnotations(), expected)
+	}
+}
@@ -0,0 +1,129 @@
+package loaders
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	"gopkg.in/yaml.v3"
+)
+
+// SetRepoDigests sets the sha256 of the repos of the KfDef in data, a YAML or JSON document,
+// to digests, which maps repo names to hex digests. Everything else, including comments and
+// variable references, is kept. It returns the names of the repos data doesn't list, e.g.
+// because they come from a base.
+func SetRepoDigests(data []byte, digests map[string]string) ([]byte, []string, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		return setRepoDigestsJSON(data, digests)
+	}
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, nil, kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "couldn't parse KfDef")
+	}
+	set := map[string]bool{}
+	if len(doc.Content) > 0 {
+		repos := mappingValue(mappingValue(doc.Content[0], "spec"), "repos")
+		if repos != nil && repos.Kind == yaml.SequenceNode {
+			for _, repo := range repos.Content {
+				name := mappingValue(repo, "name")
+				if name == nil {
+					continue
+				}
+				digest, ok := digests[name.Value]
+				if !ok {
+					continue
+				}
+				setMappingValue(repo, "sha256", digest, "uri")
+				set[name.Value] = true
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), missingRepos(digests, set), nil
+}
+
+func setRepoDigestsJSON(data []byte, digests map[string]string) ([]byte, []string, error) {
+	kfdef := map[string]interface{}{}
+	if err := json.Unmarshal(data, &kfdef); err != nil {
+		return nil, nil, kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "couldn't parse KfDef")
+	}
+	set := map[string]bool{}
+	spec, _ := kfdef["spec"].(map[string]interface{})
+	repos, _ := spec["repos"].([]interface{})
+	for _, r := range repos {
+		repo, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := repo["name"].(string)
+		if digest, ok := digests[name]; ok {
+			repo["sha256"] = digest
+			set[name] = true
+		}
+	}
+	updated, err := json.MarshalIndent(kfdef, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(updated, '\n'), missingRepos(digests, set), nil
+}
+
+func missingRepos(digests map[string]string, set map[string]bool) []string {
+	missing := []string{}
+	for name := range digests {
+		if !set[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// mappingValue returns the value of key in the mapping node or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue sets key of the mapping node to the string value. A new key is added
+// after the key after, or at the end if there is no such key.
+func setMappingValue(node *yaml.Node, key string, value string, after string) {
+	if v := mappingValue(node, key); v != nil {
+		v.Kind = yaml.ScalarNode
+		v.Tag = "!!str"
+		v.Value = value
+		v.Style = 0
+		v.Content = nil
+		return
+	}
+	pair := []*yaml.Node{
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+	}
+	at := len(node.Content)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == after {
+			at = i + 2
+		}
+	}
+	content := append([]*yaml.Node{}, node.Content[:at]...)
+	content = append(content, pair...)
+	node.Content = append(content, node.Content[at:]...)
+}
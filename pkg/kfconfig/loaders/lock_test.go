@@ -0,0 +1,86 @@
+package loaders
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetRepoDigests(t *testing.T) {
+	type testCase struct {
+		name     string
+		input    string
+		expected string
+		missing  []string
+	}
+	digests := map[string]string{"manifests": "0123", "extra": "4567"}
+	testCases := []testCase{
+		{
+			name: "yaml",
+			input: `apiVersion: kfdef.apps.kubeflow.org/v1
+kind: KfDef
+spec:
+  repos:
+  # The Kubeflow manifests.
+  - name: manifests
+    uri: https://github.com/kubeflow/manifests/archive/${VERSION}.tar.gz
+  - name: other
+    uri: https://example.com/other.tar.gz
+    sha256: ffff
+  - name: extra
+    sha256: old # pinned by hand
+    uri: https://example.com/extra.tar.gz
+`,
+			expected: `apiVersion: kfdef.apps.kubeflow.org/v1
+kind: KfDef
+spec:
+  repos:
+    # The Kubeflow manifests.
+    - name: manifests
+      uri: https://github.com/kubeflow/manifests/archive/${VERSION}.tar.gz
+      sha256: "0123"
+    - name: other
+      uri: https://example.com/other.tar.gz
+      sha256: ffff
+    - name: extra
+      sha256: "4567" # pinned by hand
+      uri: https://example.com/extra.tar.gz
+`,
+			missing: []string{},
+		},
+		{
+			name: "json",
+			input: `{"apiVersion": "kfdef.apps.kubeflow.org/v1", "kind": "KfDef",
+"spec": {"repos": [{"name": "manifests", "uri": "https://example.com/manifests.tar.gz"}]}}`,
+			expected: `{
+  "apiVersion": "kfdef.apps.kubeflow.org/v1",
+  "kind": "KfDef",
+  "spec": {
+    "repos": [
+      {
+        "name": "manifests",
+        "sha256": "0123",
+        "uri": "https://example.com/manifests.tar.gz"
+      }
+    ]
+  }
+}
+`,
+			missing: []string{"extra"},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, missing, err := SetRepoDigests([]byte(c.input), digests)
+			if err != nil {
+				t.Fatalf("SetRepoDigests() failed: %v", err)
+			}
+			if string(actual) != c.expected {
+				t.Errorf("SetRepoDigests() =\n%v\nwant\n%v", string(actual), c.expected)
+			}
+			if !reflect.DeepEqual(missing, c.missing) {
+				t.Errorf("missing repos = %v; want %v", missing, c.missing)
+			}
+		})
+	}
+}
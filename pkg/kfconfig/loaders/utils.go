@@ -1,8 +1,15 @@
 package loaders
 
 import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
 	kftypesv3 "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
 	"github.com/kubeflow/kfctl/v3/pkg/kfconfig"
+	"github.com/kubeflow/kfctl/v3/pkg/utils"
 )
 
 func maybeGetPlatform(pluginKind string) string {
@@ -19,3 +26,97 @@ func maybeGetPlatform(pluginKind string) string {
 		return ""
 	}
 }
+
+// appSettings are the per application settings a KfDef before v2 has no fields for.
+type appSettings struct {
+	Namespace string            `json:"namespace,omitempty"`
+	Enabled   *bool             `json:"enabled,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Version   string            `json:"version,omitempty"`
+	DependsOn []string          `json:"dependsOn,omitempty"`
+}
+
+func appSettingsAnnotation() string {
+	return strings.Join([]string{utils.KfDefAnnotation, utils.ApplicationSettings}, "/")
+}
+
+// saveAppSettings returns the annotations of a KfDef that can't represent the application
+// settings of config. The settings are kept in an annotation, by application name, so that
+// restoreAppSettings makes the conversion lossless. v1Fields is set if the KfDef has the
+// dependsOn and enabled fields of v1.
+func saveAppSettings(config kfconfig.KfConfig, v1Fields bool) (map[string]string, error) {
+	settings := map[string]appSettings{}
+	for _, app := range config.Spec.Applications {
+		s := appSettings{
+			Namespace: app.Namespace,
+			Labels:    app.Labels,
+			Version:   app.Version,
+		}
+		if !v1Fields {
+			s.Enabled = app.Enabled
+			s.DependsOn = app.DependsOn
+		}
+		if !reflect.DeepEqual(s, appSettings{}) {
+			settings[app.Name] = s
+		}
+	}
+	if len(settings) == 0 {
+		return config.Annotations, nil
+	}
+	value, err := json.Marshal(settings)
+	if err != nil {
+		return nil, &kfapis.KfError{
+			Code:    int(kfapis.INTERNAL_ERROR),
+			Message: fmt.Sprintf("could not marshal application settings: %v", err),
+		}
+	}
+	annotations := map[string]string{}
+	for k, v := range config.Annotations {
+		annotations[k] = v
+	}
+	annotations[appSettingsAnnotation()] = string(value)
+	return annotations, nil
+}
+
+// restoreAppSettings moves the application settings saved by saveAppSettings from the
+// annotations of config to its applications.
+func restoreAppSettings(config *kfconfig.KfConfig) error {
+	value, ok := config.Annotations[appSettingsAnnotation()]
+	if !ok {
+		return nil
+	}
+	settings := map[string]appSettings{}
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return &kfapis.KfError{
+			Code:    int(kfapis.INVALID_ARGUMENT),
+			Message: fmt.Sprintf("invalid annotation %v: %v", appSettingsAnnotation(), err),
+		}
+	}
+	annotations := map[string]string{}
+	for k, v := range config.Annotations {
+		if k != appSettingsAnnotation() {
+			annotations[k] = v
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	config.Annotations = annotations
+	for i := range config.Spec.Applications {
+		app := &config.Spec.Applications[i]
+		s, ok := settings[app.Name]
+		if !ok {
+			continue
+		}
+		app.Namespace = s.Namespace
+		app.Labels = s.Labels
+		app.Version = s.Version
+		if s.Enabled != nil {
+			app.Enabled = s.Enabled
+		}
+		if len(s.DependsOn) > 0 {
+			app.DependsOn = s.DependsOn
+		}
+	}
+	return nil
+}
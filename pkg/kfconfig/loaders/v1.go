@@ -53,7 +53,9 @@ func (v V1) LoadKfConfig(def interface{}) (*kfconfig.KfConfig, error) {
 			}
 		}
 		application := kfconfig.Application{
-			Name: app.Name,
+			Name:      app.Name,
+			DependsOn: app.DependsOn,
+			Enabled:   app.Enabled,
 		}
 		if app.KustomizeConfig != nil {
 			kconfig := &kfconfig.KustomizeConfig{
@@ -118,6 +120,10 @@ func (v V1) LoadKfConfig(def interface{}) (*kfconfig.KfConfig, error) {
 		s := kfconfig.Secret{
 			Name: secret.Name,
 		}
+		if secret.SecretSource == nil {
+			config.Spec.Secrets = append(config.Spec.Secrets, s)
+			continue
+		}
 		src := &kfconfig.SecretSource{}
 		// kfdef -> kfconfig should keep  literalSource , becasue only kfdef should be checked into source control,
 		// We only filter secrets during kfconfig -> kfdef.
@@ -131,14 +137,33 @@ func (v V1) LoadKfConfig(def interface{}) (*kfconfig.KfConfig, error) {
 				Name: secret.SecretSource.EnvSource.Name,
 			}
 		}
+		if secret.SecretSource.FileSource != nil {
+			fileSource := kfconfig.FileSource(*secret.SecretSource.FileSource)
+			src.FileSource = &fileSource
+		}
+		if secret.SecretSource.K8sSecretSource != nil {
+			k8sSecretSource := kfconfig.K8sSecretSource(*secret.SecretSource.K8sSecretSource)
+			src.K8sSecretSource = &k8sSecretSource
+		}
+		if secret.SecretSource.VaultSource != nil {
+			vaultSource := kfconfig.VaultSource(*secret.SecretSource.VaultSource)
+			src.VaultSource = &vaultSource
+		}
+		if secret.SecretSource.SopsSource != nil {
+			sopsSource := kfconfig.SopsSource(*secret.SecretSource.SopsSource)
+			src.SopsSource = &sopsSource
+		}
 		s.SecretSource = src
 		config.Spec.Secrets = append(config.Spec.Secrets, s)
 	}
 
 	for _, repo := range kfdef.Spec.Repos {
 		r := kfconfig.Repo{
-			Name: repo.Name,
-			URI:  repo.URI,
+			Name:    repo.Name,
+			URI:     repo.URI,
+			Sha256:  repo.Sha256,
+			Ref:     repo.Ref,
+			SubPath: repo.SubPath,
 		}
 		config.Spec.Repos = append(config.Spec.Repos, r)
 	}
@@ -158,10 +183,15 @@ func (v V1) LoadKfConfig(def interface{}) (*kfconfig.KfConfig, error) {
 		c := kfconfig.Cache{
 			Name:      cache.Name,
 			LocalPath: cache.LocalPath,
+			Digest:    cache.Digest,
+			Commit:    cache.Commit,
 		}
 		config.Status.Caches = append(config.Status.Caches, c)
 	}
 
+	if err := restoreAppSettings(config); err != nil {
+		return nil, err
+	}
 	return config, nil
 }
 
@@ -172,13 +202,19 @@ func (v V1) LoadKfDef(config kfconfig.KfConfig, out interface{}) error {
 	kfdef.APIVersion = config.APIVersion
 	kfdef.Kind = "KfDef"
 	kfdef.Labels = config.Labels
-	kfdef.Annotations = config.Annotations
+	annotations, err := saveAppSettings(config, true)
+	if err != nil {
+		return err
+	}
+	kfdef.Annotations = annotations
 	kfdef.ClusterName = config.ClusterName
 	kfdef.Spec.Version = config.Spec.Version
 
 	for _, app := range config.Spec.Applications {
 		application := kfdeftypes.Application{
-			Name: app.Name,
+			Name:      app.Name,
+			DependsOn: app.DependsOn,
+			Enabled:   app.Enabled,
 		}
 		if app.KustomizeConfig != nil {
 			kconfig := &kfdeftypes.KustomizeConfig{
@@ -224,14 +260,33 @@ func (v V1) LoadKfDef(config kfconfig.KfConfig, out interface{}) error {
 					Name: secret.SecretSource.EnvSource.Name,
 				}
 			}
+			if secret.SecretSource.FileSource != nil {
+				fileSource := kfdeftypes.FileSource(*secret.SecretSource.FileSource)
+				s.SecretSource.FileSource = &fileSource
+			}
+			if secret.SecretSource.K8sSecretSource != nil {
+				k8sSecretSource := kfdeftypes.K8sSecretSource(*secret.SecretSource.K8sSecretSource)
+				s.SecretSource.K8sSecretSource = &k8sSecretSource
+			}
+			if secret.SecretSource.VaultSource != nil {
+				vaultSource := kfdeftypes.VaultSource(*secret.SecretSource.VaultSource)
+				s.SecretSource.VaultSource = &vaultSource
+			}
+			if secret.SecretSource.SopsSource != nil {
+				sopsSource := kfdeftypes.SopsSource(*secret.SecretSource.SopsSource)
+				s.SecretSource.SopsSource = &sopsSource
+			}
 		}
 		kfdef.Spec.Secrets = append(kfdef.Spec.Secrets, s)
 	}
 
 	for _, repo := range config.Spec.Repos {
 		r := kfdeftypes.Repo{
-			Name: repo.Name,
-			URI:  repo.URI,
+			Name:    repo.Name,
+			URI:     repo.URI,
+			Sha256:  repo.Sha256,
+			Ref:     repo.Ref,
+			SubPath: repo.SubPath,
 		}
 		kfdef.Spec.Repos = append(kfdef.Spec.Repos, r)
 	}
@@ -252,6 +307,8 @@ func (v V1) LoadKfDef(config kfconfig.KfConfig, out interface{}) error {
 		c := kfdeftypes.RepoCache{
 			Name:      cache.Name,
 			LocalPath: cache.LocalPath,
+			Digest:    cache.Digest,
+			Commit:    cache.Commit,
 		}
 		kfdef.Status.ReposCache = append(kfdef.Status.ReposCache, c)
 	}
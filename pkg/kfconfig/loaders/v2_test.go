@@ -0,0 +1,101 @@
+package loaders
+
+import (
+	"io/ioutil"
+	"path"
+	"reflect"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/google/go-cmp/cmp"
+	"github.com/kubeflow/kfctl/v3/pkg/kfconfig"
+	kfutils "github.com/kubeflow/kfctl/v3/pkg/utils"
+)
+
+func loadTestKfDef(t *testing.T, name string) interface{} {
+	buf, err := ioutil.ReadFile(path.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Error reading file %v; error %v", name, err)
+	}
+	var obj interface{}
+	if err := yaml.Unmarshal(buf, &obj); err != nil {
+		t.Fatalf("Error when unmarshaling file %v; error %v", name, err)
+	}
+	return obj
+}
+
+func TestV2_LoadKfConfig(t *testing.T) {
+	config, err := V2{}.LoadKfConfig(loadTestKfDef(t, "v2.yaml"))
+	if err != nil {
+		t.Fatalf("Error converting to KfConfig: %v", err)
+	}
+	if config.Spec.Project != "my-project" || config.Spec.Zone != "us-east1-d" || config.Spec.Platform != "gcp" {
+		t.Errorf("GCP settings weren't read from the plugin: %v", kfutils.PrettyPrint(config.Spec))
+	}
+	apps := config.Spec.Applications
+	if apps[0].Namespace != "istio-system" || apps[0].Version != "1.1.6" {
+		t.Errorf("Application %v has namespace %q and version %q", apps[0].Name, apps[0].Namespace, apps[0].Version)
+	}
+	if apps[1].Labels["team"] != "pipelines" || !apps[1].IsEnabled() {
+		t.Errorf("Application %v has labels %v and enabled %v", apps[1].Name, apps[1].Labels, apps[1].IsEnabled())
+	}
+	if apps[2].IsEnabled() {
+		t.Errorf("Application %v should be disabled", apps[2].Name)
+	}
+}
+
+// TestV2_RoundTrip checks that converting a KfDef to another version and back doesn't lose anything.
+func TestV2_RoundTrip(t *testing.T) {
+	type testCase struct {
+		Input string
+		From  Loader
+		To    Loader
+	}
+	cases := []testCase{
+		{Input: "v1.yaml", From: V1{}, To: V2{}},
+		{Input: "v2.yaml", From: V2{}, To: V1{}},
+		{Input: "v2.yaml", From: V2{}, To: V1beta1{}},
+	}
+
+	for _, c := range cases {
+		expected, err := c.From.LoadKfConfig(loadTestKfDef(t, c.Input))
+		if err != nil {
+			t.Fatalf("Error converting %v to KfConfig: %v", c.Input, err)
+		}
+		var converted interface{}
+		if err := c.To.LoadKfDef(*expected, &converted); err != nil {
+			t.Fatalf("Error converting %v with %T: %v", c.Input, c.To, err)
+		}
+		config, err := c.To.LoadKfConfig(converted)
+		if err != nil {
+			t.Fatalf("Error loading %v converted with %T: %v", c.Input, c.To, err)
+		}
+		// The KfConfig keeps the version it was loaded from.
+		config.APIVersion = expected.APIVersion
+		// Literal secrets are never written to a KfDef.
+		for _, secret := range expected.Spec.Secrets {
+			secret.SecretSource.LiteralSource = nil
+		}
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("%v changed when converted with %T: %v", c.Input, c.To,
+				cmp.Diff(kfutils.PrettyPrint(expected), kfutils.PrettyPrint(config)))
+		}
+	}
+}
+
+func TestV2_AppSettingsAnnotation(t *testing.T) {
+	disabled := false
+	config := kfconfig.KfConfig{}
+	config.Spec.Applications = []kfconfig.Application{
+		{Name: "a", Namespace: "ns", Enabled: &disabled, Labels: map[string]string{"k": "v"}, Version: "1.0"},
+		{Name: "b"},
+	}
+	annotations, err := saveAppSettings(config, false)
+	if err != nil {
+		t.Fatalf("saveAppSettings failed: %v", err)
+	}
+	expected := `{"a":{"namespace":"ns","enabled":false,"labels":{"k":"v"},"version":"1.0"}}`
+	if annotations[appSettingsAnnotation()] != expected {
+		t.Errorf("annotation %v = %v; want %v", appSettingsAnnotation(), annotations[appSettingsAnnotation()], expected)
+	}
+}
@@ -0,0 +1,141 @@
+package loaders
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+)
+
+// variablePattern matches the variable references in KfDef values: ${NAME}, or
+// ${NAME:-default} to fall back to default if NAME isn't set. $${ is a literal ${.
+var variablePattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// WithVars sets variables referenced in the KfDef. They take precedence over the variable
+// files and the environment.
+func WithVars(vars map[string]string) LoadOption {
+	return func(o *loadOptions) {
+		if o.vars == nil {
+			o.vars = map[string]string{}
+		}
+		for k, v := range vars {
+			o.vars[k] = v
+		}
+	}
+}
+
+// WithVarFiles sets YAML files mapping the names of variables referenced in the KfDef to
+// their values. Later files take precedence; all take precedence over the environment.
+func WithVarFiles(files ...string) LoadOption {
+	return func(o *loadOptions) {
+		o.varFiles = append(o.varFiles, files...)
+	}
+}
+
+// WithUnresolvedVariables keeps the variable references in the KfDef as they are, e.g. to
+// convert a KfDef that is a template for several environments.
+func WithUnresolvedVariables() LoadOption {
+	return func(o *loadOptions) {
+		o.unresolvedVars = true
+	}
+}
+
+// variables returns the variables set by the options, without the environment.
+func (o *loadOptions) variables() (map[string]string, error) {
+	vars := map[string]string{}
+	for _, file := range o.varFiles {
+		fileVars, err := readVarFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range fileVars {
+			vars[k] = v
+		}
+	}
+	for k, v := range o.vars {
+		vars[k] = v
+	}
+	return vars, nil
+}
+
+func readVarFile(file string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "could not read variable file %v", file)
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "invalid variable file %v", file)
+	}
+	vars := map[string]string{}
+	for k, v := range values {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			return nil, kfapis.NewKfError(kfapis.ErrConfigInvalid, "invalid variable file %v: %v must be a string, number or bool",
+				file, k)
+		case nil:
+			vars[k] = ""
+		default:
+			vars[k] = fmt.Sprint(v)
+		}
+	}
+	return vars, nil
+}
+
+// resolveVariables replaces the variable references in the string values of obj, a decoded
+// KfDef, with the value of the variable in vars or else in the environment. All unresolved
+// references are returned as a single error with their path.
+func resolveVariables(configFile string, obj map[string]interface{}, vars map[string]string) error {
+	lookup := func(name string) (string, bool) {
+		if v, ok := vars[name]; ok {
+			return v, true
+		}
+		return os.LookupEnv(name)
+	}
+	unresolved := []string{}
+	for k, v := range obj {
+		obj[k] = substituteVariables(k, v, lookup, &unresolved)
+	}
+	if len(unresolved) == 0 {
+		return nil
+	}
+	sort.Strings(unresolved)
+	return kfapis.NewKfError(kfapis.ErrConfigInvalid,
+		"%v has unresolved variables; set them in the environment, with --var or in a --var-file: %v",
+		configFile, strings.Join(unresolved, "; "))
+}
+
+func substituteVariables(path string, value interface{}, lookup func(string) (string, bool),
+	unresolved *[]string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			v[k] = substituteVariables(path+"."+k, child, lookup, unresolved)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = substituteVariables(fmt.Sprintf("%v[%v]", path, i), child, lookup, unresolved)
+		}
+	case string:
+		return variablePattern.ReplaceAllStringFunc(v, func(ref string) string {
+			if strings.HasPrefix(ref, "$$") {
+				return ref[1:]
+			}
+			match := variablePattern.FindStringSubmatch(ref)
+			if value, ok := lookup(match[1]); ok {
+				return value
+			}
+			if match[2] != "" {
+				return match[3]
+			}
+			*unresolved = append(*unresolved, fmt.Sprintf("%v: %v", path, ref))
+			return ref
+		})
+	}
+	return value
+}
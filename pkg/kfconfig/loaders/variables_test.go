@@ -0,0 +1,67 @@
+package loaders
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+)
+
+func TestLoadConfigFromURI_variables(t *testing.T) {
+	wd, _ := os.Getwd()
+	configFile := path.Join(wd, "testdata", "variables.yaml")
+
+	_, err := LoadConfigFromURI(configFile)
+	if !errors.Is(err, kfapis.ErrConfigInvalid) {
+		t.Fatalf("Loading a config with unresolved variables returned %v; want ErrConfigInvalid", err)
+	}
+	for _, ref := range []string{
+		"metadata.name: ${KFCTL_TEST_ENV}",
+		"spec.applications[0].kustomizeConfig.parameters[0].value: ${KFCTL_TEST_HOSTNAME}",
+		"spec.repos[0].uri: ${KFCTL_TEST_REPO}",
+	} {
+		if !strings.Contains(err.Error(), ref) {
+			t.Errorf("Error %v doesn't report %v", err, ref)
+		}
+	}
+
+	varFile, err := ioutil.TempFile("", "kfctl-vars-")
+	if err != nil {
+		t.Fatalf("Couldn't create variable file: %v", err)
+	}
+	defer os.Remove(varFile.Name())
+	vars := "KFCTL_TEST_HOSTNAME: file.example.com\nKFCTL_TEST_REPO: https://example.com/manifests.tar.gz\n"
+	if _, err := varFile.WriteString(vars); err != nil {
+		t.Fatalf("Couldn't write variable file: %v", err)
+	}
+	varFile.Close()
+	os.Setenv("KFCTL_TEST_ENV", "dev")
+	os.Setenv("KFCTL_TEST_HOSTNAME", "env.example.com")
+	defer os.Unsetenv("KFCTL_TEST_ENV")
+	defer os.Unsetenv("KFCTL_TEST_HOSTNAME")
+
+	config, err := LoadConfigFromURI(configFile, WithVarFiles(varFile.Name()),
+		WithVars(map[string]string{"KFCTL_TEST_REPO": "https://example.com/override.tar.gz"}))
+	if err != nil {
+		t.Fatalf("Error loading %v: %v", configFile, err)
+	}
+	params := config.Spec.Applications[0].KustomizeConfig.Parameters
+	actual := []string{config.Name, params[0].Value, params[1].Value, config.Spec.Project, config.Spec.Repos[0].URI}
+	expected := []string{"kubeflow-dev", "file.example.com", "${NOT_A_VARIABLE}", "kubeflow-dev",
+		"https://example.com/override.tar.gz"}
+	if strings.Join(actual, " ") != strings.Join(expected, " ") {
+		t.Errorf("Resolved values are %v; want %v", actual, expected)
+	}
+
+	config, err = LoadConfigFromURI(configFile, WithUnresolvedVariables())
+	if err != nil {
+		t.Fatalf("Error loading %v with unresolved variables: %v", configFile, err)
+	}
+	if config.Spec.Repos[0].URI != "${KFCTL_TEST_REPO}" {
+		t.Errorf("Repo URI is %v; want the variable reference", config.Spec.Repos[0].URI)
+	}
+}
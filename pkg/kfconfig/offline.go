@@ -0,0 +1,181 @@
+package kfconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	offlineMu sync.RWMutex
+	// offline is set with SetOffline, e.g. by kfctl --offline.
+	offline bool
+	// offlineBundle is the directory of the extracted bundle repos are resolved from.
+	offlineBundle string
+)
+
+// SetOffline turns offline mode on or off for the whole process. Offline, configs and repos
+// are never fetched from the network; repos come from the bundle in bundleDir, if it isn't
+// empty, or the shared cache.
+func SetOffline(enabled bool, bundleDir string) {
+	offlineMu.Lock()
+	defer offlineMu.Unlock()
+	offline = enabled
+	offlineBundle = bundleDir
+}
+
+// Offline returns true if kfctl mustn't use the network.
+func Offline() bool {
+	offlineMu.RLock()
+	defer offlineMu.RUnlock()
+	return offline
+}
+
+// OfflineBundle returns the bundle dir of offline mode or "".
+func OfflineBundle() string {
+	offlineMu.RLock()
+	defer offlineMu.RUnlock()
+	return offlineBundle
+}
+
+// offlineSources returns the bundle and the shared cache offline repos are resolved from.
+// Either can be nil.
+func offlineSources() (*BundleIndex, *SharedCache, error) {
+	var index *BundleIndex
+	if dir := OfflineBundle(); dir != "" {
+		var err error
+		if index, err = ReadBundleIndex(dir); err != nil {
+			return nil, nil, err
+		}
+	}
+	shared, err := DefaultSharedCache()
+	if err != nil {
+		log.Warnf("Not using the shared cache: %v", err)
+	}
+	return index, shared, nil
+}
+
+// checkOffline fails with a list of all repos that neither are in the bundle nor the shared
+// cache, so that a missing repo is found before anything is fetched.
+func (c *KfConfig) checkOffline(baseCacheDir string) error {
+	index, shared, err := offlineSources()
+	if err != nil {
+		return err
+	}
+	missing := []string{}
+	for _, r := range c.Spec.Repos {
+		if _, err := os.Stat(filepath.Join(baseCacheDir, r.Name)); err == nil && c.isCached(r) {
+			continue
+		}
+		if fi, err := os.Stat(r.URI); err == nil && fi.Mode().IsDir() {
+			continue
+		}
+		if index != nil {
+			if _, ok := index.Find(r.URI, r.Digest()); ok {
+				continue
+			}
+		}
+		if shared != nil && r.IsArchive() {
+			_, ok, err := shared.Lookup(r.URI, r.Digest())
+			if err != nil {
+				return err
+			}
+			if ok {
+				continue
+			}
+		}
+		missing = append(missing, fmt.Sprintf("%v (%v)", r.Name, r.URI))
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return kfapis.NewKfError(kfapis.ErrConfigInvalid,
+		"kfctl is offline and repos %v aren't in %v; run kfctl alpha bundle create on a machine with network access and pass the bundle with --bundle",
+		strings.Join(missing, ", "), offlineSourcesString(index, shared))
+}
+
+func offlineSourcesString(index *BundleIndex, shared *SharedCache) string {
+	sources := []string{}
+	if index != nil {
+		sources = append(sources, "the bundle "+OfflineBundle())
+	}
+	if shared != nil {
+		sources = append(sources, "the shared cache "+shared.Dir)
+	}
+	if len(sources) == 0 {
+		return "any bundle or cache"
+	}
+	return strings.Join(sources, " or ")
+}
+
+// fetchOffline makes cacheDir hold the files of r from the bundle or the shared cache and
+// returns the digest of the archive of r, if it is one, and the commit of a git repo.
+func fetchOffline(r Repo, cacheDir string) (string, string, error) {
+	index, shared, err := offlineSources()
+	if err != nil {
+		return "", "", err
+	}
+	var bundled *BundleRepo
+	if index != nil {
+		if b, ok := index.Find(r.URI, r.Digest()); ok {
+			bundled = &b
+		}
+	}
+	missing := kfapis.NewKfError(kfapis.ErrConfigInvalid, "kfctl is offline and %v isn't in %v",
+		r.URI, offlineSourcesString(index, shared))
+
+	// Repos kfctl packed from a checkout or a dir are extracted as they are.
+	if bundled != nil && !bundled.Archive {
+		if err := os.MkdirAll(cacheDir, os.ModePerm); err != nil {
+			return "", "", errors.WithStack(err)
+		}
+		log.Infof("Using %v from the bundle", r.URI)
+		_, err := downloadArchive(index.Path(*bundled), bundled.Digest, cacheDir)
+		return "", bundled.Commit, err
+	}
+	if bundled == nil && !r.IsArchive() {
+		return "", "", missing
+	}
+
+	// Archives from the bundle are added to the shared cache under their URI so that other
+	// app dirs find them without the bundle.
+	digest := r.Digest()
+	fetch := func(dir string) (string, error) {
+		if bundled == nil {
+			return "", missing
+		}
+		log.Infof("Using %v from the bundle", r.URI)
+		return downloadArchive(index.Path(*bundled), bundled.Digest, dir)
+	}
+	if bundled != nil {
+		digest = bundled.Digest
+	} else if digest == "" && shared != nil {
+		// Offline, the archive of an unpinned URI fetched last is as recent as it gets.
+		entry, ok, err := shared.Lookup(r.URI, "")
+		if err != nil {
+			return "", "", err
+		}
+		if ok {
+			digest = entry.Digest
+		}
+	}
+	if shared == nil {
+		if err := os.MkdirAll(cacheDir, os.ModePerm); err != nil {
+			return "", "", errors.WithStack(err)
+		}
+		digest, err := fetch(cacheDir)
+		return digest, "", err
+	}
+	entry, err := shared.Get(r.URI, digest, fetch)
+	if err != nil {
+		return "", "", err
+	}
+	log.Infof("Using %v of %v from the shared cache", entry.Digest, r.URI)
+	return entry.Digest, "", shared.Link(entry, cacheDir)
+}
@@ -0,0 +1,104 @@
+package kfconfig
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSyncCache_offline(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "kfctl-offline-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDir)
+	defer os.Setenv(SharedCacheEnv, os.Getenv(SharedCacheEnv))
+	os.Setenv(SharedCacheEnv, filepath.Join(testDir, "shared"))
+	defer SetOffline(false, "")
+
+	tarball := testTarball(t, map[string]string{"kustomization.yaml": "resources: []\n"})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(tarball)
+	}))
+	uri := server.URL + "/manifests.tar.gz"
+	localRepo := filepath.Join(testDir, "local")
+	if err := os.MkdirAll(localRepo, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(localRepo, "kustomization.yaml"), []byte("resources: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repos := []Repo{{Name: "manifests", URI: uri}}
+
+	// Missing repos are all reported before anything is fetched.
+	SetOffline(true, "")
+	config := &KfConfig{
+		Spec: KfConfigSpec{
+			AppDir: filepath.Join(testDir, "missing"),
+			Repos:  append(repos, Repo{Name: "other", URI: server.URL + "/other.tar.gz"}),
+		},
+	}
+	err = config.SyncCache()
+	if err == nil || !strings.Contains(err.Error(), "manifests") || !strings.Contains(err.Error(), "other") {
+		t.Fatalf("SyncCache() = %v; want an error naming both repos", err)
+	}
+
+	// A bundle is staged online and pins the repos to their digests.
+	SetOffline(false, "")
+	bundleDir := filepath.Join(testDir, "bundle")
+	config = &KfConfig{Spec: KfConfigSpec{Repos: repos}}
+	index, err := config.StageBundle(bundleDir)
+	if err != nil {
+		t.Fatalf("StageBundle() failed: %v", err)
+	}
+	if err := index.Write(); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+	if len(index.Repos) != 1 || !index.Repos[0].Archive || config.Spec.Repos[0].Sha256 == "" {
+		t.Fatalf("StageBundle() = %+v, sha256 %q; want a pinned archive", index.Repos, config.Spec.Repos[0].Sha256)
+	}
+
+	// Offline, repos come from the bundle and then from the shared cache.
+	SetOffline(true, bundleDir)
+	for _, app := range []string{"app1", "app2"} {
+		if app == "app2" {
+			SetOffline(true, "")
+		}
+		config := &KfConfig{
+			Spec: KfConfigSpec{
+				AppDir: filepath.Join(testDir, app),
+				Repos:  append(repos, Repo{Name: "local", URI: localRepo}),
+			},
+		}
+		if err := config.SyncCache(); err != nil {
+			t.Fatalf("SyncCache() of %v failed: %v", app, err)
+		}
+		expected := filepath.Join(testDir, app, DefaultCacheDir, "manifests", "manifests-abc123")
+		if config.Status.Caches[0].LocalPath != expected {
+			t.Errorf("LocalPath = %v; want %v", config.Status.Caches[0].LocalPath, expected)
+		}
+		if _, err := os.Stat(filepath.Join(expected, "kustomization.yaml")); err != nil {
+			t.Errorf("kustomization.yaml of %v wasn't fetched: %v", app, err)
+		}
+	}
+}
+
+func TestReadBundleIndex_outside(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kfctl-bundle-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	index := `{"repos": [{"name": "manifests", "uri": "https://example.com/m.tar.gz", "file": "../../etc/passwd"}]}`
+	if err := ioutil.WriteFile(filepath.Join(dir, BundleIndexFile), []byte(index), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadBundleIndex(dir); err == nil {
+		t.Errorf("ReadBundleIndex() succeeded; want an error for a repo outside the bundle")
+	}
+}
@@ -0,0 +1,290 @@
+package kfconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	kftypesv3 "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
+	"github.com/kubeflow/kfctl/v3/pkg/utils"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// SecretProvider is implemented by every kind of secret source and returns the value of
+// the secret. c is the KfConfig the secret belongs to.
+type SecretProvider interface {
+	Secret(c *KfConfig) (string, error)
+}
+
+var (
+	// newKubeClient returns the client K8sSecretSource reads Secrets with.
+	newKubeClient = func() (kubernetes.Interface, error) {
+		config := kftypesv3.GetConfig()
+		if config == nil {
+			return nil, kfapis.NewKfError(kfapis.ErrClusterUnreachable, "couldn't load a kubeconfig")
+		}
+		return kubernetes.NewForConfig(config)
+	}
+
+	// vaultHTTPClient is the client VaultSource reads secrets with.
+	vaultHTTPClient = http.DefaultClient
+
+	// sopsCommand is the command SopsSource decrypts documents with.
+	sopsCommand = "sops"
+)
+
+// provider returns the source that is set or nil if there is none.
+func (s *SecretSource) provider() SecretProvider {
+	switch {
+	case s == nil:
+		return nil
+	case s.LiteralSource != nil:
+		return s.LiteralSource
+	case s.HashedSource != nil:
+		return s.HashedSource
+	case s.EnvSource != nil:
+		return s.EnvSource
+	case s.FileSource != nil:
+		return s.FileSource
+	case s.K8sSecretSource != nil:
+		return s.K8sSecretSource
+	case s.VaultSource != nil:
+		return s.VaultSource
+	case s.SopsSource != nil:
+		return s.SopsSource
+	}
+	return nil
+}
+
+// sources returns the names of the sources that are set.
+func (s *SecretSource) sources() []string {
+	sources := []string{}
+	for name, set := range map[string]bool{
+		"literalSource":   s.LiteralSource != nil,
+		"hashedSource":    s.HashedSource != nil,
+		"envSource":       s.EnvSource != nil,
+		"fileSource":      s.FileSource != nil,
+		"k8sSecretSource": s.K8sSecretSource != nil,
+		"vaultSource":     s.VaultSource != nil,
+		"sopsSource":      s.SopsSource != nil,
+	} {
+		if set {
+			sources = append(sources, name)
+		}
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+func (s *LiteralSource) Secret(c *KfConfig) (string, error) {
+	return s.Value, checkRedacted(s.Value)
+}
+
+func (s *HashedSource) Secret(c *KfConfig) (string, error) {
+	return s.HashedValue, checkRedacted(s.HashedValue)
+}
+
+func (s *EnvSource) Secret(c *KfConfig) (string, error) {
+	return os.Getenv(s.Name), nil
+}
+
+func (s *FileSource) Secret(c *KfConfig) (string, error) {
+	if s.Path == "" {
+		return "", kfapis.NewKfError(kfapis.ErrConfigInvalid, "fileSource has no path")
+	}
+	data, err := ioutil.ReadFile(c.appPath(s.Path))
+	if err != nil {
+		return "", kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "couldn't read secret file")
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+func (s *K8sSecretSource) Secret(c *KfConfig) (string, error) {
+	if s.Name == "" || s.Key == "" {
+		return "", kfapis.NewKfError(kfapis.ErrConfigInvalid, "k8sSecretSource needs a name and a key")
+	}
+	namespace := s.Namespace
+	if namespace == "" {
+		namespace = c.Namespace
+	}
+	client, err := newKubeClient()
+	if err != nil {
+		return "", kfapis.WrapKfErrorAs(kfapis.ErrClusterUnreachable, err, "couldn't create a Kubernetes client")
+	}
+	secret, err := client.CoreV1().Secrets(namespace).Get(s.Name, metav1.GetOptions{})
+	// Retrying doesn't create the Secret or grant access to it.
+	if k8serrors.IsNotFound(err) || k8serrors.IsForbidden(err) || k8serrors.IsUnauthorized(err) {
+		return "", kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "couldn't get Secret %v/%v", namespace, s.Name)
+	}
+	if err != nil {
+		return "", utils.ClusterError(err, kfapis.INTERNAL_ERROR, "couldn't get Secret %v/%v", namespace, s.Name)
+	}
+	value, ok := secret.Data[s.Key]
+	if !ok {
+		return "", kfapis.NewKfError(kfapis.ErrConfigInvalid, "Secret %v/%v has no key %v", namespace, s.Name, s.Key)
+	}
+	return string(value), nil
+}
+
+func (s *VaultSource) Secret(c *KfConfig) (string, error) {
+	address := s.Address
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if address == "" || s.Path == "" || s.Key == "" {
+		return "", kfapis.NewKfError(kfapis.ErrConfigInvalid,
+			"vaultSource needs a path, a key and an address or $VAULT_ADDR")
+	}
+	token, err := vaultToken()
+	if err != nil {
+		return "", err
+	}
+	mount := s.Mount
+	if mount == "" {
+		mount = "secret"
+	}
+	url := strings.TrimSuffix(address, "/") + "/v1/" + strings.Trim(mount, "/") + "/"
+	switch s.KVVersion {
+	case 0, 2:
+		url += "data/"
+	case 1:
+	default:
+		return "", kfapis.NewKfError(kfapis.ErrConfigInvalid, "vaultSource has unknown kvVersion %v", s.KVVersion)
+	}
+	url += strings.Trim(s.Path, "/")
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "invalid Vault address %v", address)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+	resp, err := vaultHTTPClient.Do(req)
+	if err != nil {
+		return "", kfapis.WrapKfErrorAs(kfapis.ErrClusterUnreachable, err, "couldn't read Vault secret %v", s.Path)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", kfapis.WrapKfError(err, "couldn't read Vault secret %v", s.Path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		vaultErr := struct {
+			Errors []string `json:"errors"`
+		}{}
+		_ = json.Unmarshal(body, &vaultErr)
+		// Missing secrets and denied tokens are configuration errors; only an unavailable
+		// Vault is worth retrying.
+		kind := kfapis.ErrConfigInvalid
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			kind = kfapis.ErrClusterUnreachable
+		}
+		return "", kfapis.NewKfError(kind, "couldn't read Vault secret %v: %v %v", s.Path, resp.Status,
+			strings.Join(vaultErr.Errors, "; "))
+	}
+
+	// KV version 2 nests the secret in data.data, version 1 in data.
+	secret := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", kfapis.WrapKfError(err, "invalid response for Vault secret %v", s.Path)
+	}
+	if s.KVVersion != 1 {
+		versioned := struct {
+			Data json.RawMessage `json:"data"`
+		}{}
+		if err := json.Unmarshal(secret.Data, &versioned); err != nil {
+			return "", kfapis.WrapKfError(err, "invalid response for Vault secret %v", s.Path)
+		}
+		secret.Data = versioned.Data
+	}
+	values := map[string]interface{}{}
+	if err := json.Unmarshal(secret.Data, &values); err != nil {
+		return "", kfapis.WrapKfError(err, "invalid response for Vault secret %v", s.Path)
+	}
+	value, ok := values[s.Key]
+	if !ok {
+		return "", kfapis.NewKfError(kfapis.ErrConfigInvalid, "Vault secret %v has no key %v", s.Path, s.Key)
+	}
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// vaultToken returns the token of the Vault CLI: $VAULT_TOKEN or else ~/.vault-token.
+func vaultToken() (string, error) {
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token, nil
+	}
+	home, err := os.UserHomeDir()
+	if err == nil {
+		if data, err := ioutil.ReadFile(filepath.Join(home, ".vault-token")); err == nil {
+			return strings.TrimSpace(string(data)), nil
+		}
+	}
+	return "", kfapis.NewKfError(kfapis.ErrConfigInvalid, "no Vault token; set $VAULT_TOKEN or run vault login")
+}
+
+func (s *SopsSource) Secret(c *KfConfig) (string, error) {
+	if s.Key == "" || (s.Path == "") == (s.Value == "") {
+		return "", kfapis.NewKfError(kfapis.ErrConfigInvalid, "sopsSource needs a key and either a path or a value")
+	}
+	args := []string{"--decrypt", "--extract", fmt.Sprintf("[%q]", s.Key)}
+	file := c.appPath(s.Path)
+	if s.Value != "" {
+		tmp, err := ioutil.TempFile("", "kfctl-sops-*.yaml")
+		if err != nil {
+			return "", kfapis.WrapKfError(err, "couldn't write SOPS document")
+		}
+		defer os.Remove(tmp.Name())
+		_, err = tmp.WriteString(s.Value)
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return "", kfapis.WrapKfError(err, "couldn't write SOPS document")
+		}
+		file = tmp.Name()
+		args = append(args, "--input-type", "yaml")
+	}
+	cmd := exec.Command(sopsCommand, append(args, file)...)
+	stderr := &strings.Builder{}
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "couldn't decrypt SOPS document: %v",
+			strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// checkRedacted fails if value is utils.RedactedValue, i.e. the secret was read from a config
+// kfctl wrote without --show-secrets.
+func checkRedacted(value string) error {
+	if value == utils.RedactedValue {
+		return kfapis.NewKfError(kfapis.ErrConfigInvalid,
+			"the secret is redacted; set it again or write the config with --show-secrets")
+	}
+	return nil
+}
+
+// appPath returns p relative to the app dir unless it's absolute.
+func (c *KfConfig) appPath(p string) string {
+	if p == "" || filepath.IsAbs(p) || c.Spec.AppDir == "" {
+		return p
+	}
+	return filepath.Join(c.Spec.AppDir, p)
+}
@@ -0,0 +1,307 @@
+package kfconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	"github.com/kubeflow/kfctl/v3/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestGetSecret_fileSource(t *testing.T) {
+	appDir, err := ioutil.TempDir("", "kfctl-secrets-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(appDir)
+	if err := ioutil.WriteFile(filepath.Join(appDir, "password"), []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &KfConfig{
+		Spec: KfConfigSpec{
+			AppDir: appDir,
+			Secrets: []Secret{
+				{Name: "password", SecretSource: &SecretSource{FileSource: &FileSource{Path: "password"}}},
+				{Name: "missing", SecretSource: &SecretSource{FileSource: &FileSource{Path: "missing"}}},
+			},
+		},
+	}
+	value, err := config.GetSecret("password")
+	if err != nil {
+		t.Fatalf("GetSecret() failed: %v", err)
+	}
+	if value != "s3cret" {
+		t.Errorf("GetSecret() = %q; want %q", value, "s3cret")
+	}
+	if _, err := config.GetSecret("missing"); err == nil {
+		t.Errorf("GetSecret() of a missing file didn't fail")
+	}
+}
+
+func TestGetSecret_redacted(t *testing.T) {
+	appDir, err := ioutil.TempDir("", "kfctl-secrets-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(appDir)
+	if err := ioutil.WriteFile(filepath.Join(appDir, "password"), []byte(utils.RedactedValue+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &KfConfig{
+		Spec: KfConfigSpec{
+			AppDir: appDir,
+			Secrets: []Secret{
+				{Name: "literal", SecretSource: &SecretSource{LiteralSource: &LiteralSource{Value: utils.RedactedValue}}},
+				{Name: "hashed", SecretSource: &SecretSource{HashedSource: &HashedSource{HashedValue: utils.RedactedValue}}},
+				{Name: "file", SecretSource: &SecretSource{FileSource: &FileSource{Path: "password"}}},
+			},
+		},
+	}
+	for _, s := range config.Spec.Secrets {
+		if value, err := config.GetSecret(s.Name); err == nil {
+			t.Errorf("GetSecret(%v) = %q; want an error for a redacted secret", s.Name, value)
+		}
+	}
+}
+
+func TestGetSecret_k8sSecretSource(t *testing.T) {
+	client := fake.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "oauth", Namespace: "kubeflow"},
+		Data:       map[string][]byte{"client-secret": []byte("s3cret")},
+	})
+	defer func(f func() (kubernetes.Interface, error)) { newKubeClient = f }(newKubeClient)
+	newKubeClient = func() (kubernetes.Interface, error) { return client, nil }
+
+	config := &KfConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kubeflow"},
+		Spec: KfConfigSpec{
+			Secrets: []Secret{
+				{Name: "oauth", SecretSource: &SecretSource{
+					K8sSecretSource: &K8sSecretSource{Name: "oauth", Key: "client-secret"},
+				}},
+				{Name: "missing-key", SecretSource: &SecretSource{
+					K8sSecretSource: &K8sSecretSource{Name: "oauth", Namespace: "kubeflow", Key: "password"},
+				}},
+				{Name: "missing-secret", SecretSource: &SecretSource{
+					K8sSecretSource: &K8sSecretSource{Name: "missing", Key: "password"},
+				}},
+				{Name: "forbidden", SecretSource: &SecretSource{
+					K8sSecretSource: &K8sSecretSource{Name: "oauth", Namespace: "istio-system", Key: "client-secret"},
+				}},
+				{Name: "unavailable", SecretSource: &SecretSource{
+					K8sSecretSource: &K8sSecretSource{Name: "oauth", Namespace: "unavailable", Key: "client-secret"},
+				}},
+			},
+		},
+	}
+	value, err := config.GetSecret("oauth")
+	if err != nil {
+		t.Fatalf("GetSecret() failed: %v", err)
+	}
+	if value != "s3cret" {
+		t.Errorf("GetSecret() = %q; want %q", value, "s3cret")
+	}
+	_, err = config.GetSecret("missing-key")
+	if err == nil || !strings.Contains(err.Error(), "Secret kubeflow/oauth has no key password") {
+		t.Errorf("GetSecret() error = %v; want a missing key error", err)
+	}
+
+	// Only errors reaching the cluster are worth retrying.
+	client.PrependReactor("get", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		resource := schema.GroupResource{Resource: "secrets"}
+		switch action.GetNamespace() {
+		case "istio-system":
+			return true, nil, k8serrors.NewForbidden(resource, "oauth", errors.New("RBAC denied"))
+		case "unavailable":
+			return true, nil, k8serrors.NewServiceUnavailable("etcd is down")
+		}
+		return false, nil, nil
+	})
+	for name, kind := range map[string]error{
+		"missing-secret": kfapis.ErrConfigInvalid,
+		"forbidden":      kfapis.ErrConfigInvalid,
+		"unavailable":    kfapis.ErrClusterUnreachable,
+	} {
+		if _, err := config.GetSecret(name); !errors.Is(err, kind) {
+			t.Errorf("GetSecret(%v) error = %v; want %v", name, err, kind)
+		}
+	}
+}
+
+func TestGetSecret_vaultSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(map[string][]string{"errors": {"permission denied"}})
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/kubeflow/sealed":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_ = json.NewEncoder(w).Encode(map[string][]string{"errors": {"Vault is sealed"}})
+		case "/v1/secret/data/kubeflow/oauth":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"data":     map[string]interface{}{"client-secret": "s3cret"},
+					"metadata": map[string]interface{}{"version": 3},
+				},
+			})
+		case "/v1/kv/kubeflow/oauth":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"client-secret": "v1-s3cret"},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string][]string{"errors": {}})
+		}
+	}))
+	defer server.Close()
+	defer os.Setenv("VAULT_TOKEN", os.Getenv("VAULT_TOKEN"))
+
+	type testCase struct {
+		name         string
+		token        string
+		source       VaultSource
+		expected     string
+		expectedErr  string
+		expectedKind error
+	}
+	testCases := []testCase{
+		{
+			name:     "kv v2",
+			token:    "root",
+			source:   VaultSource{Address: server.URL, Path: "kubeflow/oauth", Key: "client-secret"},
+			expected: "s3cret",
+		},
+		{
+			name:     "kv v1",
+			token:    "root",
+			source:   VaultSource{Address: server.URL, Mount: "kv", Path: "kubeflow/oauth", Key: "client-secret", KVVersion: 1},
+			expected: "v1-s3cret",
+		},
+		{
+			name:        "missing key",
+			token:       "root",
+			source:      VaultSource{Address: server.URL, Path: "kubeflow/oauth", Key: "password"},
+			expectedErr: "Vault secret kubeflow/oauth has no key password",
+		},
+		{
+			name:         "missing secret",
+			token:        "root",
+			source:       VaultSource{Address: server.URL, Path: "kubeflow/missing", Key: "password"},
+			expectedErr:  "404 Not Found",
+			expectedKind: kfapis.ErrConfigInvalid,
+		},
+		{
+			name:         "permission denied",
+			token:        "guest",
+			source:       VaultSource{Address: server.URL, Path: "kubeflow/oauth", Key: "client-secret"},
+			expectedErr:  "403 Forbidden permission denied",
+			expectedKind: kfapis.ErrConfigInvalid,
+		},
+		{
+			name:         "sealed",
+			token:        "root",
+			source:       VaultSource{Address: server.URL, Path: "kubeflow/sealed", Key: "client-secret"},
+			expectedErr:  "503 Service Unavailable Vault is sealed",
+			expectedKind: kfapis.ErrClusterUnreachable,
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			os.Setenv("VAULT_TOKEN", c.token)
+			source := c.source
+			config := &KfConfig{
+				Spec: KfConfigSpec{
+					Secrets: []Secret{{Name: "oauth", SecretSource: &SecretSource{VaultSource: &source}}},
+				},
+			}
+			value, err := config.GetSecret("oauth")
+			if c.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.expectedErr) {
+					t.Fatalf("GetSecret() error = %v; want %v", err, c.expectedErr)
+				}
+				if c.expectedKind != nil && !errors.Is(err, c.expectedKind) {
+					t.Errorf("GetSecret() error = %v; want %v", err, c.expectedKind)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetSecret() failed: %v", err)
+			}
+			if value != c.expected {
+				t.Errorf("GetSecret() = %q; want %q", value, c.expected)
+			}
+		})
+	}
+}
+
+func TestGetSecret_sopsSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kfctl-sops-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// The stand-in for sops prints its arguments and the document.
+	sops := filepath.Join(dir, "sops")
+	script := "#!/bin/sh\nfor last; do :; done\necho \"$*\"\ncat \"$last\"\n"
+	if err := ioutil.WriteFile(sops, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	defer func(c string) { sopsCommand = c }(sopsCommand)
+	sopsCommand = sops
+
+	config := &KfConfig{
+		Spec: KfConfigSpec{
+			Secrets: []Secret{
+				{Name: "password", SecretSource: &SecretSource{
+					SopsSource: &SopsSource{Value: "password: ENC[AES256_GCM,data:...]", Key: "password"},
+				}},
+			},
+		},
+	}
+	value, err := config.GetSecret("password")
+	if err != nil {
+		t.Fatalf("GetSecret() failed: %v", err)
+	}
+	lines := strings.Split(value, "\n")
+	if !strings.HasPrefix(lines[0], `--decrypt --extract ["password"] --input-type yaml `) {
+		t.Errorf("sops arguments = %v", lines[0])
+	}
+	if lines[1] != "password: ENC[AES256_GCM,data:...]" {
+		t.Errorf("sops document = %v", lines[1])
+	}
+}
+
+func TestValidate_secretSources(t *testing.T) {
+	config := &KfConfig{
+		Spec: KfConfigSpec{
+			Secrets: []Secret{{Name: "password", SecretSource: &SecretSource{
+				EnvSource:  &EnvSource{Name: "PASSWORD"},
+				FileSource: &FileSource{Path: "password"},
+			}}},
+		},
+	}
+	errs := config.Validate()
+	if len(errs) != 1 || errs[0].Field != "spec.secrets[0].secretSource" ||
+		!strings.Contains(errs[0].Error(), "envSource, fileSource") {
+		t.Errorf("Validate() = %v; want a single error about two sources", errs)
+	}
+}
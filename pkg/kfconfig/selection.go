@@ -0,0 +1,69 @@
+package kfconfig
+
+import (
+	"sort"
+	"strings"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	"github.com/kubeflow/kfctl/v3/pkg/utils"
+)
+
+// ApplicationSelection holds the applications a command works on, e.g. set with the
+// --app and --skip-app flags of kfctl apply, build and delete.
+type ApplicationSelection struct {
+	only map[string]bool
+	skip map[string]bool
+}
+
+// Selects returns true if the command works on the application name.
+func (s ApplicationSelection) Selects(name string) bool {
+	if len(s.only) > 0 && !s.only[name] {
+		return false
+	}
+	return !s.skip[name]
+}
+
+// IsSubset returns true if the selection may leave out applications, in which case
+// objects shared by all applications, like the KfDef namespace, must be left alone.
+func (s ApplicationSelection) IsSubset() bool {
+	return len(s.only) > 0 || len(s.skip) > 0
+}
+
+// ApplicationSelection returns the applications selected by the apps and skip-apps
+// annotations of c, comma separated lists of application names. Without them all
+// applications are selected. Names of applications c doesn't have are an error.
+func (c *KfConfig) ApplicationSelection() (ApplicationSelection, error) {
+	s := ApplicationSelection{
+		only: c.applicationsAnnotation(utils.Apps),
+		skip: c.applicationsAnnotation(utils.SkipApps),
+	}
+	known := map[string]bool{}
+	for _, app := range c.Spec.Applications {
+		known[app.Name] = true
+	}
+	unknown := []string{}
+	for _, names := range []map[string]bool{s.only, s.skip} {
+		for name := range names {
+			if !known[name] {
+				unknown = append(unknown, name)
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return ApplicationSelection{}, kfapis.NewKfError(kfapis.ErrConfigInvalid,
+			"KfDef %v has no applications named %v", c.Name, strings.Join(unknown, ", "))
+	}
+	return s, nil
+}
+
+func (c *KfConfig) applicationsAnnotation(name string) map[string]bool {
+	names := map[string]bool{}
+	value := c.GetAnnotations()[strings.Join([]string{utils.KfDefAnnotation, name}, "/")]
+	for _, n := range strings.Split(value, ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			names[n] = true
+		}
+	}
+	return names
+}
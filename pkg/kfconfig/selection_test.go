@@ -0,0 +1,90 @@
+package kfconfig
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubeflow/kfctl/v3/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApplicationSelection(t *testing.T) {
+	type testCase struct {
+		name        string
+		apps        string
+		skipApps    string
+		selected    []string
+		subset      bool
+		expectedErr string
+	}
+	testCases := []testCase{
+		{
+			name:     "all",
+			selected: []string{"istio", "cert-manager", "jupyter"},
+		},
+		{
+			name:     "apps",
+			apps:     "istio, jupyter",
+			selected: []string{"istio", "jupyter"},
+			subset:   true,
+		},
+		{
+			name:     "skip apps",
+			skipApps: "istio",
+			selected: []string{"cert-manager", "jupyter"},
+			subset:   true,
+		},
+		{
+			name:     "apps and skip apps",
+			apps:     "istio,jupyter",
+			skipApps: "jupyter",
+			selected: []string{"istio"},
+			subset:   true,
+		},
+		{
+			name:        "unknown apps",
+			apps:        "istio,notebooks",
+			skipApps:    "katib",
+			expectedErr: "KfDef kubeflow has no applications named katib, notebooks",
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			config := &KfConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "kubeflow",
+					Annotations: map[string]string{
+						strings.Join([]string{utils.KfDefAnnotation, utils.Apps}, "/"):     c.apps,
+						strings.Join([]string{utils.KfDefAnnotation, utils.SkipApps}, "/"): c.skipApps,
+					},
+				},
+				Spec: KfConfigSpec{
+					Applications: []Application{{Name: "istio"}, {Name: "cert-manager"}, {Name: "jupyter"}},
+				},
+			}
+			selection, err := config.ApplicationSelection()
+			if c.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.expectedErr) {
+					t.Fatalf("ApplicationSelection() error = %v; want %v", err, c.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplicationSelection() failed: %v", err)
+			}
+			selected := []string{}
+			for _, app := range config.Spec.Applications {
+				if selection.Selects(app.Name) {
+					selected = append(selected, app.Name)
+				}
+			}
+			if strings.Join(selected, ",") != strings.Join(c.selected, ",") {
+				t.Errorf("selected applications = %v; want %v", selected, c.selected)
+			}
+			if selection.IsSubset() != c.subset {
+				t.Errorf("IsSubset() = %v; want %v", selection.IsSubset(), c.subset)
+			}
+		})
+	}
+}
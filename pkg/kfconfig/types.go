@@ -1,15 +1,14 @@
 package kfconfig
 
 import (
-	"archive/tar"
-	"bytes"
-	"compress/gzip"
+	"crypto/sha256"
 	"fmt"
 	"github.com/ghodss/yaml"
 	gogetter "github.com/hashicorp/go-getter"
 	"github.com/hashicorp/go-getter/helper/url"
 	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
 	kftypesv3 "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
+	"github.com/kubeflow/kfctl/v3/pkg/utils"
 	"github.com/otiai10/copy"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -24,7 +23,9 @@ import (
 	"path/filepath"
 	"regexp"
 	"sigs.k8s.io/kustomize/v3/pkg/types"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 const (
@@ -83,6 +84,37 @@ type KfConfigSpec struct {
 type Application struct {
 	Name            string           `json:"name,omitempty"`
 	KustomizeConfig *KustomizeConfig `json:"kustomizeConfig,omitempty"`
+	// DependsOn lists the applications that must be applied and ready before this one.
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// Namespace the objects of the application are installed in.
+	// Defaults to the namespace of the KfConfig.
+	Namespace string `json:"namespace,omitempty"`
+	// Enabled is false for applications that are declared but not installed. Defaults to true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// Labels are added to every object of the application.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Version of the application. It is set as the app.kubernetes.io/version label of its objects.
+	Version string `json:"version,omitempty"`
+}
+
+// VersionLabel is the label holding the version of an application.
+const VersionLabel = "app.kubernetes.io/version"
+
+// IsEnabled returns false if the application is declared but shouldn't be installed.
+func (a Application) IsEnabled() bool {
+	return a.Enabled == nil || *a.Enabled
+}
+
+// ObjectLabels returns the labels to add to every object of the application.
+func (a Application) ObjectLabels() map[string]string {
+	labels := map[string]string{}
+	for k, v := range a.Labels {
+		labels[k] = v
+	}
+	if a.Version != "" {
+		labels[VersionLabel] = a.Version
+	}
+	return labels
 }
 
 type KustomizeConfig struct {
@@ -115,24 +147,71 @@ type Secret struct {
 	SecretSource *SecretSource `json:"secretSource,omitempty"`
 }
 
+// SecretSource holds the source of a secret's value. Only one source should be set. Sources
+// other than LiteralSource and HashedSource are references that GetSecret resolves when the
+// secret is used; the value itself is never written to the KfDef.
 type SecretSource struct {
-	LiteralSource *LiteralSource `json:"literalSource,omitempty"`
-	HashedSource  *HashedSource  `json:"hashedSource,omitempty"`
-	EnvSource     *EnvSource     `json:"envSource,omitempty"`
+	LiteralSource   *LiteralSource   `json:"literalSource,omitempty"`
+	HashedSource    *HashedSource    `json:"hashedSource,omitempty"`
+	EnvSource       *EnvSource       `json:"envSource,omitempty"`
+	FileSource      *FileSource      `json:"fileSource,omitempty"`
+	K8sSecretSource *K8sSecretSource `json:"k8sSecretSource,omitempty"`
+	VaultSource     *VaultSource     `json:"vaultSource,omitempty"`
+	SopsSource      *SopsSource      `json:"sopsSource,omitempty"`
 }
 
 type LiteralSource struct {
-	Value string `json:"value,omitempty"`
+	Value string `json:"value,omitempty" kfctl:"secret"`
 }
 
 type HashedSource struct {
-	HashedValue string `json:"value,omitempty"`
+	HashedValue string `json:"value,omitempty" kfctl:"secret"`
 }
 
 type EnvSource struct {
 	Name string `json:"name,omitempty"`
 }
 
+// FileSource reads the secret from a file. A trailing newline is not part of the secret.
+type FileSource struct {
+	// Path of the file, relative to the app dir.
+	Path string `json:"path,omitempty"`
+}
+
+// K8sSecretSource reads the secret from a key of a Kubernetes Secret in the current
+// kubeconfig context.
+type K8sSecretSource struct {
+	Name string `json:"name,omitempty"`
+	// Namespace of the Secret. Defaults to the namespace of the KfDef.
+	Namespace string `json:"namespace,omitempty"`
+	Key       string `json:"key,omitempty"`
+}
+
+// VaultSource reads the secret from a key of a HashiCorp Vault KV secret. The Vault token is
+// read from $VAULT_TOKEN or else ~/.vault-token.
+type VaultSource struct {
+	// Address of the Vault server. Defaults to $VAULT_ADDR.
+	Address string `json:"address,omitempty"`
+	// Mount is the path the KV secrets engine is mounted at. Defaults to secret.
+	Mount string `json:"mount,omitempty"`
+	// Path of the secret in the KV secrets engine.
+	Path string `json:"path,omitempty"`
+	Key  string `json:"key,omitempty"`
+	// KVVersion is the version of the KV secrets engine, 1 or 2. Defaults to 2.
+	KVVersion int `json:"kvVersion,omitempty"`
+}
+
+// SopsSource reads the secret from a key of a document encrypted with SOPS, which is
+// decrypted with the sops command.
+type SopsSource struct {
+	// Path of the encrypted document, relative to the app dir.
+	Path string `json:"path,omitempty"`
+	// Value is the encrypted YAML or JSON document if Path isn't set.
+	Value string `json:"value,omitempty"`
+	// Key of the secret at the top level of the document.
+	Key string `json:"key,omitempty"`
+}
+
 // SecretRef is a reference to a secret
 type SecretRef struct {
 	// Name of the secret
@@ -148,6 +227,14 @@ type Repo struct {
 	// Can use any URI understood by go-getter:
 	// https://github.com/hashicorp/go-getter/blob/master/README.md#installation-and-usage
 	URI string `json:"uri,omitempty"`
+	// Sha256 is the digest of the archive at URI. If set, kfctl fails if the downloaded
+	// archive has another digest. kfctl alpha lock sets it.
+	Sha256 string `json:"sha256,omitempty"`
+	// Ref is the branch, tag, commit or other ref, e.g. pull/<ID>/head, of a git:: repo.
+	// The default branch is used if it's empty.
+	Ref string `json:"ref,omitempty"`
+	// SubPath is the directory of the repo that applications are relative to.
+	SubPath string `json:"subPath,omitempty"`
 }
 
 type Status struct {
@@ -173,6 +260,10 @@ type Condition struct {
 type Cache struct {
 	Name      string `json:"name,omitempty"`
 	LocalPath string `json:"localPath,omitempty"`
+	// Digest of the archive the repo was fetched from, e.g. sha256:0123....
+	Digest string `json:"digest,omitempty"`
+	// Commit of a git:: repo that Ref resolved to.
+	Commit string `json:"commit,omitempty"`
 }
 
 type PluginKindType string
@@ -230,7 +321,7 @@ func (c *KfConfig) GetRepoCache(repoName string) (Cache, bool) {
 }
 
 func (c *KfConfig) GetPluginSpec(pluginKind PluginKindType, s interface{}) error {
-	for _, p := range c.Spec.Plugins {
+	for i, p := range c.Spec.Plugins {
 		if p.Kind != pluginKind {
 			continue
 		}
@@ -246,6 +337,9 @@ func (c *KfConfig) GetPluginSpec(pluginKind PluginKindType, s interface{}) error
 				Message: msg,
 			}
 		}
+		if err := c.checkPluginFields(fmt.Sprintf("spec.plugins[%v].spec", i), specBytes, s); err != nil {
+			return err
+		}
 		err = yaml.Unmarshal(specBytes, s)
 		if err != nil {
 			msg := fmt.Sprintf("Could not unmarshal plugin %v to the provided type; error %v", pluginKind, err)
@@ -255,12 +349,48 @@ func (c *KfConfig) GetPluginSpec(pluginKind PluginKindType, s interface{}) error
 				Message: msg,
 			}
 		}
+		for _, value := range utils.SecretValues(s) {
+			utils.AddSecretValue(value)
+		}
 		return nil
 	}
-	return &kfapis.KfError{
-		Code:    int(kfapis.NOT_FOUND),
-		Message: fmt.Sprintf("%v %v", pluginNotFoundErrPrefix, pluginKind),
+	return kfapis.NewKfError(kfapis.ErrPluginNotFound, "%v %v", pluginNotFoundErrPrefix, pluginKind)
+}
+
+// warnedUnknownFields holds the unknown plugin fields already reported in non-strict mode;
+// plugins get their spec many times during a single command.
+var warnedUnknownFields sync.Map
+
+// checkPluginFields reports the fields of the plugin spec at path that s doesn't have.
+// They are errors unless the strict annotation is false. The loaders report them with
+// their line numbers when the config is read, so only paths are reported here.
+func (c *KfConfig) checkPluginFields(path string, spec []byte, s interface{}) error {
+	unknown, err := utils.UnknownFields(spec, s, nil)
+	if err != nil {
+		return kfapis.WrapKfErrorAs(kfapis.ErrConfigInvalid, err, "couldn't decode %v", path)
+	}
+	for i := range unknown {
+		unknown[i] = utils.UnknownField{Path: path + "." + unknown[i].Path}
 	}
+	if c.Strict() {
+		return utils.UnknownFieldsError("KfDef "+c.Name, unknown)
+	}
+	for _, f := range unknown {
+		if _, warned := warnedUnknownFields.LoadOrStore(c.Name+"/"+f.Path, true); !warned {
+			log.Warnf("KfDef %v: %v", c.Name, f)
+		}
+	}
+	return nil
+}
+
+// Strict returns false if the strict annotation of c turns unknown config fields into warnings.
+func (c *KfConfig) Strict() bool {
+	value, ok := c.GetAnnotations()[strings.Join([]string{utils.KfDefAnnotation, utils.Strict}, "/")]
+	if !ok {
+		return true
+	}
+	strict, err := strconv.ParseBool(value)
+	return err != nil || strict
 }
 
 // SetPluginSpec sets the requested parameter: add the plugin if it doesn't already exist, or replace existing plugin.
@@ -406,6 +536,9 @@ func (c *KfConfig) SetPluginFailed(pluginKind PluginKindType, msg string) {
 	c.SetCondition(failedCond, v1.ConditionTrue, "", msg)
 }
 
+// forcedGetterRegexp matches go-getter URIs with a forced getter, from gogetter.getForcedGetter.
+var forcedGetterRegexp = regexp.MustCompile(`^([A-Za-z0-9]+)::(.+)$`)
+
 // SyncCache will synchronize the local cache of any repositories.
 // On success the status is updated with pointers to the cache.
 //
@@ -437,15 +570,18 @@ func (c *KfConfig) SetPluginFailed(pluginKind PluginKindType, msg string) {
 // But unpacks it into
 // kubeflow-manifests-${COMMIT}
 //
+// git:: repos avoid both problems: Ref takes any ref, e.g. pull/188/head, the clone is the
+// cache dir and the commit is recorded in Status.Caches.
+//
 func (c *KfConfig) SyncCache() error {
 	if c.Spec.AppDir == "" {
 		return fmt.Errorf("AppDir must be specified")
 	}
 
 	appDir := c.Spec.AppDir
-	// Loop over all the repos and download them.
-	// TODO(https://github.com/kubeflow/kubeflow/issues/3545): We should check if we already have a local copy and
-	// not redownload it.
+	// Loop over all the repos and download them. Archives are shared between app dirs through
+	// the shared cache so only the first app dir downloads them. Offline, repos come from the
+	// bundle or the shared cache and nothing is downloaded.
 
 	baseCacheDir := path.Join(appDir, DefaultCacheDir)
 	if _, err := os.Stat(baseCacheDir); os.IsNotExist(err) {
@@ -457,6 +593,12 @@ func (c *KfConfig) SyncCache() error {
 		}
 	}
 
+	if Offline() {
+		if err := c.checkOffline(baseCacheDir); err != nil {
+			return err
+		}
+	}
+
 	for _, r := range c.Spec.Repos {
 		cacheDir := path.Join(baseCacheDir, r.Name)
 
@@ -464,14 +606,7 @@ func (c *KfConfig) SyncCache() error {
 		// If there was a problem the first time around then removing it might provide a way to recover.
 		if _, err := os.Stat(cacheDir); err == nil {
 			// Check if the cache is up to date.
-			shouldSkip := false
-			for _, cache := range c.Status.Caches {
-				if cache.Name == r.Name && cache.LocalPath != "" {
-					shouldSkip = true
-					break
-				}
-			}
-			if shouldSkip {
+			if c.isCached(r) {
 				log.Infof("%v exists; not resyncing ", cacheDir)
 				continue
 			}
@@ -483,11 +618,16 @@ func (c *KfConfig) SyncCache() error {
 			// the KfDef. Specifically coordinator.CreateKfDefFromOptions is calling kftypes.DownloadFromCache
 			// We don't want to rely on that method to set the cache because we have logic
 			// below to set LocalPath that we don't want to duplicate.
-			// Unfortunately this means we end up fetching the repo twice which is very inefficient.
+			// Archives are fetched from the shared cache the second time; other repos are fetched twice.
 			if err := os.RemoveAll(cacheDir); err != nil {
 				log.Errorf("There was a problem deleting directory %v; error %v", cacheDir, err)
 				return errors.WithStack(err)
 			}
+		} else if fi, err := os.Lstat(cacheDir); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+			log.Infof("Removing %v because the shared cache entry it links to was pruned", cacheDir)
+			if err := os.Remove(cacheDir); err != nil {
+				return errors.WithStack(err)
+			}
 		}
 
 		u, err := url.Parse(r.URI)
@@ -497,12 +637,34 @@ func (c *KfConfig) SyncCache() error {
 			return errors.WithStack(err)
 		}
 
+		subPath, err := r.subPath()
+		if err != nil {
+			return kfapis.WrapKfError(err, "couldn't fetch repo %v", r.Name)
+		}
+
 		log.Infof("Fetching %v to %v", r.URI, cacheDir)
 		fu, err := gogetter.Detect(r.URI, "", gogetter.Detectors)
-		// from gogetter.getForcedGetter
-		var forcedRegexp = regexp.MustCompile(`^([A-Za-z0-9]+)::(.+)$`)
 		// uri is in go-getter format (i.e. not http, may also handle local)
-		if ms := forcedRegexp.FindStringSubmatch(fu); ms != nil {
+		isDir := false
+		if fi, err := os.Stat(r.URI); err == nil && fi.Mode().IsDir() {
+			isDir = true
+		}
+		ms := forcedGetterRegexp.FindStringSubmatch(fu)
+		if r.Sha256 != "" && !r.IsArchive() {
+			return kfapis.NewKfError(kfapis.ErrConfigInvalid,
+				"repo %v has a sha256 but %v isn't an archive; only archives can be verified", r.Name, r.URI)
+		}
+		digest, commit := "", ""
+		if Offline() && !isDir {
+			if digest, commit, err = fetchOffline(r, cacheDir); err != nil {
+				return kfapis.WrapKfError(err, "couldn't fetch repo %v", r.Name)
+			}
+		} else if r.IsGit() {
+			if commit, err = fetchGit(r, cacheDir); err != nil {
+				return kfapis.WrapKfError(err, "couldn't fetch repo %v", r.Name)
+			}
+			log.Infof("Fetched commit %v of repo %v", commit, r.Name)
+		} else if ms != nil {
 			tarballUrlErr := gogetter.GetAny(cacheDir, fu)
 			if tarballUrlErr != nil {
 				return &kfapis.KfError{
@@ -510,65 +672,38 @@ func (c *KfConfig) SyncCache() error {
 					Message: fmt.Sprintf("couldn't download URI %v Error %v", fu, tarballUrlErr),
 				}
 			}
-		} else {
+		} else if isDir {
+			// Manifests are local dir
 			if err := os.MkdirAll(cacheDir, os.ModePerm); err != nil {
 				log.Errorf("Could not create dir %v; error %v", cacheDir, err)
 				return errors.WithStack(err)
 			}
 
-			// Manifests are local dir
-			if fi, err := os.Stat(r.URI); err == nil && fi.Mode().IsDir() {
-				// check whether the cache directory is a sub directory of manifests
-				absCacheDir, err := filepath.Abs(cacheDir)
-				if err != nil {
-					return errors.WithStack(err)
-				}
-
-				absURI, err := filepath.Abs(r.URI)
-				if err != nil {
-					return errors.WithStack(err)
-				}
+			// check whether the cache directory is a sub directory of manifests
+			absCacheDir, err := filepath.Abs(cacheDir)
+			if err != nil {
+				return errors.WithStack(err)
+			}
 
-				relDir, err := filepath.Rel(absURI, absCacheDir)
-				if err != nil {
-					return errors.WithStack(err)
-				}
+			absURI, err := filepath.Abs(r.URI)
+			if err != nil {
+				return errors.WithStack(err)
+			}
 
-				if !strings.HasPrefix(relDir, ".."+string(filepath.Separator)) {
-					return errors.WithStack(errors.New("SyncCache: could not sync cache when the cache path " + cacheDir + " is sub directory of manifests " + r.URI))
-				}
+			relDir, err := filepath.Rel(absURI, absCacheDir)
+			if err != nil {
+				return errors.WithStack(err)
+			}
 
-				if err := copy.Copy(r.URI, cacheDir); err != nil {
-					return errors.WithStack(err)
-				}
-			} else {
-				t := &http.Transport{
-					Proxy: http.ProxyFromEnvironment,
-				}
-				t.RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
-				t.RegisterProtocol("", http.NewFileTransport(http.Dir("/")))
-				hclient := &http.Client{Transport: t}
-				req, _ := http.NewRequest("GET", r.URI, nil)
-				req.Header.Set("User-Agent", "kfctl")
-				resp, err := hclient.Do(req)
-				if err != nil {
-					return &kfapis.KfError{
-						Code:    int(kfapis.INVALID_ARGUMENT),
-						Message: fmt.Sprintf("couldn't download URI %v : %v", r.URI, err),
-					}
-				}
-				defer resp.Body.Close()
+			if !strings.HasPrefix(relDir, ".."+string(filepath.Separator)) {
+				return errors.WithStack(errors.New("SyncCache: could not sync cache when the cache path " + cacheDir + " is sub directory of manifests " + r.URI))
+			}
 
-				body, err := ioutil.ReadAll(resp.Body)
-				if err != nil {
-					log.Errorf("Could not read response body; error %v", err)
-					return errors.WithStack(err)
-				}
-				if err := untar(body, cacheDir); err != nil {
-					log.Errorf("Could not untar file %v; error %v", r.URI, err)
-					return errors.WithStack(err)
-				}
+			if err := copy.Copy(r.URI, cacheDir); err != nil {
+				return errors.WithStack(err)
 			}
+		} else if digest, err = fetchArchive(r.URI, r.Digest(), cacheDir); err != nil {
+			return kfapis.WrapKfError(err, "couldn't fetch repo %v", r.Name)
 		}
 
 		// This is a bit of a hack to deal with the fact that GitHub tarballs
@@ -598,9 +733,18 @@ func (c *KfConfig) SyncCache() error {
 			}
 		}
 
+		if subPath != "" {
+			localPath = path.Join(localPath, subPath)
+			if fi, err := os.Stat(localPath); err != nil || !fi.IsDir() {
+				return kfapis.NewKfError(kfapis.ErrConfigInvalid, "repo %v has no directory %v", r.Name, subPath)
+			}
+		}
+
 		c.Status.Caches = append(c.Status.Caches, Cache{
 			Name:      r.Name,
 			LocalPath: localPath,
+			Digest:    digest,
+			Commit:    commit,
 		})
 
 		log.Infof("Fetch succeeded; LocalPath %v", localPath)
@@ -608,71 +752,201 @@ func (c *KfConfig) SyncCache() error {
 	return nil
 }
 
-func untar(body []byte, cacheDir string) error {
-	gzf, err := gzip.NewReader(bytes.NewReader(body))
-	if err != nil {
-		return err
-	}
-
-	tarReader := tar.NewReader(gzf)
-	for {
-		header, err := tarReader.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
+// isCached returns true if Status.Caches has an up to date entry for r. Git repos whose ref
+// is a commit must have been fetched at that commit.
+func (c *KfConfig) isCached(r Repo) bool {
+	for _, cache := range c.Status.Caches {
+		if cache.Name != r.Name || cache.LocalPath == "" || r.Sha256 != "" && cache.Digest != r.Digest() {
+			continue
 		}
-		if header == nil {
+		if commitPattern.MatchString(r.Ref) && !strings.EqualFold(cache.Commit, r.Ref) {
 			continue
 		}
+		return true
+	}
+	return false
+}
 
-		target := filepath.Join(cacheDir, header.Name)
+// Digest returns the pinned digest of the repo archive, e.g. sha256:0123..., or "".
+func (r Repo) Digest() string {
+	if r.Sha256 == "" {
+		return ""
+	}
+	return "sha256:" + strings.ToLower(strings.TrimPrefix(r.Sha256, "sha256:"))
+}
 
-		switch header.Typeflag {
+// IsArchive returns true if the repo is fetched as an archive, i.e. its URI is neither a
+// go-getter URI with a forced getter such as git:: nor a local directory. Only archives
+// are shared between app dirs and can be pinned with Sha256.
+func (r Repo) IsArchive() bool {
+	if fi, err := os.Stat(r.URI); err == nil && fi.Mode().IsDir() {
+		return false
+	}
+	fu, err := gogetter.Detect(r.URI, "", gogetter.Detectors)
+	return err != nil || !forcedGetterRegexp.MatchString(fu)
+}
 
-		case tar.TypeDir:
-			if _, err := os.Stat(target); err != nil {
-				if err := os.MkdirAll(target, 0755); err != nil {
-					return err
-				}
-			}
+// ResolveDigest returns the digest of the archive of the repo, fetching it into the shared
+// cache unless it's there. It fails if the repo is pinned to another digest.
+func (r Repo) ResolveDigest() (string, error) {
+	if !r.IsArchive() {
+		return "", kfapis.NewKfError(kfapis.ErrConfigInvalid, "%v isn't an archive", r.URI)
+	}
+	shared, err := DefaultSharedCache()
+	if err != nil {
+		log.Warnf("Not using the shared cache: %v", err)
+	}
+	if shared == nil {
+		tmp, err := ioutil.TempDir("", "kfctl-repo-")
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		defer os.RemoveAll(tmp)
+		return downloadArchive(r.URI, r.Digest(), tmp)
+	}
+	entry, err := shared.Get(r.URI, r.Digest(), func(dir string) (string, error) {
+		return downloadArchive(r.URI, r.Digest(), dir)
+	})
+	return entry.Digest, err
+}
 
-		case tar.TypeReg:
-			f, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
-			if err != nil {
-				return err
-			}
+// fetchArchive makes cacheDir a link to the archive at uri in the shared cache, downloading
+// it if it isn't cached yet, and returns its digest. Without a shared cache the archive is
+// extracted into cacheDir. If digest isn't empty the archive must have that digest.
+func fetchArchive(uri string, digest string, cacheDir string) (string, error) {
+	shared, err := DefaultSharedCache()
+	if err != nil {
+		log.Warnf("Not using the shared cache: %v", err)
+	}
+	if shared == nil {
+		if err := os.MkdirAll(cacheDir, os.ModePerm); err != nil {
+			log.Errorf("Could not create dir %v; error %v", cacheDir, err)
+			return "", errors.WithStack(err)
+		}
+		return downloadArchive(uri, digest, cacheDir)
+	}
+	entry, err := shared.Get(uri, digest, func(dir string) (string, error) {
+		return downloadArchive(uri, digest, dir)
+	})
+	if err != nil {
+		return "", err
+	}
+	log.Infof("Using %v of %v from the shared cache", entry.Digest, uri)
+	return entry.Digest, shared.Link(entry, cacheDir)
+}
 
-			if _, err := io.Copy(f, tarReader); err != nil {
-				return err
-			}
+// downloadArchive extracts the archive at uri, a URL or a local file, into dir and returns
+// its digest. The archive is extracted while it's downloaded unless digest isn't empty; then
+// it's written to a temporary file first and only extracted if it has that digest.
+func downloadArchive(uri string, digest string, dir string) (string, error) {
+	rc, err := openURI(uri)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
 
-			if err := f.Close(); err != nil {
-				return err
-			}
+	h := sha256.New()
+	var body io.Reader = io.TeeReader(rc, h)
+	if digest != "" {
+		tmp, err := ioutil.TempFile("", "kfctl-archive-")
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+		if _, err := io.Copy(tmp, body); err != nil {
+			return "", kfapis.WrapKfError(err, "couldn't download URI %v", uri)
+		}
+		if actual := fmt.Sprintf("sha256:%x", h.Sum(nil)); actual != digest {
+			return "", kfapis.NewKfError(kfapis.ErrConfigInvalid,
+				"%v has digest %v but the KfDef pins %v; refusing to use it", uri, actual, digest)
+		}
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return "", errors.WithStack(err)
+		}
+		body = tmp
+	}
+	if err := extractArchive(body, dir); err != nil {
+		log.Errorf("Could not extract %v; error %v", uri, err)
+		// Don't leave a partial, possibly malicious, archive behind.
+		if removeErr := removeContents(dir); removeErr != nil {
+			log.Warnf("Couldn't clean up %v: %v", dir, removeErr)
+		}
+		return "", kfapis.WrapKfError(err, "couldn't extract %v", uri)
+	}
+	// Hash what's after the end of the archive too.
+	if _, err := io.Copy(ioutil.Discard, body); err != nil {
+		return "", kfapis.WrapKfError(err, "couldn't download URI %v", uri)
+	}
+	return fmt.Sprintf("sha256:%x", h.Sum(nil)), nil
+}
+
+// openURI opens uri, a URL or a local file, for reading.
+func openURI(uri string) (io.ReadCloser, error) {
+	t := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+	}
+	t.RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
+	t.RegisterProtocol("", http.NewFileTransport(http.Dir("/")))
+	hclient := &http.Client{Transport: t}
+	req, _ := http.NewRequest("GET", uri, nil)
+	req.Header.Set("User-Agent", "kfctl")
+	resp, err := hclient.Do(req)
+	if err != nil {
+		return nil, &kfapis.KfError{
+			Code:    int(kfapis.UNAVAILABLE),
+			Message: fmt.Sprintf("couldn't download URI %v : %v", uri, err),
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		// Server errors and rate limits may go away; other statuses won't.
+		code := kfapis.INVALID_ARGUMENT
+		if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+			code = kfapis.UNAVAILABLE
+		}
+		return nil, &kfapis.KfError{
+			Code:    int(code),
+			Message: fmt.Sprintf("couldn't download URI %v : %v", uri, resp.Status),
+		}
+	}
+	return resp.Body, nil
+}
+
+// removeContents removes the files in dir.
+func removeContents(dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := os.RemoveAll(filepath.Join(dir, f.Name())); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
-// GetSecret returns the specified secret or an error if the secret isn't specified.
+// GetSecret returns the specified secret or an error if the secret isn't specified. Secrets
+// that are references are resolved on every call.
 func (c *KfConfig) GetSecret(name string) (string, error) {
 	for _, s := range c.Spec.Secrets {
 		if s.Name != name {
 			continue
 		}
-		if s.SecretSource.LiteralSource != nil {
-			return s.SecretSource.LiteralSource.Value, nil
+		provider := s.SecretSource.provider()
+		if provider == nil {
+			return "", fmt.Errorf("No secret source provided for secret %v", name)
 		}
-		if s.SecretSource.HashedSource != nil {
-			return s.SecretSource.HashedSource.HashedValue, nil
+		value, err := provider.Secret(c)
+		if err == nil {
+			err = checkRedacted(value)
 		}
-		if s.SecretSource.EnvSource != nil {
-			return os.Getenv(s.SecretSource.EnvSource.Name), nil
+		if err != nil {
+			return "", kfapis.WrapKfError(err, "couldn't get secret %v", name)
 		}
-
-		return "", fmt.Errorf("No secret source provided for secret %v", name)
+		utils.AddSecretValue(value)
+		return value, nil
 	}
 	return "", NewSecretNotFound(name)
 }
@@ -993,7 +1267,7 @@ func IsPluginNotFound(e error) bool {
 	if e == nil {
 		return false
 	}
-	err, ok := e.(*kfapis.KfError)
+	err, ok := kfapis.AsKfError(e)
 	return ok && err.Code == int(kfapis.NOT_FOUND) && strings.HasPrefix(err.Message, pluginNotFoundErrPrefix)
 }
 
@@ -1001,7 +1275,7 @@ func IsConditionNotFound(e error) bool {
 	if e == nil {
 		return false
 	}
-	err, ok := e.(*kfapis.KfError)
+	err, ok := kfapis.AsKfError(e)
 	return ok && err.Code == int(kfapis.NOT_FOUND) &&
 		strings.HasPrefix(err.Message, conditionNotFoundErrPrefix)
 }
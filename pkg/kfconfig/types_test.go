@@ -24,11 +24,13 @@ import (
 	"io/ioutil"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"os"
 	"path"
 	"path/filepath"
 	"reflect"
 	"sigs.k8s.io/kustomize/v3/pkg/types"
+	"strings"
 	"testing"
 )
 
@@ -41,6 +43,8 @@ func TestSyncCache(t *testing.T) {
 
 	// Verify that we can sync some files.
 	testDir, _ := ioutil.TempDir("", "")
+	defer os.Setenv(SharedCacheEnv, os.Getenv(SharedCacheEnv))
+	os.Setenv(SharedCacheEnv, path.Join(testDir, "shared"))
 
 	srcDir := path.Join(testDir, "src")
 	err := os.Mkdir(srcDir, os.ModePerm)
@@ -260,6 +264,27 @@ func TestKfConfig_GetPluginSpec(t *testing.T) {
 	}
 }
 
+func TestKfConfig_GetPluginSpecStrict(t *testing.T) {
+	d := &KfConfig{
+		Spec: KfConfigSpec{
+			Plugins: []Plugin{{
+				Kind: "fakeplugin",
+				Spec: &runtime.RawExtension{Raw: []byte(`{"param": "p", "parm": "typo"}`)},
+			}},
+		},
+	}
+	err := d.GetPluginSpec("fakeplugin", &FakePluginSpec{})
+	if err == nil || !strings.Contains(err.Error(), "unknown field spec.plugins[0].spec.parm") {
+		t.Errorf("GetPluginSpec with an unknown field returned %v; want an error naming it", err)
+	}
+
+	d.SetAnnotations(map[string]string{"kfctl.kubeflow.io/strict": "false"})
+	actual := &FakePluginSpec{}
+	if err := d.GetPluginSpec("fakeplugin", actual); err != nil || actual.Param != "p" {
+		t.Errorf("GetPluginSpec with strict=false returned %v, %+v; want the known fields", err, actual)
+	}
+}
+
 func TestKfConfig_SetPluginSpec(t *testing.T) {
 	// Test that we can properly parse the gcp structs.
 	type testCase struct {
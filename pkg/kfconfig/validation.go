@@ -0,0 +1,245 @@
+package kfconfig
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// knownPluginKinds are the plugin kinds kfctl can load.
+var knownPluginKinds = []string{
+	string(AWS_PLUGIN_KIND),
+	string(GCP_PLUGIN_KIND),
+	string(MINIKUBE_PLUGIN_KIND),
+	string(EXISTING_ARRIKTO_PLUGIN_KIND),
+}
+
+// sha256Pattern matches the hex digests of Repo.Sha256.
+var sha256Pattern = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// pluginSecretRefs are the fields of plugin specs that hold a SecretRef.
+var pluginSecretRefs = map[PluginKindType][][]string{
+	AWS_PLUGIN_KIND: {
+		{"auth", "basicAuth", "password"},
+	},
+	GCP_PLUGIN_KIND: {
+		{"auth", "basicAuth", "password"},
+		{"auth", "iap", "oAuthClientSecret"},
+	},
+}
+
+// Validate checks c for semantic problems and returns all of them, each with the path of
+// the offending field. Overlays are only checked for repos that are already in the cache.
+func (c *KfConfig) Validate() field.ErrorList {
+	allErrs := field.ErrorList{}
+	metadata := field.NewPath("metadata")
+	if c.Name != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(c.Name) {
+			allErrs = append(allErrs, field.Invalid(metadata.Child("name"), c.Name, msg))
+		}
+	}
+	if c.Namespace != "" {
+		for _, msg := range validation.IsDNS1123Label(c.Namespace) {
+			allErrs = append(allErrs, field.Invalid(metadata.Child("namespace"), c.Namespace, msg))
+		}
+	}
+
+	spec := field.NewPath("spec")
+	repos := map[string]bool{}
+	for i, repo := range c.Spec.Repos {
+		repoPath := spec.Child("repos").Index(i)
+		switch {
+		case repo.Name == "":
+			allErrs = append(allErrs, field.Required(repoPath.Child("name"), ""))
+		case repos[repo.Name]:
+			allErrs = append(allErrs, field.Duplicate(repoPath.Child("name"), repo.Name))
+		}
+		if repo.URI == "" {
+			allErrs = append(allErrs, field.Required(repoPath.Child("uri"), ""))
+		}
+		if repo.Sha256 != "" && !sha256Pattern.MatchString(strings.TrimPrefix(repo.Sha256, "sha256:")) {
+			allErrs = append(allErrs, field.Invalid(repoPath.Child("sha256"), repo.Sha256,
+				"must be 64 hexadecimal digits"))
+		}
+		if repo.Ref != "" && !repo.IsGit() {
+			allErrs = append(allErrs, field.Invalid(repoPath.Child("ref"), repo.Ref, "only git:: repos have a ref"))
+		}
+		if checkSubPath(repo.SubPath) != nil {
+			allErrs = append(allErrs, field.Invalid(repoPath.Child("subPath"), repo.SubPath,
+				"must be a relative path inside the repo"))
+		}
+		if repo.IsGit() {
+			if _, _, _, err := repo.gitSource(); err != nil {
+				msg := err.Error()
+				if kfErr, ok := kfapis.AsKfError(err); ok {
+					msg = kfErr.Message
+				}
+				allErrs = append(allErrs, field.Invalid(repoPath.Child("uri"), repo.URI, msg))
+			}
+		}
+		repos[repo.Name] = true
+	}
+
+	secrets := map[string]bool{}
+	for i, secret := range c.Spec.Secrets {
+		secretPath := spec.Child("secrets").Index(i)
+		switch {
+		case secret.Name == "":
+			allErrs = append(allErrs, field.Required(secretPath.Child("name"), ""))
+		case secrets[secret.Name]:
+			allErrs = append(allErrs, field.Duplicate(secretPath.Child("name"), secret.Name))
+		}
+		if secret.SecretSource == nil {
+			allErrs = append(allErrs, field.Required(secretPath.Child("secretSource"), ""))
+		} else if sources := secret.SecretSource.sources(); len(sources) > 1 {
+			allErrs = append(allErrs, field.Invalid(secretPath.Child("secretSource"), strings.Join(sources, ", "),
+				"only one source may be set"))
+		}
+		secrets[secret.Name] = true
+	}
+
+	apps := map[string]bool{}
+	for _, app := range c.Spec.Applications {
+		apps[app.Name] = true
+	}
+	seen := map[string]bool{}
+	appErrs := field.ErrorList{}
+	for i, app := range c.Spec.Applications {
+		appErrs = append(appErrs, c.validateApplication(app, spec.Child("applications").Index(i), seen, apps, repos)...)
+		seen[app.Name] = true
+	}
+	allErrs = append(allErrs, appErrs...)
+	// Unknown dependencies are reported above; only look for cycles once they are fixed.
+	if len(appErrs) == 0 {
+		if _, err := c.ApplicationOrder(); err != nil {
+			msg := err.Error()
+			if kfErr, ok := kfapis.AsKfError(err); ok {
+				msg = kfErr.Message
+			}
+			allErrs = append(allErrs, field.Invalid(spec.Child("applications"), "", msg))
+		}
+	}
+
+	for i, plugin := range c.Spec.Plugins {
+		allErrs = append(allErrs, validatePlugin(plugin, spec.Child("plugins").Index(i), secrets)...)
+	}
+	return allErrs
+}
+
+func (c *KfConfig) validateApplication(app Application, appPath *field.Path, seen map[string]bool,
+	apps map[string]bool, repos map[string]bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch {
+	case app.Name == "":
+		allErrs = append(allErrs, field.Required(appPath.Child("name"), ""))
+	case seen[app.Name]:
+		allErrs = append(allErrs, field.Duplicate(appPath.Child("name"), app.Name))
+	default:
+		for _, msg := range validation.IsDNS1123Label(app.Name) {
+			allErrs = append(allErrs, field.Invalid(appPath.Child("name"), app.Name, msg))
+		}
+	}
+	if app.Namespace != "" {
+		for _, msg := range validation.IsDNS1123Label(app.Namespace) {
+			allErrs = append(allErrs, field.Invalid(appPath.Child("namespace"), app.Namespace, msg))
+		}
+	}
+	allErrs = append(allErrs, metav1validation.ValidateLabels(app.Labels, appPath.Child("labels"))...)
+	for _, msg := range validation.IsValidLabelValue(app.Version) {
+		allErrs = append(allErrs, field.Invalid(appPath.Child("version"), app.Version, msg))
+	}
+	for j, dep := range app.DependsOn {
+		if !apps[dep] {
+			allErrs = append(allErrs, field.NotFound(appPath.Child("dependsOn").Index(j), dep))
+		}
+	}
+
+	configPath := appPath.Child("kustomizeConfig")
+	if app.KustomizeConfig == nil {
+		return append(allErrs, field.Required(configPath, ""))
+	}
+	repoRef := app.KustomizeConfig.RepoRef
+	if repoRef == nil {
+		return append(allErrs, field.Required(configPath.Child("repoRef"), ""))
+	}
+	if !repos[repoRef.Name] {
+		return append(allErrs, field.NotFound(configPath.Child("repoRef", "name"), repoRef.Name))
+	}
+
+	cache, ok := c.GetRepoCache(repoRef.Name)
+	if !ok || cache.LocalPath == "" {
+		return allErrs
+	}
+	if _, err := os.Stat(cache.LocalPath); err != nil {
+		return allErrs
+	}
+	appDir := path.Join(cache.LocalPath, repoRef.Path)
+	if _, err := os.Stat(appDir); err != nil {
+		return append(allErrs, field.Invalid(configPath.Child("repoRef", "path"), repoRef.Path,
+			fmt.Sprintf("not found in repo %v", repoRef.Name)))
+	}
+	for j, overlay := range app.KustomizeConfig.Overlays {
+		if _, err := os.Stat(path.Join(appDir, "overlays", overlay)); err != nil {
+			allErrs = append(allErrs, field.Invalid(configPath.Child("overlays").Index(j), overlay,
+				fmt.Sprintf("no overlay %v in %v", overlay, path.Join(repoRef.Name, repoRef.Path))))
+		}
+	}
+	return allErrs
+}
+
+func validatePlugin(plugin Plugin, pluginPath *field.Path, secrets map[string]bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+	kindPath := pluginPath.Child("kind")
+	known := false
+	for _, kind := range knownPluginKinds {
+		known = known || string(plugin.Kind) == kind
+	}
+	if !known {
+		return append(allErrs, field.NotSupported(kindPath, plugin.Kind, knownPluginKinds))
+	}
+
+	refs := pluginSecretRefs[plugin.Kind]
+	if len(refs) == 0 || plugin.Spec == nil {
+		return allErrs
+	}
+	spec := map[string]interface{}{}
+	specBytes, err := yaml.Marshal(plugin.Spec)
+	if err == nil {
+		err = yaml.Unmarshal(specBytes, &spec)
+	}
+	if err != nil {
+		return append(allErrs, field.Invalid(pluginPath.Child("spec"), "", err.Error()))
+	}
+	for _, ref := range refs {
+		name, found, _ := unstructured.NestedString(spec, append(ref, "name")...)
+		if !found {
+			continue
+		}
+		if !secrets[name] {
+			refPath := pluginPath.Child("spec").Child(ref[0], ref[1:]...).Child("name")
+			allErrs = append(allErrs, field.NotFound(refPath, name))
+		}
+	}
+	return allErrs
+}
+
+// ValidationError returns errs as a single INVALID_ARGUMENT KfError or nil if errs is empty.
+func ValidationError(errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := []string{}
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return kfapis.NewKfError(kfapis.ErrConfigInvalid, "invalid KfDef: %v", strings.Join(msgs, "; "))
+}
@@ -0,0 +1,119 @@
+package kfconfig
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestValidate(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "kfctl-validate-")
+	if err != nil {
+		t.Fatalf("Couldn't create cache dir: %v", err)
+	}
+	defer os.RemoveAll(cacheDir)
+	if err := os.MkdirAll(path.Join(cacheDir, "jupyter", "overlays", "istio"), os.ModePerm); err != nil {
+		t.Fatalf("Couldn't create overlay: %v", err)
+	}
+
+	config := &KfConfig{}
+	config.Name = "My_Kubeflow"
+	config.Namespace = "kubeflow"
+	config.Spec = KfConfigSpec{
+		Repos: []Repo{
+			{Name: "manifests", URI: "https://github.com/kubeflow/manifests/archive/master.tar.gz", Sha256: "master"},
+			{Name: "kubeflow", URI: "https://github.com/kubeflow/kubeflow/archive/master.tar.gz", Ref: "v1.0", SubPath: "../kustomize"},
+			{Name: "kfctl", URI: "git::https://github.com/kubeflow/kfctl.git?ref=v1.0", Ref: "v1.1"},
+		},
+		Applications: []Application{
+			{
+				Name: "jupyter",
+				KustomizeConfig: &KustomizeConfig{
+					RepoRef:  &RepoRef{Name: "manifests", Path: "jupyter"},
+					Overlays: []string{"istio", "application"},
+				},
+			},
+			{
+				Name: "jupyter",
+				KustomizeConfig: &KustomizeConfig{
+					RepoRef: &RepoRef{Name: "manifests", Path: "jupyter"},
+				},
+			},
+			{
+				Name:      "katib",
+				Namespace: "Katib",
+				DependsOn: []string{"istio"},
+				KustomizeConfig: &KustomizeConfig{
+					RepoRef: &RepoRef{Name: "components", Path: "katib"},
+				},
+			},
+		},
+		Plugins: []Plugin{
+			{
+				Kind: GCP_PLUGIN_KIND,
+				Spec: &runtime.RawExtension{
+					Raw: []byte(`{"auth": {"iap": {"oAuthClientId": "id", "oAuthClientSecret": {"name": "oauth"}}}}`),
+				},
+			},
+			{
+				Kind: AWS_PLUGIN_KIND,
+				Spec: &runtime.RawExtension{
+					Raw: []byte(`{"region": "us-west-2", "auth": {"basicAuth": {"username": "admin", "password": {"name": "admin-password"}}}}`),
+				},
+			},
+			{Kind: "KfAzurePlugin"},
+		},
+	}
+	config.Status.Caches = []Cache{{Name: "manifests", LocalPath: cacheDir}}
+
+	expected := []string{
+		`metadata.name: Invalid value: "My_Kubeflow": a DNS-1123 subdomain must consist of lower case alphanumeric ` +
+			`characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', ` +
+			`regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')`,
+		`spec.repos[0].sha256: Invalid value: "master": must be 64 hexadecimal digits`,
+		`spec.repos[1].ref: Invalid value: "v1.0": only git:: repos have a ref`,
+		`spec.repos[1].subPath: Invalid value: "../kustomize": must be a relative path inside the repo`,
+		`spec.repos[2].uri: Invalid value: "git::https://github.com/kubeflow/kfctl.git?ref=v1.0": ` +
+			`git URI git::https://github.com/kubeflow/kfctl.git?ref=v1.0 has ref v1.0 but the repo has ref v1.1`,
+		`spec.applications[0].kustomizeConfig.overlays[1]: Invalid value: "application": no overlay application in manifests/jupyter`,
+		`spec.applications[1].name: Duplicate value: "jupyter"`,
+		`spec.applications[2].namespace: Invalid value: "Katib": a DNS-1123 label must consist of lower case ` +
+			`alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  ` +
+			`or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`,
+		`spec.applications[2].dependsOn[0]: Not found: "istio"`,
+		`spec.applications[2].kustomizeConfig.repoRef.name: Not found: "components"`,
+		`spec.plugins[0].spec.auth.iap.oAuthClientSecret.name: Not found: "oauth"`,
+		`spec.plugins[1].spec.auth.basicAuth.password.name: Not found: "admin-password"`,
+		`spec.plugins[2].kind: Unsupported value: "KfAzurePlugin": supported values: "KfAwsPlugin", "KfGcpPlugin", ` +
+			`"KfMinikubePlugin", "KfExistingArriktoPlugin"`,
+	}
+	actual := []string{}
+	for _, err := range config.Validate() {
+		actual = append(actual, err.Error())
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Validate() =\n%v\nwant\n%v", actual, expected)
+	}
+}
+
+func TestValidateCycle(t *testing.T) {
+	config := &KfConfig{Spec: KfConfigSpec{
+		Repos: []Repo{{Name: "manifests", URI: "file:/tmp/manifests"}},
+		Applications: []Application{
+			{Name: "a", DependsOn: []string{"b"}, KustomizeConfig: &KustomizeConfig{RepoRef: &RepoRef{Name: "manifests"}}},
+			{Name: "b", DependsOn: []string{"a"}, KustomizeConfig: &KustomizeConfig{RepoRef: &RepoRef{Name: "manifests"}}},
+		},
+	}}
+	errs := config.Validate()
+	expected := `spec.applications: Invalid value: "": applications have a dependency cycle: a -> b -> a`
+	if len(errs) != 1 || errs[0].Error() != expected {
+		t.Errorf("Validate() = %v; want %v", errs, expected)
+	}
+	if err := ValidationError(errs); err == nil {
+		t.Errorf("ValidationError(%v) returned nil", errs)
+	}
+}
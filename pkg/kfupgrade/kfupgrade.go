@@ -30,7 +30,6 @@ import (
 	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
 	kftypesv3 "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
 	kfupgrade "github.com/kubeflow/kfctl/v3/pkg/apis/apps/kfupgrade/v1alpha1"
-	kfdefgcpplugin "github.com/kubeflow/kfctl/v3/pkg/apis/apps/plugins/gcp/v1alpha1"
 	"github.com/kubeflow/kfctl/v3/pkg/kfapp/coordinator"
 	"github.com/kubeflow/kfctl/v3/pkg/kfconfig"
 	kfconfigloaders "github.com/kubeflow/kfctl/v3/pkg/kfconfig/loaders"
@@ -227,7 +226,8 @@ func MergeKfCfg(oldKfCfg *kfconfig.KfConfig, newKfCfg *kfconfig.KfConfig) {
 	}
 
 	for _, kind := range pluginKinds {
-		oldPlugin := kfdefgcpplugin.GcpPluginSpec{}
+		// Copy the spec as is; not every plugin has a GcpPluginSpec.
+		oldPlugin := map[string]interface{}{}
 		err := oldKfCfg.GetPluginSpec(kind, &oldPlugin)
 
 		// If no error, then this plugin is found and we need to copy it to the new KfCfg.
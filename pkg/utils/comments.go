@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KeepComments returns updated, a YAML document derived from original, with the comments
+// of original. Comments are moved to the fields with the same path in updated; list items
+// that are objects with a name are matched by name and other items by index. Fields keep
+// their order in original and new fields follow. Comments of fields that updated doesn't
+// have are lost.
+func KeepComments(original []byte, updated []byte) ([]byte, error) {
+	src := &yaml.Node{}
+	if err := yaml.Unmarshal(original, src); err != nil {
+		return nil, err
+	}
+	dst := &yaml.Node{}
+	if err := yaml.Unmarshal(updated, dst); err != nil {
+		return nil, err
+	}
+	copyComments(src, dst)
+
+	buf := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(dst); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func copyComments(src *yaml.Node, dst *yaml.Node) {
+	if src.Kind != dst.Kind {
+		return
+	}
+	dst.HeadComment = src.HeadComment
+	dst.LineComment = src.LineComment
+	dst.FootComment = src.FootComment
+
+	switch src.Kind {
+	case yaml.DocumentNode:
+		if len(src.Content) > 0 && len(dst.Content) > 0 {
+			copyComments(src.Content[0], dst.Content[0])
+		}
+	case yaml.MappingNode:
+		pairs := map[string][]*yaml.Node{}
+		for i := 0; i+1 < len(dst.Content); i += 2 {
+			pairs[dst.Content[i].Value] = dst.Content[i : i+2]
+		}
+		content := []*yaml.Node{}
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			pair, ok := pairs[src.Content[i].Value]
+			if !ok {
+				continue
+			}
+			copyComments(src.Content[i], pair[0])
+			copyComments(src.Content[i+1], pair[1])
+			content = append(content, pair...)
+			delete(pairs, src.Content[i].Value)
+		}
+		for i := 0; i+1 < len(dst.Content); i += 2 {
+			if _, ok := pairs[dst.Content[i].Value]; ok {
+				content = append(content, dst.Content[i:i+2]...)
+			}
+		}
+		dst.Content = content
+	case yaml.SequenceNode:
+		named := map[string]*yaml.Node{}
+		for _, item := range src.Content {
+			if name := namedItem(item); name != "" {
+				named[name] = item
+			}
+		}
+		for i, item := range dst.Content {
+			if srcItem, ok := named[namedItem(item)]; ok {
+				copyComments(srcItem, item)
+			} else if i < len(src.Content) && namedItem(src.Content[i]) == "" {
+				copyComments(src.Content[i], item)
+			}
+		}
+	}
+}
+
+// namedItem returns the name of a list item that is an object with a name, or "".
+func namedItem(node *yaml.Node) string {
+	if node.Kind != yaml.MappingNode {
+		return ""
+	}
+	return mappingValue(node, "name")
+}
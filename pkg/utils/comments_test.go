@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestKeepComments(t *testing.T) {
+	original := []byte(`# Kubeflow on GCP.
+apiVersion: v1
+kind: Test
+spec:
+  # Installed in order.
+  applications:
+  - name: b
+    # Pinned for the demo.
+    version: "1"
+  - name: a
+    overlay: istio # renamed
+  plugins:
+  - zone: us-east1-d # closest zone
+`)
+	updated := []byte(`apiVersion: v2
+kind: Test
+spec:
+  applications:
+  - name: a
+    overlays:
+    - istio
+  - name: b
+    version: "1"
+  plugins:
+  - zone: us-east1-d
+  repos:
+  - name: manifests
+`)
+	expected := `# Kubeflow on GCP.
+apiVersion: v2
+kind: Test
+spec:
+  # Installed in order.
+  applications:
+    - name: a
+      overlays:
+        - istio
+    - name: b
+      # Pinned for the demo.
+      version: "1"
+  plugins:
+    - zone: us-east1-d # closest zone
+  repos:
+    - name: manifests
+`
+	actual, err := KeepComments(original, updated)
+	if err != nil {
+		t.Fatalf("KeepComments failed: %v", err)
+	}
+	if string(actual) != expected {
+		t.Errorf("KeepComments =\n%s\nwant\n%s", actual, expected)
+	}
+}
@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		expected string
+	}{
+		{
+			name:     "identical",
+			from:     "a\nb\n",
+			to:       "a\nb\n",
+			expected: "",
+		},
+		{
+			name: "added",
+			from: "",
+			to:   "a\nb\n",
+			expected: "--- live\n+++ rendered\n" +
+				"@@ -0,0 +1,2 @@\n" +
+				"+a\n" +
+				"+b\n",
+		},
+		{
+			name: "changed",
+			from: "a\nb\nc\nd\ne\nf\ng\nh\n",
+			to:   "a\nb\nc\nd\nE\nf\ng\nh\n",
+			expected: "--- live\n+++ rendered\n" +
+				"@@ -2,7 +2,7 @@\n" +
+				" b\n" +
+				" c\n" +
+				" d\n" +
+				"-e\n" +
+				"+E\n" +
+				" f\n" +
+				" g\n" +
+				" h\n",
+		},
+		{
+			name: "separate hunks",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			to:   "0\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n13\n",
+			expected: "--- live\n+++ rendered\n" +
+				"@@ -1,4 +1,4 @@\n" +
+				"-1\n" +
+				"+0\n" +
+				" 2\n" +
+				" 3\n" +
+				" 4\n" +
+				"@@ -9,4 +9,4 @@\n" +
+				" 9\n" +
+				" 10\n" +
+				" 11\n" +
+				"-12\n" +
+				"+13\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := UnifiedDiff("live", "rendered", test.from, test.to)
+			if actual != test.expected {
+				PrintDiff(actual, test.expected)
+				t.Errorf("Unexpected diff")
+			}
+		})
+	}
+}
+
+func TestStripAndProject(t *testing.T) {
+	live := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":            "dashboard",
+			"namespace":       "kubeflow",
+			"resourceVersion": "42",
+			"uid":             "1234",
+			"managedFields":   []interface{}{map[string]interface{}{"manager": "kfctl"}},
+			"annotations": map[string]interface{}{
+				LastAppliedConfigAnnotation: "{}",
+			},
+		},
+		"spec": map[string]interface{}{
+			"replicas":             int64(1),
+			"revisionHistoryLimit": int64(10),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":                     "dashboard",
+							"image":                    "dashboard:v1",
+							"terminationMessagePath":   "/dev/termination-log",
+							"terminationMessagePolicy": "File",
+						},
+					},
+				},
+			},
+		},
+		"status": map[string]interface{}{
+			"readyReplicas": int64(1),
+		},
+	}}
+	desired := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "dashboard",
+			"namespace": "kubeflow",
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(1),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":  "dashboard",
+							"image": "dashboard:v2",
+						},
+					},
+				},
+			},
+		},
+	}}
+	expected := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "dashboard",
+			"namespace": "kubeflow",
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(1),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":  "dashboard",
+							"image": "dashboard:v1",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	actual := ProjectLiveObject(StripServerFields(live), desired)
+	if !reflect.DeepEqual(actual.Object, expected) {
+		t.Errorf("Unexpected projection:\n%v", PrettyPrint(actual.Object))
+	}
+	if _, ok := live.Object["status"]; !ok {
+		t.Errorf("StripServerFields must not modify its input")
+	}
+}
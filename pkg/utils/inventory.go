@@ -0,0 +1,234 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// InventoryLabel is set on inventory ConfigMaps to the name of the KfDef that owns them.
+	InventoryLabel = "kfctl.kubeflow.io/inventory"
+	// InventoryAppAnnotation records the application an inventory ConfigMap belongs to.
+	InventoryAppAnnotation = "kfctl.kubeflow.io/application"
+	inventoryPrefix        = "kfctl-inventory-"
+	inventoryDataKey       = "objects"
+)
+
+// ObjectRef identifies an object applied by kfctl.
+type ObjectRef struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+// NewObjectRef returns the reference for obj.
+func NewObjectRef(obj *unstructured.Unstructured) ObjectRef {
+	return ObjectRef{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+}
+
+// Key returns a human readable identifier, e.g. Deployment/kubeflow/centraldashboard.
+func (r ObjectRef) Key() string {
+	return ObjectKey(r.Object())
+}
+
+// Object returns an unstructured object carrying only the identifying fields of r.
+func (r ObjectRef) Object() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(r.APIVersion)
+	obj.SetKind(r.Kind)
+	obj.SetNamespace(r.Namespace)
+	obj.SetName(r.Name)
+	return obj
+}
+
+// identity ignores the API group and version. Kinds like Deployment are served by several
+// groups and moving an object to another group must not prune the live object.
+func (r ObjectRef) identity() string {
+	return strings.Join([]string{r.Kind, r.Namespace, r.Name}, "/")
+}
+
+// ObsoleteObjects returns the objects in previous that are not in current, in reverse order
+// so that dependents are removed before the objects they depend on.
+func ObsoleteObjects(previous []ObjectRef, current []ObjectRef) []ObjectRef {
+	keep := map[string]bool{}
+	for _, ref := range current {
+		keep[ref.identity()] = true
+	}
+	obsolete := []ObjectRef{}
+	for i := len(previous) - 1; i >= 0; i-- {
+		if !keep[previous[i].identity()] {
+			obsolete = append(obsolete, previous[i])
+		}
+	}
+	return obsolete
+}
+
+// MergeObjects returns current followed by the objects of previous that current doesn't contain.
+func MergeObjects(current []ObjectRef, previous []ObjectRef) []ObjectRef {
+	merged := append([]ObjectRef{}, current...)
+	seen := map[string]bool{}
+	for _, ref := range current {
+		seen[ref.identity()] = true
+	}
+	for _, ref := range previous {
+		if !seen[ref.identity()] {
+			seen[ref.identity()] = true
+			merged = append(merged, ref)
+		}
+	}
+	return merged
+}
+
+// Inventory records the objects kfctl applied for every application of a KfDef.
+// Each application is stored in its own ConfigMap in the KfDef namespace.
+type Inventory struct {
+	clientset kubernetes.Interface
+	namespace string
+	kfDefName string
+}
+
+// NewInventory returns the inventory of KfDef kfDefName stored in namespace.
+func NewInventory(clientset kubernetes.Interface, namespace string, kfDefName string) *Inventory {
+	return &Inventory{
+		clientset: clientset,
+		namespace: namespace,
+		kfDefName: kfDefName,
+	}
+}
+
+// InventoryName returns the name of the ConfigMap holding the inventory of app of KfDef
+// kfDefName. The hash of both names keeps KfDefs in one namespace apart even if their names
+// only differ in where a dash is or in case.
+func InventoryName(kfDefName string, app string) string {
+	hash := sha256.Sum256([]byte(kfDefName + "/" + app))
+	return fmt.Sprintf("%v%v-%v-%x", inventoryPrefix, kfDefName, strings.ToLower(app), hash[:4])
+}
+
+// legacyInventoryName returns the name inventories of app had before InventoryName included
+// the KfDef name.
+func legacyInventoryName(app string) string {
+	return inventoryPrefix + strings.ToLower(app)
+}
+
+// Load returns the recorded objects of every application keyed by application name.
+func (i *Inventory) Load() (map[string][]ObjectRef, error) {
+	configMaps, err := i.clientset.CoreV1().ConfigMaps(i.namespace).List(metav1.ListOptions{
+		LabelSelector: InventoryLabel + "=" + i.kfDefName,
+	})
+	if err != nil {
+		return nil, ClusterError(err, kfapis.INTERNAL_ERROR, "couldn't list inventories in namespace %v", i.namespace)
+	}
+	inventories := map[string][]ObjectRef{}
+	for _, cm := range configMaps.Items {
+		app := cm.Annotations[InventoryAppAnnotation]
+		if app == "" {
+			log.Warnf("Ignoring inventory %v without %v annotation", cm.Name, InventoryAppAnnotation)
+			continue
+		}
+		refs := []ObjectRef{}
+		if err := json.Unmarshal([]byte(cm.Data[inventoryDataKey]), &refs); err != nil {
+			return nil, &kfapis.KfError{
+				Code:    int(kfapis.INTERNAL_ERROR),
+				Message: fmt.Sprintf("couldn't parse inventory %v: %v", cm.Name, err),
+			}
+		}
+		// Inventories saved since they were renamed win over legacy ones.
+		if _, ok := inventories[app]; ok && cm.Name == legacyInventoryName(app) {
+			continue
+		}
+		inventories[app] = refs
+	}
+	return inventories, nil
+}
+
+// Save records refs as the objects of app, replacing the previous inventory.
+func (i *Inventory) Save(app string, refs []ObjectRef) error {
+	data, err := json.Marshal(refs)
+	if err != nil {
+		return &kfapis.KfError{
+			Code:    int(kfapis.INTERNAL_ERROR),
+			Message: fmt.Sprintf("couldn't marshal inventory of %v: %v", app, err),
+		}
+	}
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        InventoryName(i.kfDefName, app),
+			Namespace:   i.namespace,
+			Labels:      map[string]string{InventoryLabel: i.kfDefName},
+			Annotations: map[string]string{InventoryAppAnnotation: app},
+		},
+		Data: map[string]string{inventoryDataKey: string(data)},
+	}
+	configMaps := i.clientset.CoreV1().ConfigMaps(i.namespace)
+	existing, err := configMaps.Get(cm.Name, metav1.GetOptions{})
+	switch {
+	case k8serrors.IsNotFound(err):
+		_, err = configMaps.Create(cm)
+	case err == nil:
+		cm.ResourceVersion = existing.ResourceVersion
+		_, err = configMaps.Update(cm)
+	}
+	if err != nil {
+		return &kfapis.KfError{
+			Code:    int(kfapis.INTERNAL_ERROR),
+			Message: fmt.Sprintf("couldn't save inventory of %v: %v", app, err),
+		}
+	}
+	return i.removeLegacy(app)
+}
+
+// Remove deletes the inventory of app.
+func (i *Inventory) Remove(app string) error {
+	err := i.clientset.CoreV1().ConfigMaps(i.namespace).Delete(InventoryName(i.kfDefName, app), &metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return &kfapis.KfError{
+			Code:    int(kfapis.INTERNAL_ERROR),
+			Message: fmt.Sprintf("couldn't remove inventory of %v: %v", app, err),
+		}
+	}
+	return i.removeLegacy(app)
+}
+
+// removeLegacy deletes the inventory of app under its legacy name if it belongs to this KfDef.
+func (i *Inventory) removeLegacy(app string) error {
+	configMaps := i.clientset.CoreV1().ConfigMaps(i.namespace)
+	name := legacyInventoryName(app)
+	cm, err := configMaps.Get(name, metav1.GetOptions{})
+	if err == nil && cm.Labels[InventoryLabel] == i.kfDefName && cm.Annotations[InventoryAppAnnotation] == app {
+		err = configMaps.Delete(name, &metav1.DeleteOptions{})
+	}
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return &kfapis.KfError{
+			Code:    int(kfapis.INTERNAL_ERROR),
+			Message: fmt.Sprintf("couldn't remove legacy inventory %v: %v", name, err),
+		}
+	}
+	return nil
+}
+
+// Applications returns the names of the applications in inventories in sorted order.
+func Applications(inventories map[string][]ObjectRef) []string {
+	apps := []string{}
+	for app := range inventories {
+		apps = append(apps, app)
+	}
+	sort.Strings(apps)
+	return apps
+}
@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestObsoleteObjects(t *testing.T) {
+	previous := []ObjectRef{
+		{APIVersion: "v1", Kind: "ServiceAccount", Namespace: "kubeflow", Name: "dashboard"},
+		{APIVersion: "extensions/v1beta1", Kind: "Deployment", Namespace: "kubeflow", Name: "dashboard"},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "kubeflow", Name: "old-config"},
+		{APIVersion: "v1", Kind: "Service", Namespace: "kubeflow", Name: "old-service"},
+	}
+	current := []ObjectRef{
+		{APIVersion: "v1", Kind: "ServiceAccount", Namespace: "kubeflow", Name: "dashboard"},
+		{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "kubeflow", Name: "dashboard"},
+	}
+	expected := []ObjectRef{
+		{APIVersion: "v1", Kind: "Service", Namespace: "kubeflow", Name: "old-service"},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "kubeflow", Name: "old-config"},
+	}
+	if actual := ObsoleteObjects(previous, current); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("ObsoleteObjects() = %v; want %v", actual, expected)
+	}
+
+	merged := MergeObjects(current, previous)
+	if len(merged) != 4 || merged[3].Name != "old-service" || merged[1].APIVersion != "apps/v1" {
+		t.Errorf("Unexpected MergeObjects() result %v", merged)
+	}
+}
+
+func TestInventorySaveLoad(t *testing.T) {
+	inventory := NewInventory(fake.NewSimpleClientset(), "kubeflow", "kubeflow-app")
+	refs := []ObjectRef{
+		{APIVersion: "v1", Kind: "Service", Namespace: "kubeflow", Name: "dashboard"},
+	}
+	if err := inventory.Save("CentralDashboard", refs); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	refs = append(refs, ObjectRef{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Name: "dashboard"})
+	if err := inventory.Save("CentralDashboard", refs); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	loaded, err := inventory.Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, map[string][]ObjectRef{"CentralDashboard": refs}) {
+		t.Errorf("Load() = %v; want %v", loaded, refs)
+	}
+
+	if err := inventory.Remove("CentralDashboard"); err != nil {
+		t.Fatalf("Remove() failed: %v", err)
+	}
+	loaded, err = inventory.Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if len(loaded) != 0 {
+		t.Errorf("Load() after Remove() = %v; want no inventories", loaded)
+	}
+}
+
+func TestInventorySameApp(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	first := NewInventory(clientset, "kubeflow", "kubeflow-app")
+	second := NewInventory(clientset, "kubeflow", "kubeflow")
+	firstRefs := []ObjectRef{{APIVersion: "v1", Kind: "Service", Namespace: "kubeflow", Name: "first"}}
+	secondRefs := []ObjectRef{{APIVersion: "v1", Kind: "Service", Namespace: "kubeflow", Name: "second"}}
+	if err := first.Save("dashboard", firstRefs); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	// The names only differ in where the dash is.
+	if err := second.Save("app-dashboard", secondRefs); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	if err := second.Save("dashboard", secondRefs); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	loaded, err := first.Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, map[string][]ObjectRef{"dashboard": firstRefs}) {
+		t.Errorf("Load() = %v; want only the inventory of the first KfDef", loaded)
+	}
+}
+
+func TestInventoryLegacy(t *testing.T) {
+	legacy := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        legacyInventoryName("dashboard"),
+			Namespace:   "kubeflow",
+			Labels:      map[string]string{InventoryLabel: "kubeflow-app"},
+			Annotations: map[string]string{InventoryAppAnnotation: "dashboard"},
+		},
+		Data: map[string]string{inventoryDataKey: `[{"apiVersion": "v1", "kind": "Service", "name": "old"}]`},
+	}
+	clientset := fake.NewSimpleClientset(legacy)
+	inventory := NewInventory(clientset, "kubeflow", "kubeflow-app")
+	loaded, err := inventory.Load()
+	if err != nil || len(loaded["dashboard"]) != 1 || loaded["dashboard"][0].Name != "old" {
+		t.Fatalf("Load() = %v, %v; want the legacy inventory", loaded, err)
+	}
+
+	refs := []ObjectRef{{APIVersion: "v1", Kind: "Service", Namespace: "kubeflow", Name: "new"}}
+	if err := inventory.Save("dashboard", refs); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	configMaps, err := clientset.CoreV1().ConfigMaps("kubeflow").List(metav1.ListOptions{})
+	if err != nil || len(configMaps.Items) != 1 || configMaps.Items[0].Name != InventoryName("kubeflow-app", "dashboard") {
+		t.Errorf("ConfigMaps after Save() = %v, %v; want the legacy inventory replaced", configMaps, err)
+	}
+}
@@ -16,7 +16,6 @@ package utils
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/cenkalti/backoff"
 	"github.com/ghodss/yaml"
@@ -24,28 +23,17 @@ import (
 	gogetter "github.com/hashicorp/go-getter"
 	configtypes "github.com/kubeflow/kfctl/v3/config"
 	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
-	kftypes "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"io"
 	"io/ioutil"
-	"k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	k8stypes "k8s.io/apimachinery/pkg/types"
 	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
-	"k8s.io/cli-runtime/pkg/genericclioptions"
-	"k8s.io/cli-runtime/pkg/printers"
-	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	kubectlapply "k8s.io/kubernetes/pkg/kubectl/cmd/apply"
-	kubectldelete "k8s.io/kubernetes/pkg/kubectl/cmd/delete"
-	cmdutil "k8s.io/kubernetes/pkg/kubectl/cmd/util"
-	"math/rand"
 	netUrl "net/url"
-	"os"
 	"path"
 	"regexp"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -59,7 +47,6 @@ import (
 
 const (
 	YamlSeparator              = "(?m)^---[ \t]*$"
-	CertDir                    = "/opt/ca"
 	controlPlaneLabel          = "control-plane"
 	katibMetricsCollectorLabel = "katib-metricscollector-injection"
 	KfDefAnnotation            = "kfctl.kubeflow.io"
@@ -67,17 +54,19 @@ const (
 	SetAnnotation              = "set-kubeflow-annotation"
 	KfDefInstance              = "kfdef-instance"
 	InstallByOperator          = "install-by-operator"
+	ForceConflicts             = "force-conflicts"
+	Prune                      = "prune"
+	PruneDryRun                = "prune-dry-run"
+	Wait                       = "wait"
+	WaitTimeout                = "wait-timeout"
+	Parallelism                = "parallelism"
+	Strict                     = "strict"
+	ApplicationSettings        = "application-settings"
+	Apps                       = "apps"
+	SkipApps                   = "skip-apps"
+	RunAnnotations             = "run-annotations"
 )
 
-func generateRandStr(length int) string {
-	chars := "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = chars[rand.Intn(len(chars))]
-	}
-	return string(b)
-}
-
 func NewDefaultBackoff() *backoff.ExponentialBackOff {
 	b := backoff.NewExponentialBackOff()
 	b.InitialInterval = 3 * time.Second
@@ -252,280 +241,6 @@ func DeleteResourceFromFile(config *rest.Config, filename string) error {
 	return nil
 }
 
-type Apply struct {
-	matchVersionKubeConfigFlags *cmdutil.MatchVersionFlags
-	factory                     cmdutil.Factory
-	clientset                   *kubernetes.Clientset
-	options                     *kubectlapply.ApplyOptions
-	tmpfile                     *os.File
-	stdin                       *os.File
-}
-
-func NewApply(namespace string, restConfig *rest.Config) (*Apply, error) {
-	configFlags := genericclioptions.NewConfigFlags(false)
-	if restConfig != nil {
-		certFile := path.Join(CertDir, generateRandStr(10))
-		if err := ioutil.WriteFile(certFile, restConfig.TLSClientConfig.CAData, 0644); err != nil {
-			return nil, err
-		}
-		configFlags.CAFile = &certFile
-		configFlags.BearerToken = &(restConfig.BearerToken)
-		configFlags.APIServer = &(restConfig.Host)
-	}
-	apply := &Apply{
-		matchVersionKubeConfigFlags: cmdutil.NewMatchVersionFlags(configFlags),
-	}
-	apply.factory = cmdutil.NewFactory(apply.matchVersionKubeConfigFlags)
-	clientset, err := apply.factory.KubernetesClientSet()
-	if err != nil {
-		return nil, &kfapis.KfError{
-			Code:    int(kfapis.INTERNAL_ERROR),
-			Message: fmt.Sprintf("could not get clientset: %v", err),
-		}
-	}
-	apply.clientset = clientset
-	err = apply.namespace(namespace)
-	if err != nil {
-		return nil, err
-	}
-
-	// Change behaviour of error from exit to panic.
-	cmdutil.BehaviorOnFatal(func(s string, i int) {
-		panic(fmt.Errorf("Encountered error: %s. Exiting with code: %d.", s, i))
-	})
-
-	return apply, nil
-}
-
-func (a *Apply) IfNamespaceExist(name string) bool {
-	_, nsMissingErr := a.clientset.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
-	if nsMissingErr != nil {
-		return false
-	}
-	return true
-}
-
-func (a *Apply) Apply(data []byte) error {
-	a.tmpfile = a.tempFile(data)
-	a.stdin = os.Stdin
-	os.Stdin = a.tmpfile
-	defer a.cleanup()
-	ioStreams := genericclioptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
-	a.options = kubectlapply.NewApplyOptions(ioStreams)
-	a.options.DeleteFlags = a.deleteFlags("that contains the configuration to apply")
-	initializeErr := a.init()
-	if initializeErr != nil {
-		return &kfapis.KfError{
-			Code:    int(kfapis.INTERNAL_ERROR),
-			Message: fmt.Sprintf("could not initialize : %v", initializeErr),
-		}
-	}
-	var err error
-	func() {
-		defer func() {
-			if temp := recover(); temp != nil {
-				err = temp.(error)
-			}
-		}()
-		err = a.run()
-	}()
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func (a *Apply) run() error {
-	resourcesErr := a.options.Run()
-	if resourcesErr != nil {
-		return &kfapis.KfError{
-			Code:    int(kfapis.INTERNAL_ERROR),
-			Message: fmt.Sprintf("Apply.Run : %v", resourcesErr),
-		}
-	}
-	return nil
-}
-
-func (a *Apply) cleanup() error {
-	os.Stdin = a.stdin
-	if a.tmpfile != nil {
-		if err := a.tmpfile.Close(); err != nil {
-			return err
-		}
-		return os.Remove(a.tmpfile.Name())
-	}
-	return nil
-}
-
-func (a *Apply) init() error {
-	var err error
-	var o = a.options
-	var f = a.factory
-	// allow for a success message operation to be specified at print time
-	o.ToPrinter = func(operation string) (printers.ResourcePrinter, error) {
-		o.PrintFlags.NamePrintFlags.Operation = operation
-		if o.DryRun {
-			err = o.PrintFlags.Complete("%s (dry run)")
-			if err != nil {
-				return nil, err
-			}
-		}
-		if o.ServerDryRun {
-			err = o.PrintFlags.Complete("%s (server dry run)")
-			if err != nil {
-				return nil, err
-			}
-		}
-		return o.PrintFlags.ToPrinter()
-	}
-	o.DiscoveryClient, err = f.ToDiscoveryClient()
-	if err != nil {
-		return err
-	}
-	dynamicClient, err := f.DynamicClient()
-	if err != nil {
-		return err
-	}
-	o.DeleteOptions = o.DeleteFlags.ToOptions(dynamicClient, o.IOStreams)
-	o.OpenAPIPatch = true
-	o.OpenAPISchema, _ = f.OpenAPISchema()
-	o.Validator, err = f.Validator(false)
-	o.Builder = f.NewBuilder()
-	o.Mapper, err = f.ToRESTMapper()
-	if err != nil {
-		return err
-	}
-	o.DynamicClient, err = f.DynamicClient()
-	if err != nil {
-		return err
-	}
-	o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace()
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func (a *Apply) patchNamespaceWithLabel(namespace string, labelKey string,
-	labelValue string) error {
-	var labelPatchMap = map[string]metav1.ObjectMeta{
-		"metadata": metav1.ObjectMeta{
-			Labels: map[string]string{labelKey: labelValue},
-		},
-	}
-	labelPatchJSON, err := json.Marshal(labelPatchMap)
-	if err != nil {
-		return err
-	}
-	log.Infof("Labeling Namespace: %v", namespace)
-	_, err = a.clientset.CoreV1().Namespaces().Patch(
-		namespace,
-		"application/strategic-merge-patch+json",
-		[]byte(labelPatchJSON),
-	)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func (a *Apply) namespace(namespace string) error {
-	log.Infof(string(kftypes.NAMESPACE)+": %v", namespace)
-	namespaceInstance, nsMissingErr := a.clientset.CoreV1().Namespaces().Get(
-		namespace, metav1.GetOptions{},
-	)
-	if nsMissingErr != nil {
-		log.Infof("Creating namespace: %v", namespace)
-		nsSpec := &v1.Namespace{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: namespace,
-				Labels: map[string]string{
-					controlPlaneLabel:          "kubeflow",
-					katibMetricsCollectorLabel: "enabled",
-				},
-			},
-		}
-		_, nsErr := a.clientset.CoreV1().Namespaces().Create(nsSpec)
-		if nsErr != nil {
-			return &kfapis.KfError{
-				Code: int(kfapis.INVALID_ARGUMENT),
-				Message: fmt.Sprintf("couldn't create %v %v Error: %v",
-					string(kftypes.NAMESPACE), namespace, nsErr),
-			}
-		}
-	} else {
-		if _, ok := namespaceInstance.ObjectMeta.Labels[controlPlaneLabel]; !ok {
-			patchErr := a.patchNamespaceWithLabel(
-				namespace, controlPlaneLabel, "kubeflow",
-			)
-			if patchErr != nil {
-				return &kfapis.KfError{
-					Code:    int(kfapis.INTERNAL_ERROR),
-					Message: fmt.Sprintf("couldn't patch %v Error: %v", namespace, patchErr),
-				}
-			}
-		}
-		if _, ok := namespaceInstance.ObjectMeta.Labels[katibMetricsCollectorLabel]; !ok {
-			patchErr := a.patchNamespaceWithLabel(
-				namespace, katibMetricsCollectorLabel, "enabled",
-			)
-			if patchErr != nil {
-				return &kfapis.KfError{
-					Code:    int(kfapis.INTERNAL_ERROR),
-					Message: fmt.Sprintf("couldn't patch %v Error: %v", namespace, patchErr),
-				}
-			}
-
-		}
-	}
-	return nil
-}
-
-func (a *Apply) tempFile(data []byte) *os.File {
-	tmpfile, err := ioutil.TempFile("/tmp", "kout")
-	if err != nil {
-		log.Fatal(err)
-	}
-	if _, err := tmpfile.Write(data); err != nil {
-		log.Fatal(err)
-	}
-	if _, err := tmpfile.Seek(0, 0); err != nil {
-		log.Fatal(err)
-	}
-	return tmpfile
-}
-
-func (a *Apply) deleteFlags(usage string) *kubectldelete.DeleteFlags {
-	cascade := true
-	gracePeriod := -1
-	// setup command defaults
-	all := false
-	force := false
-	ignoreNotFound := false
-	now := false
-	output := ""
-	labelSelector := ""
-	fieldSelector := ""
-	timeout := time.Duration(0)
-	wait := true
-	filenames := []string{a.tmpfile.Name()}
-	recursive := false
-	return &kubectldelete.DeleteFlags{
-		FileNameFlags:  &genericclioptions.FileNameFlags{Usage: usage, Filenames: &filenames, Recursive: &recursive},
-		LabelSelector:  &labelSelector,
-		FieldSelector:  &fieldSelector,
-		Cascade:        &cascade,
-		GracePeriod:    &gracePeriod,
-		All:            &all,
-		Force:          &force,
-		IgnoreNotFound: &ignoreNotFound,
-		Now:            &now,
-		Timeout:        &timeout,
-		Wait:           &wait,
-		Output:         &output,
-	}
-}
-
 // DeleteResource removes resource. Prior to that it checks whether the resource is created through the kubeflow operator.
 // always removes the resource if it is not created by the Kubeflow operator, otherwise checks the annotation to
 // be sure the resource is part of the deployment and then remove.
@@ -6,15 +6,15 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-// PrettyPrint returns a pretty format output of any value.
+// PrettyPrint returns a pretty format output of any value. Secrets are redacted.
 func PrettyPrint(value interface{}) string {
 	if s, ok := value.(string); ok {
-		return s
+		return RedactString(s)
 	}
-	valueJson, err := json.MarshalIndent(value, "", "  ")
+	valueJson, err := json.MarshalIndent(Redact(value), "", "  ")
 	if err != nil {
 		log.Errorf("Failed to marshal value; error %v", err)
-		return fmt.Sprintf("%+v", value)
+		return RedactString(fmt.Sprintf("%+v", value))
 	}
-	return string(valueJson)
+	return RedactString(string(valueJson))
 }
@@ -0,0 +1,260 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	kftypes "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+)
+
+const (
+	// LastAppliedConfigAnnotation is the annotation kubectl apply uses to store the applied configuration.
+	LastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+	defaultObjectNamespace      = "default"
+)
+
+// ObjectClient reads and writes arbitrary objects using the dynamic client.
+// REST mappings are discovered lazily and cached; the cache is reset when a kind
+// can't be resolved so that CRDs created earlier in the same run become visible.
+type ObjectClient struct {
+	dynamic   dynamic.Interface
+	mapper    *restmapper.DeferredDiscoveryRESTMapper
+	namespace string
+}
+
+// NewObjectClient creates an ObjectClient for the cluster described by config.
+// Namespaced objects that don't set a namespace are resolved in defaultNamespace;
+// "default" is used when defaultNamespace is empty.
+func NewObjectClient(config *rest.Config, defaultNamespace string) (*ObjectClient, error) {
+	if config == nil {
+		return nil, &kfapis.KfError{
+			Code:    int(kfapis.INVALID_ARGUMENT),
+			Message: "could not create object client: no rest config",
+		}
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, &kfapis.KfError{
+			Code:    int(kfapis.INTERNAL_ERROR),
+			Message: fmt.Sprintf("could not create dynamic client: %v", err),
+		}
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, &kfapis.KfError{
+			Code:    int(kfapis.INTERNAL_ERROR),
+			Message: fmt.Sprintf("could not create discovery client: %v", err),
+		}
+	}
+	if defaultNamespace == "" {
+		defaultNamespace = defaultObjectNamespace
+	}
+	return &ObjectClient{
+		dynamic:   dynamicClient,
+		mapper:    restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient)),
+		namespace: defaultNamespace,
+	}, nil
+}
+
+// ResourceFor returns the dynamic resource interface for obj. If obj is namespaced and
+// has no namespace, the client's default namespace is set on obj.
+func (c *ObjectClient) ResourceFor(obj *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil && meta.IsNoMatchError(err) {
+		// The kind may have been registered by a CRD since discovery was cached.
+		c.mapper.Reset()
+		mapping, err = c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(c.namespace)
+		}
+		return c.dynamic.Resource(mapping.Resource).Namespace(obj.GetNamespace()), nil
+	}
+	return c.dynamic.Resource(mapping.Resource), nil
+}
+
+// Get returns the live version of obj. It returns nil without an error if the object doesn't exist.
+func (c *ObjectClient) Get(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	resource, err := c.ResourceFor(obj)
+	if err != nil {
+		return nil, err
+	}
+	live, err := resource.Get(obj.GetName(), metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return live, nil
+}
+
+// Delete deletes obj and lets the garbage collector remove its dependents in the background.
+// It returns false without an error if the object doesn't exist.
+func (c *ObjectClient) Delete(obj *unstructured.Unstructured) (bool, error) {
+	resource, err := c.ResourceFor(obj)
+	if err != nil {
+		return false, err
+	}
+	propagation := metav1.DeletePropagationBackground
+	err = resource.Delete(obj.GetName(), &metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ParseObjects splits a multi document YAML manifest into unstructured objects.
+// Empty documents are skipped.
+func ParseObjects(data []byte) ([]*unstructured.Unstructured, error) {
+	docs, err := SplitYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	objects := []*unstructured.Unstructured{}
+	for _, doc := range docs {
+		obj := map[string]interface{}{}
+		if err := yaml.Unmarshal(doc, &obj); err != nil {
+			return nil, err
+		}
+		if len(obj) == 0 {
+			continue
+		}
+		objects = append(objects, &unstructured.Unstructured{Object: obj})
+	}
+	return objects, nil
+}
+
+// ObjectKey returns a human readable identifier for obj, e.g. Deployment/kubeflow/centraldashboard.
+func ObjectKey(obj *unstructured.Unstructured) string {
+	parts := []string{obj.GetKind()}
+	if obj.GetNamespace() != "" {
+		parts = append(parts, obj.GetNamespace())
+	}
+	parts = append(parts, obj.GetName())
+	return strings.Join(parts, "/")
+}
+
+// StripServerFields returns a copy of obj without the fields maintained by the API server:
+// status, managed fields, resource versions, uids, timestamps and the last applied annotation.
+func StripServerFields(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	stripped := obj.DeepCopy()
+	unstructured.RemoveNestedField(stripped.Object, "status")
+	for _, field := range []string{"managedFields", "resourceVersion", "uid", "selfLink",
+		"creationTimestamp", "generation", "deletionTimestamp", "deletionGracePeriodSeconds"} {
+		unstructured.RemoveNestedField(stripped.Object, "metadata", field)
+	}
+	annotations := stripped.GetAnnotations()
+	if _, ok := annotations[LastAppliedConfigAnnotation]; ok {
+		delete(annotations, LastAppliedConfigAnnotation)
+		if len(annotations) == 0 {
+			unstructured.RemoveNestedField(stripped.Object, "metadata", "annotations")
+		} else {
+			stripped.SetAnnotations(annotations)
+		}
+	}
+	return stripped
+}
+
+// ProjectLiveObject restricts live to the fields that are set in desired.
+// Fields defaulted by the API server would otherwise show up as differences even though
+// kfctl never manages them. Lists are compared element by element.
+func ProjectLiveObject(live *unstructured.Unstructured, desired *unstructured.Unstructured) *unstructured.Unstructured {
+	projected, _ := project(live.Object, desired.Object).(map[string]interface{})
+	if projected == nil {
+		projected = map[string]interface{}{}
+	}
+	return &unstructured.Unstructured{Object: projected}
+}
+
+func project(live interface{}, desired interface{}) interface{} {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return live
+		}
+		out := map[string]interface{}{}
+		for key, dv := range d {
+			if lv, ok := l[key]; ok {
+				out[key] = project(lv, dv)
+			}
+		}
+		return out
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			return live
+		}
+		out := make([]interface{}, len(l))
+		for i := range l {
+			if i < len(d) {
+				out[i] = project(l[i], d[i])
+			} else {
+				out[i] = l[i]
+			}
+		}
+		return out
+	default:
+		return live
+	}
+}
+
+// KubeConfigNamespace returns the namespace of the current context in $HOME/.kube/config.
+// It returns the empty string if there is no current context or it doesn't set a namespace.
+func KubeConfigNamespace() string {
+	kubeconfig := kftypes.GetKubeConfig()
+	if kubeconfig == nil {
+		return ""
+	}
+	if ctx, ok := kubeconfig.Contexts[kubeconfig.CurrentContext]; ok && ctx != nil {
+		return ctx.Namespace
+	}
+	return ""
+}
+
+// IsClusterUnreachable returns true if err means the API server couldn't be reached,
+// as opposed to a request the API server rejected.
+func IsClusterUnreachable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if k8serrors.IsServiceUnavailable(err) || k8serrors.IsServerTimeout(err) || k8serrors.IsTimeout(err) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// ClusterError returns a KfError for err returned by the API server. Errors reaching the
+// cluster are reported as UNAVAILABLE and any other error with code.
+func ClusterError(err error, code kfapis.StatusCode, format string, args ...interface{}) error {
+	if IsClusterUnreachable(err) {
+		return kfapis.WrapKfErrorAs(kfapis.ErrClusterUnreachable, err, format, args...)
+	}
+	return &kfapis.KfError{
+		Code:    int(code),
+		Message: fmt.Sprintf(format+": %v", append(args, err)...),
+	}
+}
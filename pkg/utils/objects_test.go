@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"syscall"
+	"testing"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestClusterError(t *testing.T) {
+	type testCase struct {
+		err      error
+		expected kfapis.StatusCode
+	}
+	tests := []testCase{
+		{
+			err: &url.Error{
+				Op:  "Get",
+				URL: "https://127.0.0.1:6443/api/v1/namespaces/kubeflow",
+				Err: syscall.ECONNREFUSED,
+			},
+			expected: kfapis.UNAVAILABLE,
+		},
+		{
+			err:      k8serrors.NewServiceUnavailable("apiserver is shutting down"),
+			expected: kfapis.UNAVAILABLE,
+		},
+		{
+			err:      k8serrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "kubeflow", fmt.Errorf("denied")),
+			expected: kfapis.INTERNAL_ERROR,
+		},
+	}
+	for _, c := range tests {
+		err := ClusterError(c.err, kfapis.INTERNAL_ERROR, "couldn't get namespace %v", "kubeflow")
+		kfErr, ok := err.(*kfapis.KfError)
+		if !ok {
+			t.Fatalf("ClusterError(%v) returned %T; want *KfError", c.err, err)
+		}
+		if kfErr.Code != int(c.expected) {
+			t.Errorf("ClusterError(%v) code = %v; want %v", c.err, kfErr.Code, c.expected)
+		}
+	}
+}
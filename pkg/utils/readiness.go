@@ -0,0 +1,214 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Readiness is the readiness of a single object.
+type Readiness struct {
+	Ready bool
+	// Failed is set when the object can't become ready without intervention,
+	// e.g. a failed Job or a Deployment that exceeded its progress deadline.
+	Failed bool
+	// Message explains why the object isn't ready.
+	Message string
+}
+
+// readinessKinds are the kinds CheckReadiness inspects beyond their existence.
+var readinessKinds = map[string]bool{
+	"Deployment":               true,
+	"StatefulSet":              true,
+	"DaemonSet":                true,
+	"Job":                      true,
+	"CustomResourceDefinition": true,
+}
+
+// HasReadiness returns true if objects of kind have a ready state to wait for.
+func HasReadiness(kind string) bool {
+	return readinessKinds[kind]
+}
+
+// CheckReadiness reports whether obj reached its ready state. Deployments, StatefulSets and
+// DaemonSets must have rolled out, Jobs must have completed and CRDs must be established.
+// Objects of any other kind are ready as soon as they exist.
+func CheckReadiness(obj *unstructured.Unstructured) Readiness {
+	switch obj.GetKind() {
+	case "Deployment", "StatefulSet", "DaemonSet":
+		if obj.GetGeneration() > nestedInt(obj, "status", "observedGeneration") {
+			return Readiness{Message: "waiting for the controller to observe the latest generation"}
+		}
+	}
+
+	switch obj.GetKind() {
+	case "Deployment":
+		return deploymentReadiness(obj)
+	case "StatefulSet":
+		return statefulSetReadiness(obj)
+	case "DaemonSet":
+		return daemonSetReadiness(obj)
+	case "Job":
+		return jobReadiness(obj)
+	case "CustomResourceDefinition":
+		if conditionStatus(obj, "Established") == "True" {
+			return Readiness{Ready: true}
+		}
+		return Readiness{Message: "not established"}
+	default:
+		return Readiness{Ready: true}
+	}
+}
+
+func deploymentReadiness(obj *unstructured.Unstructured) Readiness {
+	if reason := conditionReason(obj, "Progressing"); reason == "ProgressDeadlineExceeded" {
+		return Readiness{Failed: true, Message: "exceeded its progress deadline"}
+	}
+	replicas := specReplicas(obj)
+	updated := nestedInt(obj, "status", "updatedReplicas")
+	total := nestedInt(obj, "status", "replicas")
+	available := nestedInt(obj, "status", "availableReplicas")
+	switch {
+	case updated < replicas:
+		return Readiness{Message: fmt.Sprintf("%v of %v replicas updated", updated, replicas)}
+	case total > updated:
+		return Readiness{Message: fmt.Sprintf("%v old replicas pending termination", total-updated)}
+	case available < updated:
+		return Readiness{Message: fmt.Sprintf("%v of %v updated replicas available", available, updated)}
+	}
+	return Readiness{Ready: true}
+}
+
+func statefulSetReadiness(obj *unstructured.Unstructured) Readiness {
+	replicas := specReplicas(obj)
+	ready := nestedInt(obj, "status", "readyReplicas")
+	if ready < replicas {
+		return Readiness{Message: fmt.Sprintf("%v of %v replicas ready", ready, replicas)}
+	}
+	strategy, _, _ := unstructured.NestedString(obj.Object, "spec", "updateStrategy", "type")
+	if strategy == "OnDelete" {
+		return Readiness{Ready: true}
+	}
+	current, _, _ := unstructured.NestedString(obj.Object, "status", "currentRevision")
+	update, _, _ := unstructured.NestedString(obj.Object, "status", "updateRevision")
+	if update != "" && current != update {
+		updated := nestedInt(obj, "status", "updatedReplicas")
+		return Readiness{Message: fmt.Sprintf("%v of %v replicas updated", updated, replicas)}
+	}
+	return Readiness{Ready: true}
+}
+
+func daemonSetReadiness(obj *unstructured.Unstructured) Readiness {
+	desired := nestedInt(obj, "status", "desiredNumberScheduled")
+	updated := nestedInt(obj, "status", "updatedNumberScheduled")
+	available := nestedInt(obj, "status", "numberAvailable")
+	switch {
+	case updated < desired:
+		return Readiness{Message: fmt.Sprintf("%v of %v pods updated", updated, desired)}
+	case available < desired:
+		return Readiness{Message: fmt.Sprintf("%v of %v pods available", available, desired)}
+	}
+	return Readiness{Ready: true}
+}
+
+func jobReadiness(obj *unstructured.Unstructured) Readiness {
+	if conditionStatus(obj, "Complete") == "True" {
+		return Readiness{Ready: true}
+	}
+	if conditionStatus(obj, "Failed") == "True" {
+		return Readiness{Failed: true, Message: fmt.Sprintf("failed: %v", conditionReason(obj, "Failed"))}
+	}
+	return Readiness{Message: "not complete"}
+}
+
+// specReplicas returns spec.replicas, which defaults to 1.
+func specReplicas(obj *unstructured.Unstructured) int64 {
+	if _, found, _ := unstructured.NestedFieldNoCopy(obj.Object, "spec", "replicas"); !found {
+		return 1
+	}
+	return nestedInt(obj, "spec", "replicas")
+}
+
+// nestedInt returns the integer at fields or 0 if it isn't set.
+func nestedInt(obj *unstructured.Unstructured, fields ...string) int64 {
+	value, found, _ := unstructured.NestedFieldNoCopy(obj.Object, fields...)
+	if !found {
+		return 0
+	}
+	switch v := value.(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+// condition returns the status condition of the given type.
+func condition(obj *unstructured.Unstructured, condType string) map[string]interface{} {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if ok && cond["type"] == condType {
+			return cond
+		}
+	}
+	return nil
+}
+
+func conditionStatus(obj *unstructured.Unstructured, condType string) string {
+	status, _ := condition(obj, condType)["status"].(string)
+	return status
+}
+
+func conditionReason(obj *unstructured.Unstructured, condType string) string {
+	reason, _ := condition(obj, condType)["reason"].(string)
+	return reason
+}
+
+// WaitForReady polls refs until all of them are ready, one of them failed or timeout expires.
+// The returned error lists every object that didn't become ready.
+func (a *ServerSideApply) WaitForReady(refs []ObjectRef, timeout time.Duration, interval time.Duration) error {
+	pending := refs
+	messages := map[string]string{}
+	failed := false
+	pollErr := wait.PollImmediate(interval, timeout, func() (bool, error) {
+		remaining := []ObjectRef{}
+		for _, ref := range pending {
+			live, err := a.client.Get(ref.Object())
+			switch {
+			case err != nil:
+				messages[ref.Key()] = err.Error()
+			case live == nil:
+				messages[ref.Key()] = "not found"
+			default:
+				readiness := CheckReadiness(live)
+				if readiness.Ready {
+					a.logger.Infof("%v ready", ref.Key())
+					delete(messages, ref.Key())
+					continue
+				}
+				messages[ref.Key()] = readiness.Message
+				failed = failed || readiness.Failed
+			}
+			remaining = append(remaining, ref)
+		}
+		pending = remaining
+		return len(pending) == 0 || failed, nil
+	})
+	if pollErr == nil && len(pending) == 0 {
+		return nil
+	}
+
+	notReady := []string{}
+	for _, ref := range pending {
+		notReady = append(notReady, fmt.Sprintf("%v (%v)", ref.Key(), messages[ref.Key()]))
+	}
+	return kfapis.NewKfError(kfapis.ErrNotReady, "objects not ready: %v", strings.Join(notReady, "; "))
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCheckReadiness(t *testing.T) {
+	tests := []struct {
+		name     string
+		object   map[string]interface{}
+		expected Readiness
+	}{
+		{
+			name: "deployment available",
+			object: map[string]interface{}{
+				"kind":     "Deployment",
+				"metadata": map[string]interface{}{"generation": int64(2)},
+				"spec":     map[string]interface{}{"replicas": int64(2)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(2),
+					"replicas":           int64(2),
+					"updatedReplicas":    int64(2),
+					"availableReplicas":  int64(2),
+				},
+			},
+			expected: Readiness{Ready: true},
+		},
+		{
+			name: "deployment generation not observed",
+			object: map[string]interface{}{
+				"kind":     "Deployment",
+				"metadata": map[string]interface{}{"generation": int64(3)},
+				"status":   map[string]interface{}{"observedGeneration": int64(2)},
+			},
+			expected: Readiness{Message: "waiting for the controller to observe the latest generation"},
+		},
+		{
+			name: "deployment rolling out",
+			object: map[string]interface{}{
+				"kind": "Deployment",
+				"status": map[string]interface{}{
+					"replicas":          int64(1),
+					"updatedReplicas":   int64(1),
+					"availableReplicas": int64(0),
+				},
+			},
+			expected: Readiness{Message: "0 of 1 updated replicas available"},
+		},
+		{
+			name: "deployment progress deadline exceeded",
+			object: map[string]interface{}{
+				"kind": "Deployment",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Progressing", "status": "False", "reason": "ProgressDeadlineExceeded"},
+					},
+				},
+			},
+			expected: Readiness{Failed: true, Message: "exceeded its progress deadline"},
+		},
+		{
+			name: "statefulset updating",
+			object: map[string]interface{}{
+				"kind": "StatefulSet",
+				"spec": map[string]interface{}{"replicas": int64(1)},
+				"status": map[string]interface{}{
+					"readyReplicas":   int64(1),
+					"currentRevision": "a",
+					"updateRevision":  "b",
+				},
+			},
+			expected: Readiness{Message: "0 of 1 replicas updated"},
+		},
+		{
+			name: "daemonset available",
+			object: map[string]interface{}{
+				"kind": "DaemonSet",
+				"status": map[string]interface{}{
+					"desiredNumberScheduled": int64(3),
+					"updatedNumberScheduled": int64(3),
+					"numberAvailable":        int64(3),
+				},
+			},
+			expected: Readiness{Ready: true},
+		},
+		{
+			name: "job failed",
+			object: map[string]interface{}{
+				"kind": "Job",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Failed", "status": "True", "reason": "BackoffLimitExceeded"},
+					},
+				},
+			},
+			expected: Readiness{Failed: true, Message: "failed: BackoffLimitExceeded"},
+		},
+		{
+			name:     "crd not established",
+			object:   map[string]interface{}{"kind": "CustomResourceDefinition"},
+			expected: Readiness{Message: "not established"},
+		},
+		{
+			name:     "configmap",
+			object:   map[string]interface{}{"kind": "ConfigMap"},
+			expected: Readiness{Ready: true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := CheckReadiness(&unstructured.Unstructured{Object: test.object})
+			if actual != test.expected {
+				t.Errorf("CheckReadiness() = %+v; want %+v", actual, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	log "github.com/sirupsen/logrus"
+)
+
+// RedactedValue replaces secrets in logs, dumps and persisted configs.
+const RedactedValue = "<redacted>"
+
+// SecretTag is the tag of struct fields holding secrets, e.g. `kfctl:"secret"`.
+const SecretTag = "kfctl"
+
+var (
+	redactMu sync.RWMutex
+	// showSecrets is set with SetShowSecrets, e.g. by kfctl --show-secrets.
+	showSecrets bool
+	// secretValues are the values of resolved secrets.
+	secretValues = map[string]bool{}
+	// secretKinds are the types of embedded objects, e.g. plugins, whose fields can be secrets.
+	secretKinds = KindTypes{}
+)
+
+// SetShowSecrets turns redaction off (true) or on (false) for the whole process.
+func SetShowSecrets(show bool) {
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	showSecrets = show
+}
+
+// ShowSecrets returns true if secrets aren't redacted.
+func ShowSecrets() bool {
+	redactMu.RLock()
+	defer redactMu.RUnlock()
+	return showSecrets
+}
+
+// AddSecretValue records the value of a resolved secret so that RedactString masks it.
+// Values shorter than 4 characters are too likely to appear by chance and are ignored.
+func AddSecretValue(value string) {
+	if len(value) < 4 || value == RedactedValue {
+		return
+	}
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	secretValues[value] = true
+}
+
+// RegisterSecretKinds adds the types Redact uses for embedded objects of the given kinds,
+// like UnknownFields does for its kinds.
+func RegisterSecretKinds(kinds KindTypes) {
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	for k, v := range kinds {
+		secretKinds[k] = v
+	}
+}
+
+// RedactString replaces the values of resolved secrets in s.
+func RedactString(s string) string {
+	redactMu.RLock()
+	defer redactMu.RUnlock()
+	if showSecrets || len(secretValues) == 0 {
+		return s
+	}
+	values := make([]string, 0, len(secretValues))
+	for v := range secretValues {
+		values = append(values, v)
+	}
+	// Longer values first so that a secret containing another one is masked whole.
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	for _, v := range values {
+		s = strings.Replace(s, v, RedactedValue, -1)
+	}
+	return s
+}
+
+// Redact returns the JSON representation of value, decoded into maps and slices, with
+// its secret fields replaced by RedactedValue. value is returned as is if secrets are shown
+// or it can't be encoded.
+func Redact(value interface{}) interface{} {
+	if ShowSecrets() || value == nil {
+		return value
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return value
+	}
+	RedactData(decoded, value)
+	return decoded
+}
+
+// RedactData replaces the values of the secret fields of data, the JSON representation of
+// obj decoded into maps and slices, with RedactedValue, and returns their paths. Nothing is
+// replaced if secrets are shown.
+func RedactData(data interface{}, obj interface{}) []string {
+	if ShowSecrets() || obj == nil {
+		return nil
+	}
+	redactMu.RLock()
+	kinds := KindTypes{}
+	for k, v := range secretKinds {
+		kinds[k] = v
+	}
+	redactMu.RUnlock()
+	redacted := []string{}
+	redactValue(data, reflect.TypeOf(obj), "", kinds, &redacted)
+	sort.Strings(redacted)
+	return redacted
+}
+
+func redactValue(data interface{}, t reflect.Type, path string, kinds KindTypes, redacted *[]string) {
+	t = derefType(t)
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		items, ok := data.([]interface{})
+		if !ok {
+			return
+		}
+		for i, item := range items {
+			redactValue(item, t.Elem(), fmt.Sprintf("%v[%v]", path, i), kinds, redacted)
+		}
+	case reflect.Map:
+		values, ok := data.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for k, v := range values {
+			redactValue(v, t.Elem(), childPath(path, k), kinds, redacted)
+		}
+	case reflect.Struct:
+		values, ok := data.(map[string]interface{})
+		if !ok || t == rawExtensionType {
+			return
+		}
+		fields := jsonFields(t)
+		secrets := secretFields(t)
+		typed := map[string]reflect.Type{}
+		if kind, ok := values["kind"].(string); ok {
+			if obj, ok := kinds[kind]; ok {
+				typed = jsonFields(reflect.TypeOf(obj))
+				for k := range secretFields(reflect.TypeOf(obj)) {
+					secrets[k] = true
+				}
+			}
+		}
+		for k, v := range values {
+			if secrets[k] {
+				if s, ok := v.(string); ok && s != "" {
+					values[k] = RedactedValue
+					*redacted = append(*redacted, childPath(path, k))
+				}
+				continue
+			}
+			ft, ok := fields[k]
+			if !ok {
+				continue
+			}
+			if typedType, ok := typed[k]; ok && derefType(ft) == rawExtensionType {
+				ft = typedType
+			}
+			redactValue(v, ft, childPath(path, k), kinds, redacted)
+		}
+	}
+}
+
+// secretFields returns the json names of the fields of the struct type t, including
+// embedded structs, that are tagged as secrets.
+func secretFields(t reflect.Type) map[string]bool {
+	t = derefType(t)
+	secrets := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" && f.Anonymous && derefType(f.Type).Kind() == reflect.Struct {
+			for k := range secretFields(f.Type) {
+				secrets[k] = true
+			}
+			continue
+		}
+		if f.Tag.Get(SecretTag) != "secret" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		secrets[name] = true
+	}
+	return secrets
+}
+
+// SecretValues returns the non-empty values of the secret fields of obj, a struct or a
+// pointer to one, by their path.
+func SecretValues(obj interface{}) map[string]string {
+	values := map[string]string{}
+	collectSecretValues(reflect.ValueOf(obj), "", values)
+	return values
+}
+
+func collectSecretValues(v reflect.Value, path string, values map[string]string) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			collectSecretValues(v.Index(i), fmt.Sprintf("%v[%v]", path, i), values)
+		}
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			collectSecretValues(v.MapIndex(k), childPath(path, fmt.Sprint(k.Interface())), values)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			name := strings.Split(f.Tag.Get("json"), ",")[0]
+			fieldPath := path
+			if !f.Anonymous || name != "" {
+				if name == "" {
+					name = f.Name
+				}
+				fieldPath = childPath(path, name)
+			}
+			if f.Tag.Get(SecretTag) == "secret" && f.Type.Kind() == reflect.String {
+				if s := v.Field(i).String(); s != "" {
+					values[fieldPath] = s
+				}
+				continue
+			}
+			collectSecretValues(v.Field(i), fieldPath, values)
+		}
+	}
+}
+
+// RedactedFieldsError returns an INVALID_ARGUMENT error naming the secret fields of obj that
+// hold RedactedValue, e.g. because kfctl wrote the config they were read from, or nil.
+func RedactedFieldsError(source string, obj interface{}) error {
+	redacted := []string{}
+	for path, value := range SecretValues(obj) {
+		if value == RedactedValue {
+			redacted = append(redacted, path)
+		}
+	}
+	if len(redacted) == 0 {
+		return nil
+	}
+	sort.Strings(redacted)
+	return kfapis.NewKfError(kfapis.ErrConfigInvalid,
+		"%v has redacted secrets: %v; set them again or write the config with --show-secrets", source,
+		strings.Join(redacted, ", "))
+}
+
+// redactHook masks resolved secrets in log messages and fields.
+type redactHook struct{}
+
+// RedactHook returns a logrus hook that masks the values of resolved secrets in log entries.
+func RedactHook() log.Hook {
+	return redactHook{}
+}
+
+func (redactHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (redactHook) Fire(entry *log.Entry) error {
+	entry.Message = RedactString(entry.Message)
+	// The fields can be shared with other entries.
+	data := log.Fields{}
+	for k, v := range entry.Data {
+		if s, ok := v.(string); ok {
+			v = RedactString(s)
+		}
+		data[k] = v
+	}
+	entry.Data = data
+	return nil
+}
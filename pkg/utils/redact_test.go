@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type redactTestConfig struct {
+	Name    string             `json:"name"`
+	Token   string             `json:"token" kfctl:"secret"`
+	Plugins []redactTestPlugin `json:"plugins"`
+}
+
+type redactTestPlugin struct {
+	Kind string                `json:"kind"`
+	Spec *runtime.RawExtension `json:"spec"`
+}
+
+type redactTestDbPlugin struct {
+	Kind string           `json:"kind"`
+	Spec redactTestDbSpec `json:"spec"`
+}
+
+type redactTestDbSpec struct {
+	Username string `json:"username"`
+	Password string `json:"password,omitempty" kfctl:"secret"`
+}
+
+func TestRedact(t *testing.T) {
+	RegisterSecretKinds(KindTypes{"DbPlugin": redactTestDbPlugin{}})
+	config := redactTestConfig{
+		Name:  "kubeflow",
+		Token: "s3cret-token",
+		Plugins: []redactTestPlugin{
+			{Kind: "DbPlugin", Spec: &runtime.RawExtension{Raw: []byte(`{"username":"admin","password":"s3cret-db"}`)}},
+			{Kind: "OtherPlugin", Spec: &runtime.RawExtension{Raw: []byte(`{"password":"not-a-secret"}`)}},
+		},
+	}
+
+	redacted := &strings.Builder{}
+	encoder := json.NewEncoder(redacted)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(Redact(config)); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	expected := `{"name":"kubeflow","plugins":[` +
+		`{"kind":"DbPlugin","spec":{"password":"<redacted>","username":"admin"}},` +
+		`{"kind":"OtherPlugin","spec":{"password":"not-a-secret"}}],"token":"<redacted>"}`
+	if strings.TrimSpace(redacted.String()) != expected {
+		t.Errorf("Redact() = %v; want %v", redacted.String(), expected)
+	}
+
+	var data interface{}
+	raw, _ := json.Marshal(config)
+	_ = json.Unmarshal(raw, &data)
+	paths := RedactData(data, config)
+	if !reflect.DeepEqual(paths, []string{"plugins[0].spec.password", "token"}) {
+		t.Errorf("RedactData() = %v", paths)
+	}
+
+	SetShowSecrets(true)
+	defer SetShowSecrets(false)
+	if shown := Redact(config); !reflect.DeepEqual(shown, config) {
+		t.Errorf("Redact() with secrets shown = %v; want the value itself", shown)
+	}
+}
+
+func TestRedactString(t *testing.T) {
+	AddSecretValue("hunter2-password")
+	AddSecretValue("abc")
+
+	msg := RedactString("logging in with hunter2-password as abc")
+	if msg != "logging in with <redacted> as abc" {
+		t.Errorf("RedactString() = %v", msg)
+	}
+
+	entry := log.NewEntry(log.New())
+	entry.Message = "password hunter2-password"
+	entry.Data = log.Fields{"password": "hunter2-password", "count": 1}
+	if err := RedactHook().Fire(entry); err != nil {
+		t.Fatalf("Fire() failed: %v", err)
+	}
+	if entry.Message != "password <redacted>" || entry.Data["password"] != RedactedValue || entry.Data["count"] != 1 {
+		t.Errorf("Fire() left entry %v %v", entry.Message, entry.Data)
+	}
+}
+
+func TestRedactedFieldsError(t *testing.T) {
+	spec := &redactTestDbSpec{Username: "admin", Password: RedactedValue}
+	err := RedactedFieldsError("DbPlugin spec", spec)
+	if err == nil || !strings.Contains(err.Error(), "DbPlugin spec has redacted secrets: password") {
+		t.Errorf("RedactedFieldsError() = %v", err)
+	}
+	spec.Password = "s3cret"
+	if err := RedactedFieldsError("DbPlugin spec", spec); err != nil {
+		t.Errorf("RedactedFieldsError() = %v; want nil", err)
+	}
+}
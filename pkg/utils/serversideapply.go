@@ -0,0 +1,357 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	kftypes "github.com/kubeflow/kfctl/v3/pkg/apis/apps"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	errutil "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// FieldManager is the field manager kfctl uses for server-side apply.
+	FieldManager = "kfctl"
+)
+
+// ApplyAction is the outcome of applying a single object.
+type ApplyAction string
+
+const (
+	ApplyCreated    ApplyAction = "created"
+	ApplyConfigured ApplyAction = "configured"
+	ApplyUnchanged  ApplyAction = "unchanged"
+	ApplyFailed     ApplyAction = "failed"
+	ApplyPruned     ApplyAction = "pruned"
+)
+
+// ApplyResult is the result of applying a single object.
+type ApplyResult struct {
+	// Key identifies the object, e.g. Deployment/kubeflow/centraldashboard.
+	Key    string      `json:"key"`
+	Action ApplyAction `json:"action"`
+	// Ref is the applied object with its namespace resolved.
+	Ref ObjectRef `json:"-"`
+	// Message explains why the object failed to apply.
+	Message string `json:"message,omitempty"`
+	// Err is the error the object failed to apply with.
+	Err error `json:"-"`
+}
+
+// ApplyResults is the list of per object results of an Apply call.
+type ApplyResults []ApplyResult
+
+// Count returns the number of objects with the given action.
+func (r ApplyResults) Count(action ApplyAction) int {
+	count := 0
+	for _, result := range r {
+		if result.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// Permanent returns true if an object failed with an error that retrying won't fix: a field
+// manager conflict or an object the API server rejects as invalid or forbidden.
+func (r ApplyResults) Permanent() bool {
+	for _, result := range r {
+		err := result.Err
+		if result.Action == ApplyFailed && err != nil && (k8serrors.IsConflict(err) || k8serrors.IsInvalid(err) ||
+			k8serrors.IsBadRequest(err) || k8serrors.IsForbidden(err)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Refs returns the references of the objects that were applied successfully.
+func (r ApplyResults) Refs() []ObjectRef {
+	refs := []ObjectRef{}
+	for _, result := range r {
+		if result.Action != ApplyFailed && result.Action != ApplyPruned {
+			refs = append(refs, result.Ref)
+		}
+	}
+	return refs
+}
+
+// ObjectResults converts r for use in command output.
+func (r ApplyResults) ObjectResults() []kftypes.ObjectResult {
+	objects := []kftypes.ObjectResult{}
+	for _, result := range r {
+		objects = append(objects, kftypes.ObjectResult{
+			Key:     result.Key,
+			Action:  string(result.Action),
+			Message: result.Message,
+		})
+	}
+	return objects
+}
+
+// Summary returns a one line summary of the results, e.g. "2 created, 1 configured, 5 unchanged, 0 failed".
+func (r ApplyResults) Summary() string {
+	return fmt.Sprintf("%v created, %v configured, %v unchanged, %v failed", r.Count(ApplyCreated),
+		r.Count(ApplyConfigured), r.Count(ApplyUnchanged), r.Count(ApplyFailed))
+}
+
+// ServerSideApply applies manifests with server-side apply through the dynamic client.
+// Unlike the kubectl based apply it doesn't touch any process global state and is safe
+// to use from multiple goroutines.
+type ServerSideApply struct {
+	client    *ObjectClient
+	clientset kubernetes.Interface
+	logger    *log.Entry
+	// FieldManager records kfctl as the owner of the applied fields.
+	FieldManager string
+	// Force takes ownership of fields managed by other field managers instead of
+	// reporting a conflict.
+	Force bool
+}
+
+// NewServerSideApply creates an apply engine for the cluster described by restConfig
+// and makes sure namespace exists and carries the labels Kubeflow expects.
+// If restConfig is nil it is loaded from $HOME/.kube/config. Like kubectl, objects without
+// a namespace are applied to the namespace of the current kubeconfig context.
+func NewServerSideApply(namespace string, restConfig *rest.Config) (*ServerSideApply, error) {
+	if restConfig == nil {
+		restConfig = kftypes.GetConfig()
+	}
+	client, err := NewObjectClient(restConfig, KubeConfigNamespace())
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, &kfapis.KfError{
+			Code:    int(kfapis.INTERNAL_ERROR),
+			Message: fmt.Sprintf("could not get clientset: %v", err),
+		}
+	}
+	apply := &ServerSideApply{
+		client:       client,
+		clientset:    clientset,
+		logger:       log.NewEntry(log.StandardLogger()),
+		FieldManager: FieldManager,
+	}
+	if err := apply.namespace(namespace); err != nil {
+		return nil, err
+	}
+	return apply, nil
+}
+
+// ForApplication returns a copy of a that tags its log messages with the application name
+// so that the output of applications applied in parallel stays readable.
+func (a *ServerSideApply) ForApplication(app string) *ServerSideApply {
+	forApp := *a
+	forApp.logger = a.logger.WithField("application", app)
+	return &forApp
+}
+
+// Logger returns the logger a writes its messages to.
+func (a *ServerSideApply) Logger() *log.Entry {
+	return a.logger
+}
+
+// Inventory returns the inventory of the KfDef kfDefName stored in namespace.
+func (a *ServerSideApply) Inventory(namespace string, kfDefName string) *Inventory {
+	return NewInventory(a.clientset, namespace, kfDefName)
+}
+
+// IfNamespaceExist returns true if the namespace exists.
+func (a *ServerSideApply) IfNamespaceExist(name string) bool {
+	_, nsMissingErr := a.clientset.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+	return nsMissingErr == nil
+}
+
+// Apply applies every object in the multi document YAML manifest data.
+// It returns a result for every object; the error is non nil if at least one object failed.
+func (a *ServerSideApply) Apply(data []byte) (ApplyResults, error) {
+	objects, err := ParseObjects(data)
+	if err != nil {
+		return nil, &kfapis.KfError{
+			Code:    int(kfapis.INVALID_ARGUMENT),
+			Message: fmt.Sprintf("could not parse manifests: %v", err),
+		}
+	}
+
+	results := ApplyResults{}
+	errs := []error{}
+	for _, obj := range objects {
+		result := a.applyObject(obj)
+		a.logger.Infof("%v %v", result.Key, result.Action)
+		if result.Action == ApplyFailed {
+			errs = append(errs, fmt.Errorf("%v: %v", result.Key, result.Message))
+		}
+		results = append(results, result)
+	}
+	if aggr := errutil.NewAggregate(errs); aggr != nil {
+		return results, &kfapis.KfError{
+			Code:    int(kfapis.INTERNAL_ERROR),
+			Message: fmt.Sprintf("server-side apply failed: %v", aggr),
+		}
+	}
+	return results, nil
+}
+
+// Prune deletes the objects in refs in order. With dryRun set nothing is deleted and
+// the objects that would be deleted are reported as pruned.
+func (a *ServerSideApply) Prune(refs []ObjectRef, dryRun bool) (ApplyResults, error) {
+	results := ApplyResults{}
+	errs := []error{}
+	for _, ref := range refs {
+		result := ApplyResult{Key: ref.Key(), Action: ApplyPruned, Ref: ref}
+		if dryRun {
+			a.logger.Infof("%v pruned (dry run)", result.Key)
+			results = append(results, result)
+			continue
+		}
+		deleted, err := a.client.Delete(ref.Object())
+		if err != nil {
+			result.Action = ApplyFailed
+			result.Message = err.Error()
+			errs = append(errs, fmt.Errorf("%v: %v", result.Key, err))
+		} else if !deleted {
+			a.logger.Infof("%v already deleted", result.Key)
+		} else {
+			a.logger.Infof("%v pruned", result.Key)
+		}
+		results = append(results, result)
+	}
+	if aggr := errutil.NewAggregate(errs); aggr != nil {
+		return results, &kfapis.KfError{
+			Code:    int(kfapis.INTERNAL_ERROR),
+			Message: fmt.Sprintf("pruning failed: %v", aggr),
+		}
+	}
+	return results, nil
+}
+
+func (a *ServerSideApply) applyObject(obj *unstructured.Unstructured) ApplyResult {
+	resource, err := a.client.ResourceFor(obj)
+	if err != nil {
+		return ApplyResult{Key: ObjectKey(obj), Action: ApplyFailed, Message: err.Error(), Ref: NewObjectRef(obj), Err: err}
+	}
+	key := ObjectKey(obj)
+	ref := NewObjectRef(obj)
+
+	existing, err := resource.Get(obj.GetName(), metav1.GetOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return ApplyResult{Key: key, Action: ApplyFailed, Message: err.Error(), Ref: ref, Err: err}
+	}
+	if err != nil {
+		existing = nil
+	}
+
+	body, err := json.Marshal(obj.Object)
+	if err != nil {
+		return ApplyResult{Key: key, Action: ApplyFailed, Message: err.Error(), Ref: ref, Err: err}
+	}
+	force := a.Force
+	applied, err := resource.Patch(obj.GetName(), k8stypes.ApplyPatchType, body, metav1.PatchOptions{
+		FieldManager: a.FieldManager,
+		Force:        &force,
+	})
+	if err != nil {
+		return ApplyResult{Key: key, Action: ApplyFailed, Message: applyErrorMessage(err), Ref: ref, Err: err}
+	}
+
+	switch {
+	case existing == nil:
+		return ApplyResult{Key: key, Action: ApplyCreated, Ref: ref}
+	case existing.GetResourceVersion() != applied.GetResourceVersion():
+		return ApplyResult{Key: key, Action: ApplyConfigured, Ref: ref}
+	default:
+		return ApplyResult{Key: key, Action: ApplyUnchanged, Ref: ref}
+	}
+}
+
+// applyErrorMessage turns field manager conflicts into a readable message listing the conflicting fields.
+func applyErrorMessage(err error) string {
+	statusErr, ok := err.(*k8serrors.StatusError)
+	if !ok || !k8serrors.IsConflict(err) || statusErr.ErrStatus.Details == nil {
+		return err.Error()
+	}
+	conflicts := []string{}
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		if cause.Type == metav1.CauseTypeFieldManagerConflict {
+			conflicts = append(conflicts, fmt.Sprintf("%v (%v)", cause.Field, cause.Message))
+		}
+	}
+	if len(conflicts) == 0 {
+		return err.Error()
+	}
+	return fmt.Sprintf("conflicts with other field managers: %v", strings.Join(conflicts, "; "))
+}
+
+func (a *ServerSideApply) patchNamespaceWithLabel(namespace string, labelKey string,
+	labelValue string) error {
+	var labelPatchMap = map[string]metav1.ObjectMeta{
+		"metadata": metav1.ObjectMeta{
+			Labels: map[string]string{labelKey: labelValue},
+		},
+	}
+	labelPatchJSON, err := json.Marshal(labelPatchMap)
+	if err != nil {
+		return err
+	}
+	log.Infof("Labeling Namespace: %v", namespace)
+	_, err = a.clientset.CoreV1().Namespaces().Patch(
+		namespace,
+		k8stypes.StrategicMergePatchType,
+		labelPatchJSON,
+	)
+	return err
+}
+
+func (a *ServerSideApply) namespace(namespace string) error {
+	log.Infof(string(kftypes.NAMESPACE)+": %v", namespace)
+	namespaceInstance, nsMissingErr := a.clientset.CoreV1().Namespaces().Get(
+		namespace, metav1.GetOptions{},
+	)
+	if nsMissingErr != nil && !k8serrors.IsNotFound(nsMissingErr) {
+		return ClusterError(nsMissingErr, kfapis.INTERNAL_ERROR, "couldn't get %v %v", string(kftypes.NAMESPACE), namespace)
+	}
+	if nsMissingErr != nil {
+		log.Infof("Creating namespace: %v", namespace)
+		nsSpec := &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: namespace,
+				Labels: map[string]string{
+					controlPlaneLabel:          "kubeflow",
+					katibMetricsCollectorLabel: "enabled",
+				},
+			},
+		}
+		_, nsErr := a.clientset.CoreV1().Namespaces().Create(nsSpec)
+		if nsErr != nil {
+			return ClusterError(nsErr, kfapis.INTERNAL_ERROR, "couldn't create %v %v",
+				string(kftypes.NAMESPACE), namespace)
+		}
+		return nil
+	}
+	for labelKey, labelValue := range map[string]string{
+		controlPlaneLabel:          "kubeflow",
+		katibMetricsCollectorLabel: "enabled",
+	} {
+		if _, ok := namespaceInstance.ObjectMeta.Labels[labelKey]; ok {
+			continue
+		}
+		if patchErr := a.patchNamespaceWithLabel(namespace, labelKey, labelValue); patchErr != nil {
+			return &kfapis.KfError{
+				Code:    int(kfapis.INTERNAL_ERROR),
+				Message: fmt.Sprintf("couldn't patch %v Error: %v", namespace, patchErr),
+			}
+		}
+	}
+	return nil
+}
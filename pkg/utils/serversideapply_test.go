@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"testing"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestApplyResultsSummary(t *testing.T) {
+	results := ApplyResults{
+		{Key: "Namespace/kubeflow", Action: ApplyUnchanged},
+		{Key: "Deployment/kubeflow/dashboard", Action: ApplyConfigured},
+		{Key: "Service/kubeflow/dashboard", Action: ApplyCreated},
+		{Key: "ConfigMap/kubeflow/dashboard", Action: ApplyCreated},
+	}
+	expected := "2 created, 1 configured, 1 unchanged, 0 failed"
+	if actual := results.Summary(); actual != expected {
+		t.Errorf("Summary() = %q; want %q", actual, expected)
+	}
+}
+
+func TestApplyErrorMessage(t *testing.T) {
+	conflict := k8serrors.NewApplyConflict([]metav1.StatusCause{
+		{
+			Type:    metav1.CauseTypeFieldManagerConflict,
+			Message: `conflict with "kubectl"`,
+			Field:   ".spec.replicas",
+		},
+	}, "Apply failed with 1 conflict")
+	expected := `conflicts with other field managers: .spec.replicas (conflict with "kubectl")`
+	if actual := applyErrorMessage(conflict); actual != expected {
+		t.Errorf("applyErrorMessage() = %q; want %q", actual, expected)
+	}
+
+	notFound := k8serrors.NewNotFound(schema.GroupResource{Resource: "deployments"}, "dashboard")
+	if actual := applyErrorMessage(notFound); actual != notFound.Error() {
+		t.Errorf("applyErrorMessage() = %q; want %q", actual, notFound.Error())
+	}
+}
+
+func TestApplyResultsPermanent(t *testing.T) {
+	conflict := k8serrors.NewApplyConflict(nil, "Apply failed with 1 conflict")
+	unavailable := k8serrors.NewServiceUnavailable("etcd is down")
+	invalid := k8serrors.NewInvalid(schema.GroupKind{Kind: "Deployment"}, "dashboard", nil)
+	type testCase struct {
+		results  ApplyResults
+		expected bool
+	}
+	testCases := []testCase{
+		{results: ApplyResults{{Action: ApplyCreated}}, expected: false},
+		{results: ApplyResults{{Action: ApplyFailed, Err: unavailable}}, expected: false},
+		{results: ApplyResults{{Action: ApplyFailed, Err: unavailable}, {Action: ApplyFailed, Err: conflict}}, expected: true},
+		{results: ApplyResults{{Action: ApplyFailed, Err: invalid}}, expected: true},
+	}
+	for i, c := range testCases {
+		if actual := c.results.Permanent(); actual != c.expected {
+			t.Errorf("case %v: Permanent() = %v; want %v", i, actual, c.expected)
+		}
+	}
+}
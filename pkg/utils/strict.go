@@ -0,0 +1,188 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// UnknownField is a field of a config document that the Go type it is decoded into doesn't have.
+type UnknownField struct {
+	// Path of the field, e.g. spec.applications[0].kustomizeConfig.overlay.
+	Path string
+	// Line of the field in the document or 0 if it isn't known.
+	Line int
+}
+
+func (f UnknownField) String() string {
+	if f.Line == 0 {
+		return fmt.Sprintf("unknown field %v", f.Path)
+	}
+	return fmt.Sprintf("line %v: unknown field %v", f.Line, f.Path)
+}
+
+// KindTypes maps the kind of an embedded object, e.g. a KfDef plugin, to a Go type with the
+// same fields whose RawExtension fields are typed.
+type KindTypes map[string]interface{}
+
+var (
+	rawExtensionType = reflect.TypeOf(runtime.RawExtension{})
+	unmarshalerType  = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// UnknownFields returns the fields of data, a YAML or JSON document, that have no counterpart
+// in obj. Fields are matched by their json names the way encoding/json does it, so they
+// would be silently dropped when data is decoded into obj. RawExtension fields can hold
+// anything unless their object has a kind in kinds.
+func UnknownFields(data []byte, obj interface{}, kinds KindTypes) ([]UnknownField, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	unknown := []UnknownField{}
+	findUnknownFields(doc, reflect.TypeOf(obj), "", kinds, &unknown)
+	return unknown, nil
+}
+
+func findUnknownFields(node *yaml.Node, t reflect.Type, path string, kinds KindTypes, unknown *[]UnknownField) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, n := range node.Content {
+			findUnknownFields(n, t, path, kinds, unknown)
+		}
+		return
+	case yaml.AliasNode:
+		findUnknownFields(node.Alias, t, path, kinds, unknown)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range node.Content {
+			findUnknownFields(item, t.Elem(), fmt.Sprintf("%v[%v]", path, i), kinds, unknown)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			findUnknownFields(node.Content[i+1], t.Elem(), childPath(path, node.Content[i].Value), kinds, unknown)
+		}
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode || t == rawExtensionType || reflect.PtrTo(t).Implements(unmarshalerType) {
+			return
+		}
+		fields := jsonFields(t)
+		typed := map[string]reflect.Type{}
+		if obj, ok := kinds[mappingValue(node, "kind")]; ok {
+			typed = jsonFields(reflect.TypeOf(obj))
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if strings.HasPrefix(key.Value, "$") {
+				// A strategic merge patch directive, e.g. $patch: delete in a KfDef overlay.
+				continue
+			}
+			ft, ok := fieldType(fields, key.Value)
+			if !ok {
+				*unknown = append(*unknown, UnknownField{Path: childPath(path, key.Value), Line: key.Line})
+				continue
+			}
+			if typedType, ok := fieldType(typed, key.Value); ok && derefType(ft) == rawExtensionType {
+				ft = typedType
+			}
+			findUnknownFields(node.Content[i+1], ft, childPath(path, key.Value), kinds, unknown)
+		}
+	}
+}
+
+// jsonFields returns the types of the fields of the struct type t by their json name.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	t = derefType(t)
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "" && f.Anonymous && derefType(f.Type).Kind() == reflect.Struct {
+			for k, v := range jsonFields(f.Type) {
+				fields[k] = v
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// fieldType returns the type of the field name. Like encoding/json it falls back to a
+// case-insensitive match.
+func fieldType(fields map[string]reflect.Type, name string) (reflect.Type, bool) {
+	if t, ok := fields[name]; ok {
+		return t, true
+	}
+	for k, t := range fields {
+		if strings.EqualFold(k, name) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// mappingValue returns the scalar value of key in the mapping node.
+func mappingValue(node *yaml.Node, key string) string {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1].Value
+		}
+	}
+	return ""
+}
+
+func childPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// UnknownFieldsError returns the unknown fields as a single INVALID_ARGUMENT KfError or nil
+// if there are none.
+func UnknownFieldsError(source string, unknown []UnknownField) error {
+	if len(unknown) == 0 {
+		return nil
+	}
+	msgs := []string{}
+	for _, f := range unknown {
+		msgs = append(msgs, f.String())
+	}
+	return kfapis.NewKfError(kfapis.ErrConfigInvalid, "%v has unknown fields: %v", source,
+		strings.Join(msgs, "; "))
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	kfapis "github.com/kubeflow/kfctl/v3/pkg/apis"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type testPlugin struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              *runtime.RawExtension `json:"spec,omitempty"`
+}
+
+type testConfig struct {
+	metav1.TypeMeta `json:",inline"`
+	Items           []struct {
+		Name   string            `json:"name,omitempty"`
+		Labels map[string]string `json:"labels,omitempty"`
+	} `json:"items,omitempty"`
+	Plugins []testPlugin `json:"plugins,omitempty"`
+}
+
+type testTypedPlugin struct {
+	metav1.TypeMeta `json:",inline"`
+	Spec            struct {
+		Zone string `json:"zone,omitempty"`
+	} `json:"spec,omitempty"`
+}
+
+func TestUnknownFields(t *testing.T) {
+	data := []byte(`apiVersion: v1
+kind: Test
+items:
+- name: a
+  Labels:
+    any: thing
+- nmae: b
+plugins:
+- kind: Typed
+  metadata:
+    name: typed
+  spec:
+    zone: z
+    zonee: z
+- kind: Untyped
+  spec:
+    anything: goes
+extra: true
+`)
+	expected := []UnknownField{
+		{Path: "items[1].nmae", Line: 7},
+		{Path: "plugins[0].spec.zonee", Line: 14},
+		{Path: "extra", Line: 18},
+	}
+	actual, err := UnknownFields(data, &testConfig{}, KindTypes{"Typed": testTypedPlugin{}})
+	if err != nil {
+		t.Fatalf("UnknownFields failed: %v", err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("UnknownFields = %+v; want %+v", actual, expected)
+	}
+
+	if err := UnknownFieldsError("test.yaml", actual); !errors.Is(err, kfapis.ErrConfigInvalid) {
+		t.Errorf("UnknownFieldsError returned %v; want ErrConfigInvalid", err)
+	}
+	if err := UnknownFieldsError("test.yaml", nil); err != nil {
+		t.Errorf("UnknownFieldsError without unknown fields returned %v", err)
+	}
+}